@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/moLIart/gomoku-backend/internal/records"
+)
+
+// stonesOf returns the board as rows of '.', 'x' for black and 'o' for
+// white. The board of the state holds player IDs, on an analysis board
+// both sides belong to one player, so the sides are replayed from the
+// record exported by record.
+func stonesOf(game *gameState, record func() ([]byte, error)) ([][]byte, error) {
+	stones := make([][]byte, game.Size)
+	for row := range stones {
+		stones[row] = bytes.Repeat([]byte{'.'}, game.Size)
+	}
+
+	if len(game.Players) == 2 && game.Players[0].ID == game.Players[1].ID {
+		data, err := record()
+		if err != nil {
+			return nil, err
+		}
+
+		rec, err := records.ReadPos(bytes.NewReader(data), game.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, m := range rec.Moves {
+			stones[m.Row][m.Col] = "xo"[i%2]
+		}
+		return stones, nil
+	}
+
+	sides := map[int]byte{}
+	for _, p := range game.Players {
+		if p.Stone == "black" {
+			sides[p.ID] = 'x'
+		} else {
+			sides[p.ID] = 'o'
+		}
+	}
+
+	for row, cells := range game.Board {
+		for col, id := range cells {
+			if id != nil {
+				stones[row][col] = sides[*id]
+			}
+		}
+	}
+	return stones, nil
+}
+
+// writeBoard draws the stones with coordinates, the last move in
+// parentheses and the winning line in capitals.
+func writeBoard(w io.Writer, game *gameState, stones [][]byte) error {
+	winning := map[point]bool{}
+	for _, p := range game.WinningLine {
+		winning[p] = true
+	}
+
+	letters := make([]string, game.Size)
+	for col := range letters {
+		letters[col] = colName(col)
+	}
+	header := "    " + strings.Join(letters, " ")
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, header)
+	for row := 0; row < game.Size; row++ {
+		// Cells are separated by a blank or by the parentheses around
+		// the last move
+		seps := bytes.Repeat([]byte{' '}, game.Size+1)
+		if last := game.LastMove; last != nil && last.Row == row {
+			seps[last.Col], seps[last.Col+1] = '(', ')'
+		}
+
+		fmt.Fprintf(bw, "%3d", game.Size-row)
+		for col := 0; col < game.Size; col++ {
+			cell := stones[row][col]
+			if winning[point{Row: row, Col: col}] {
+				cell -= 'a' - 'A'
+			}
+			bw.WriteByte(seps[col])
+			bw.WriteByte(cell)
+		}
+		bw.WriteByte(seps[game.Size])
+		fmt.Fprintf(bw, "%d\n", game.Size-row)
+	}
+	fmt.Fprintln(bw, header)
+
+	return bw.Flush()
+}
+
+// colName is the column letter of the board notation.
+func colName(col int) string {
+	if col >= 26 {
+		return "?"
+	}
+	return string(rune('a' + col))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func newState(size int) *gameState {
+	game := &gameState{
+		ID:      1,
+		Size:    size,
+		Players: []gamePlayer{{ID: 1, Nickname: "alice", Stone: "black"}, {ID: 2, Nickname: "bob", Stone: "white"}},
+		Board:   make([][]*int, size),
+	}
+	for row := range game.Board {
+		game.Board[row] = make([]*int, size)
+	}
+	return game
+}
+
+func TestWriteBoard(t *testing.T) {
+	game := newState(5)
+	game.Board[2][2] = intPtr(1)
+	game.Board[2][3] = intPtr(2)
+	game.LastMove = &point{Row: 2, Col: 3}
+
+	stones, err := stonesOf(game, nil)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, writeBoard(&sb, game, stones))
+
+	expected := "" +
+		"    a b c d e\n" +
+		"  5 . . . . . 5\n" +
+		"  4 . . . . . 4\n" +
+		"  3 . . x(o). 3\n" +
+		"  2 . . . . . 2\n" +
+		"  1 . . . . . 1\n" +
+		"    a b c d e\n"
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestWriteBoard_WinningLine(t *testing.T) {
+	game := newState(5)
+	for col := 0; col < 5; col++ {
+		game.Board[4][col] = intPtr(1)
+		game.WinningLine = append(game.WinningLine, point{Row: 4, Col: col})
+	}
+	game.LastMove = &point{Row: 4, Col: 4}
+
+	stones, err := stonesOf(game, nil)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, writeBoard(&sb, game, stones))
+	assert.Contains(t, sb.String(), "  1 X X X X(X)1\n")
+}
+
+func TestStonesOf_AnalysisBoard(t *testing.T) {
+	game := newState(5)
+	game.Players[1].ID = 1
+	game.Board[0][0] = intPtr(1)
+	game.Board[4][4] = intPtr(1)
+
+	stones, err := stonesOf(game, func() ([]byte, error) {
+		return []byte("a5\ne1\n"), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, byte('x'), stones[0][0])
+	assert.Equal(t, byte('o'), stones[4][4])
+}
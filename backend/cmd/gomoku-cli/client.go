@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type gamePlayer struct {
+	ID       int    `json:"id"`
+	Nickname string `json:"nickname"`
+	Stone    string `json:"stone"`
+}
+
+type point struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+type gameState struct {
+	ID            int          `json:"id"`
+	Type          string       `json:"type"`
+	Rated         bool         `json:"rated"`
+	CurrentPlayer int          `json:"current_player"`
+	Winner        *int         `json:"winner"`
+	Size          int          `json:"size"`
+	Players       []gamePlayer `json:"players"`
+	Board         [][]*int     `json:"board"`
+	LastMove      *point       `json:"last_move"`
+	Hash          string       `json:"hash"`
+	UndoRequested *int         `json:"undo_requested_by"`
+	WinningLine   []point      `json:"winning_line"`
+}
+
+// player returns the player with the given nickname, nil for spectators.
+func (g *gameState) player(nickname string) *gamePlayer {
+	for i := range g.Players {
+		if g.Players[i].Nickname == nickname {
+			return &g.Players[i]
+		}
+	}
+	return nil
+}
+
+type gameSummary struct {
+	ID            int     `json:"id"`
+	Type          string  `json:"type"`
+	Rated         bool    `json:"rated"`
+	Size          int     `json:"size"`
+	Black         *string `json:"black"`
+	White         *string `json:"white"`
+	CurrentPlayer int     `json:"current_player"`
+	Moves         int     `json:"moves"`
+}
+
+type puzzle struct {
+	ID         int    `json:"id"`
+	Side       string `json:"side"`
+	Difficulty int    `json:"difficulty"`
+}
+
+type account struct {
+	ID       int    `json:"id"`
+	Nickname string `json:"nickname"`
+	Score    int    `json:"score"`
+}
+
+// apiError is an error response of the API, either an errorRs body or
+// a plain text message.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+// tokens are the credentials returned by login, register and refresh.
+type tokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// client calls the game API on behalf of a logged in player.
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+
+	// refreshToken renews an expired token once per request, onRefresh
+	// is told about the new tokens
+	refreshToken string
+	onRefresh    func(tokens)
+}
+
+func newClient(server, token string) *client {
+	return &client{
+		server: strings.TrimRight(server, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// do sends the request, after an expired token it refreshes the token and
+// sends the request again.
+func (c *client) do(method, path string, body, result any) error {
+	err := c.send(method, path, body, result)
+
+	var apiErr *apiError
+	if c.refreshToken == "" || !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnauthorized {
+		return err
+	}
+
+	if refreshErr := c.refresh(); refreshErr != nil {
+		// The original error tells more, a revoked or expired refresh token
+		// means logging in again either way
+		return err
+	}
+	return c.send(method, path, body, result)
+}
+
+func (c *client) refresh() error {
+	var t tokens
+	rq := map[string]string{"refresh_token": c.refreshToken}
+
+	// The refresh token is spent even if the request fails
+	c.refreshToken = ""
+	if err := c.send("POST", "/api/v1/token/refresh", rq, &t); err != nil {
+		return err
+	}
+
+	c.token, c.refreshToken = t.Token, t.RefreshToken
+	if c.onRefresh != nil {
+		c.onRefresh(t)
+	}
+	return nil
+}
+
+func (c *client) send(method, path string, body, result any) error {
+	var rqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rqBody = bytes.NewReader(data)
+	}
+
+	rq, err := http.NewRequest(method, c.server+path, rqBody)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		rq.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		rq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	rs, err := c.http.Do(rq)
+	if err != nil {
+		return err
+	}
+	defer rs.Body.Close()
+
+	data, err := io.ReadAll(rs.Body)
+	if err != nil {
+		return err
+	}
+
+	if rs.StatusCode < 200 || rs.StatusCode >= 300 {
+		var errorRs struct {
+			Error string `json:"error"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &errorRs) == nil && errorRs.Error != "" {
+			message = errorRs.Error
+		}
+		return &apiError{Status: rs.StatusCode, Message: message}
+	}
+
+	switch result := result.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*result = data
+		return nil
+	default:
+		return json.Unmarshal(data, result)
+	}
+}
+
+func (c *client) login(nickname, password string) (tokens, error) {
+	return c.auth("/api/v1/login", nickname, password)
+}
+
+func (c *client) register(nickname, password string) (tokens, error) {
+	return c.auth("/api/v1/register", nickname, password)
+}
+
+func (c *client) auth(path, nickname, password string) (tokens, error) {
+	rq := map[string]string{"nickname": nickname, "password": password}
+
+	var t tokens
+	err := c.send("POST", path, rq, &t)
+	return t, err
+}
+
+// guest creates a guest player, the tokens carry its generated nickname.
+func (c *client) guest() (string, tokens, error) {
+	var rs struct {
+		Nickname string `json:"nickname"`
+		tokens
+	}
+	err := c.send("POST", "/api/v1/guest", nil, &rs)
+	return rs.Nickname, rs.tokens, err
+}
+
+func (c *client) upgradeGuest(nickname, password string) (tokens, error) {
+	rq := map[string]string{"nickname": nickname, "password": password}
+
+	var t tokens
+	err := c.do("POST", "/api/v1/guest/upgrade", rq, &t)
+	if err == nil {
+		c.token, c.refreshToken = t.Token, t.RefreshToken
+	}
+	return t, err
+}
+
+// logout revokes the token and the refresh token on the server, all logs
+// out of every session of the player.
+func (c *client) logout(all bool) error {
+	if all {
+		return c.do("POST", "/api/v1/logout/all", nil, nil)
+	}
+
+	rq := map[string]string{"refresh_token": c.refreshToken}
+	return c.do("POST", "/api/v1/logout", rq, nil)
+}
+
+func (c *client) changePassword(current, password string) (tokens, error) {
+	rq := map[string]string{"old_password": current, "new_password": password}
+
+	var t tokens
+	err := c.do("PUT", "/api/v1/account/password", rq, &t)
+	if err == nil {
+		c.token, c.refreshToken = t.Token, t.RefreshToken
+	}
+	return t, err
+}
+
+func (c *client) changeNickname(nickname string) (*account, error) {
+	var acc account
+	err := c.do("PUT", "/api/v1/account/nickname", map[string]string{"nickname": nickname}, &acc)
+	return &acc, err
+}
+
+func (c *client) deleteAccount(password string) error {
+	return c.do("DELETE", "/api/v1/account", map[string]string{"password": password}, nil)
+}
+
+// createBot creates a bot of the player and an API key of it, the key is
+// returned once.
+func (c *client) createBot(nickname string) (*account, string, error) {
+	var bot account
+	if err := c.do("POST", "/api/v1/bots", map[string]string{"nickname": nickname}, &bot); err != nil {
+		return nil, "", err
+	}
+
+	var rs struct {
+		Key string `json:"key"`
+	}
+	err := c.do("POST", fmt.Sprintf("/api/v1/bots/%d/keys", bot.ID), map[string]string{"name": "gomoku-cli"}, &rs)
+	return &bot, rs.Key, err
+}
+
+func (c *client) listGames() ([]gameSummary, error) {
+	var games []gameSummary
+	err := c.do("GET", "/api/v1/games/", nil, &games)
+	return games, err
+}
+
+func (c *client) startGame(gameType string, size int, rated bool) (*gameState, error) {
+	rq := map[string]any{"game_type": gameType, "board_size": size, "rated": rated}
+
+	var game gameState
+	err := c.do("POST", "/api/v1/games/", rq, &game)
+	return &game, err
+}
+
+func (c *client) game(id int) (*gameState, error) {
+	var game gameState
+	err := c.do("GET", fmt.Sprintf("/api/v1/games/%d", id), nil, &game)
+	return &game, err
+}
+
+func (c *client) join(id int) (*gameState, error) {
+	var game gameState
+	err := c.do("PUT", fmt.Sprintf("/api/v1/games/%d/join", id), nil, &game)
+	return &game, err
+}
+
+func (c *client) move(id int, p point) (*gameState, error) {
+	var game gameState
+	err := c.do("PUT", fmt.Sprintf("/api/v1/games/%d/move", id), p, &game)
+	return &game, err
+}
+
+func (c *client) requestUndo(id int) (*gameState, error) {
+	var game gameState
+	err := c.do("PUT", fmt.Sprintf("/api/v1/games/%d/undo", id), nil, &game)
+	return &game, err
+}
+
+func (c *client) respondUndo(id int, accept bool) (*gameState, error) {
+	var game gameState
+	err := c.do("PUT", fmt.Sprintf("/api/v1/games/%d/undo/respond", id), map[string]bool{"accept": accept}, &game)
+	return &game, err
+}
+
+func (c *client) export(id int, format string) ([]byte, error) {
+	var data []byte
+	err := c.do("GET", fmt.Sprintf("/api/v1/games/%d/export?format=%s", id, format), nil, &data)
+	return data, err
+}
+
+func (c *client) importPuzzle(format, record string, size int) (*puzzle, error) {
+	rq := map[string]any{"format": format, "record": record, "board_size": size}
+
+	var p puzzle
+	err := c.do("POST", "/api/v1/admin/puzzles/import", rq, &p)
+	return &p, err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/api/v1/games/1":
+			http.Error(w, "Game not found", http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"not your turn"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := newClient(srv.URL+"/", "token")
+
+	_, err := c.game(1)
+	assert.Equal(t, &apiError{Status: http.StatusNotFound, Message: "Game not found"}, err)
+
+	_, err = c.move(2, point{Row: 7, Col: 7})
+	assert.Equal(t, &apiError{Status: http.StatusBadRequest, Message: "not your turn"}, err)
+}
+
+func TestClient_RefreshesExpiredToken(t *testing.T) {
+	refreshes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/token/refresh":
+			refreshes++
+			w.Write([]byte(`{"token":"fresh","refresh_token":"next"}`))
+		case r.Header.Get("Authorization") != "Bearer fresh":
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		default:
+			w.Write([]byte(`{"id":1,"size":15}`))
+		}
+	}))
+	defer srv.Close()
+
+	var refreshed tokens
+	c := newClient(srv.URL, "expired")
+	c.refreshToken = "first"
+	c.onRefresh = func(t tokens) { refreshed = t }
+
+	game, err := c.game(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, game.ID)
+	assert.Equal(t, tokens{Token: "fresh", RefreshToken: "next"}, refreshed)
+	assert.Equal(t, 1, refreshes)
+
+	// Without a refresh token the error is returned as is
+	c = newClient(srv.URL, "expired")
+	_, err = c.game(1)
+	assert.Equal(t, &apiError{Status: http.StatusUnauthorized, Message: "Invalid token"}, err)
+	assert.Equal(t, 1, refreshes)
+}
+
+func TestClient_Logout(t *testing.T) {
+	var revoked string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rq struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		json.NewDecoder(r.Body).Decode(&rq)
+
+		assert.Equal(t, "/api/v1/logout", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		revoked = rq.RefreshToken
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := newClient(srv.URL, "token")
+	c.refreshToken = "refresh"
+
+	require.NoError(t, c.logout(false))
+	assert.Equal(t, "refresh", revoked)
+}
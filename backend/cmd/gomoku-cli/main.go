@@ -0,0 +1,581 @@
+// Command gomoku-cli plays and manages games of the Gomoku API from a
+// terminal.
+//
+//	gomoku-cli login alice
+//	gomoku-cli new -size 15
+//	gomoku-cli play 42
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/records"
+)
+
+const (
+	appName       = "gomoku-cli"
+	defaultServer = "http://localhost:8080"
+)
+
+var (
+	fs           = flag.NewFlagSet(appName, flag.ExitOnError)
+	serverURL    = fs.String("server", "", "API base URL, the server of the last login or "+defaultServer)
+	sessionPath  = fs.String("session", defaultSessionPath(), "file keeping the login between runs")
+	pollInterval = fs.Duration("poll", time.Second, "how often play checks for the opponent's move")
+)
+
+// app is the state shared by the commands.
+type app struct {
+	sess   *session
+	client *client
+	in     *bufio.Reader
+	out    io.Writer
+}
+
+type command struct {
+	args string
+	help string
+	run  func(a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"register": {"<nickname>", "create an account and log in", (*app).register},
+	"login":    {"<nickname>", "log in and keep the token", (*app).login},
+	"guest":    {"", "play as a guest without an account", (*app).guest},
+	"upgrade":  {"<nickname>", "turn your guest into an account, keeping your games", (*app).upgrade},
+	"logout":   {"[-all]", "revoke the token and forget it, -all logs out everywhere", (*app).logout},
+	"passwd":   {"", "change your password, logs out your other sessions", (*app).changePassword},
+	"rename":   {"<nickname>", "change your nickname", (*app).rename},
+	"bot":      {"<nickname>", "create a bot playing with the printed API key", (*app).createBot},
+	"delete":   {"", "delete your account, your games stay anonymized", (*app).deleteAccount},
+	"list":     {"", "list your unfinished games and games waiting for a player", (*app).list},
+	"new":      {"[-type pvp|pva] [-size n] [-unrated]", "start a game", (*app).newGame},
+	"join":     {"<game>", "join a game as the second player", (*app).join},
+	"show":     {"<game>", "print the board", (*app).show},
+	"move":     {"<game> <cell>", "play a move in board notation, e.g. h8", (*app).move},
+	"undo":     {"<game>", "ask to take back your last move", (*app).undo},
+	"accept":   {"<game>", "accept the opponent's takeback request", (*app).accept},
+	"decline":  {"<game>", "decline the opponent's takeback request", (*app).decline},
+	"export":   {"[-format sgf|psq|pos] <game>", "print the game record", (*app).export},
+	"play":     {"<game>", "play interactively, following the opponent's moves", (*app).play},
+	"puzzles":  {"[-format sgf|psq|pos] <file>...", "import puzzles from game records, for admins", (*app).importPuzzles},
+}
+
+func usage() {
+	fmt.Fprintf(fs.Output(), "Usage: %s [flags] <command> [args]\n\nCommands:\n", appName)
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(fs.Output(), "  %-8s %-38s %s\n", name, cmd.args, cmd.help)
+	}
+
+	fmt.Fprintf(fs.Output(), "\nFlags:\n")
+	fs.PrintDefaults()
+}
+
+func main() {
+	fs.Usage = usage
+	fs.Parse(os.Args[1:])
+
+	if fs.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", fs.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	sess, err := loadSession(*sessionPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read session: %v\n", err)
+		os.Exit(1)
+	}
+
+	server := *serverURL
+	if server == "" {
+		server = sess.Server
+	}
+	if server == "" {
+		server = defaultServer
+	}
+
+	a := &app{
+		sess:   sess,
+		client: newClient(server, sess.Token),
+		in:     bufio.NewReader(os.Stdin),
+		out:    os.Stdout,
+	}
+
+	a.client.refreshToken = sess.RefreshToken
+	a.client.onRefresh = func(t tokens) {
+		sess.Token, sess.RefreshToken = t.Token, t.RefreshToken
+		if err := sess.save(*sessionPath); err != nil {
+			fmt.Fprintf(os.Stderr, "save session: %v\n", err)
+		}
+	}
+
+	if err := cmd.run(a, fs.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func (a *app) register(args []string) error {
+	return a.authenticate(args, a.client.register)
+}
+
+func (a *app) login(args []string) error {
+	return a.authenticate(args, a.client.login)
+}
+
+func (a *app) authenticate(args []string, auth func(nickname, password string) (tokens, error)) error {
+	if len(args) != 1 {
+		return errors.New("expected a nickname")
+	}
+
+	// The password is read from standard input so it stays out of the
+	// shell history
+	password, err := a.readSecret("Password: ")
+	if err != nil {
+		return err
+	}
+
+	t, err := auth(args[0], password)
+	if err != nil {
+		return err
+	}
+
+	a.sess.Server = a.client.server
+	a.sess.Nickname = args[0]
+	a.sess.Token = t.Token
+	a.sess.RefreshToken = t.RefreshToken
+	if err := a.sess.save(*sessionPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Logged in as %s\n", args[0])
+	return nil
+}
+
+func (a *app) guest(args []string) error {
+	nickname, t, err := a.client.guest()
+	if err != nil {
+		return err
+	}
+
+	a.sess.Server = a.client.server
+	a.sess.Nickname = nickname
+	a.sess.Token = t.Token
+	a.sess.RefreshToken = t.RefreshToken
+	if err := a.sess.save(*sessionPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Playing as %s, run upgrade to keep your games\n", nickname)
+	return nil
+}
+
+func (a *app) upgrade(args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected a nickname")
+	}
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	password, err := a.readSecret("Password: ")
+	if err != nil {
+		return err
+	}
+
+	t, err := a.client.upgradeGuest(args[0], password)
+	if err != nil {
+		return err
+	}
+
+	a.sess.Nickname = args[0]
+	a.sess.Token, a.sess.RefreshToken = t.Token, t.RefreshToken
+	if err := a.sess.save(*sessionPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Upgraded to %s\n", args[0])
+	return nil
+}
+
+func (a *app) logout(args []string) error {
+	flags := flag.NewFlagSet("logout", flag.ContinueOnError)
+	all := flags.Bool("all", false, "log out of all sessions")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// The session is forgotten even if the server can't be reached
+	var err error
+	if a.sess.Token != "" {
+		err = a.client.logout(*all)
+	}
+
+	if err := os.Remove(*sessionPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("session forgotten, but the server didn't revoke it: %w", err)
+	}
+	return nil
+}
+
+func (a *app) changePassword(args []string) error {
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	current, err := a.readSecret("Current password: ")
+	if err != nil {
+		return err
+	}
+	password, err := a.readSecret("New password: ")
+	if err != nil {
+		return err
+	}
+
+	t, err := a.client.changePassword(current, password)
+	if err != nil {
+		return err
+	}
+
+	a.sess.Token, a.sess.RefreshToken = t.Token, t.RefreshToken
+	if err := a.sess.save(*sessionPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.out, "Password changed, other sessions are logged out")
+	return nil
+}
+
+func (a *app) rename(args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected a nickname")
+	}
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	acc, err := a.client.changeNickname(args[0])
+	if err != nil {
+		return err
+	}
+
+	// The session nickname finds your seat in the games
+	a.sess.Nickname = acc.Nickname
+	if err := a.sess.save(*sessionPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Renamed to %s\n", acc.Nickname)
+	return nil
+}
+
+func (a *app) deleteAccount(args []string) error {
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	password, err := a.readSecret("Password: ")
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.deleteAccount(password); err != nil {
+		return err
+	}
+
+	if err := os.Remove(*sessionPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	fmt.Fprintln(a.out, "Account deleted")
+	return nil
+}
+
+func (a *app) createBot(args []string) error {
+	if len(args) != 1 {
+		return errors.New("expected a nickname")
+	}
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	bot, key, err := a.client.createBot(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Created bot %s, its API key is shown once:\n%s\n", bot.Nickname, key)
+	return nil
+}
+
+func (a *app) list(args []string) error {
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	games, err := a.client.listGames()
+	if err != nil {
+		return err
+	}
+
+	if len(games) == 0 {
+		fmt.Fprintln(a.out, "No active games")
+		return nil
+	}
+
+	fmt.Fprintf(a.out, "%-6s %-8s %-5s %-6s %-20s %-20s %s\n", "ID", "TYPE", "SIZE", "MOVES", "BLACK", "WHITE", "RATED")
+	for _, g := range games {
+		fmt.Fprintf(a.out, "%-6d %-8s %-5d %-6d %-20s %-20s %t\n",
+			g.ID, g.Type, g.Size, g.Moves, nickname(g.Black), nickname(g.White), g.Rated)
+	}
+	return nil
+}
+
+func nickname(name *string) string {
+	if name == nil {
+		return "(waiting)"
+	}
+	return *name
+}
+
+func (a *app) newGame(args []string) error {
+	flags := flag.NewFlagSet("new", flag.ContinueOnError)
+	gameType := flags.String("type", "pvp", "game type, pvp or pva")
+	size := flags.Int("size", 15, "board size")
+	unrated := flags.Bool("unrated", false, "don't change the score of the players")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	game, err := a.client.startGame(*gameType, *size, !*unrated)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Started game %d, play it with: %s play %d\n", game.ID, appName, game.ID)
+	return nil
+}
+
+func (a *app) join(args []string) error {
+	id, err := a.gameArg(args, 1)
+	if err != nil {
+		return err
+	}
+
+	game, err := a.client.join(id)
+	if err != nil {
+		return err
+	}
+	return a.printGame(game)
+}
+
+func (a *app) show(args []string) error {
+	id, err := a.gameArg(args, 1)
+	if err != nil {
+		return err
+	}
+
+	game, err := a.client.game(id)
+	if err != nil {
+		return err
+	}
+	return a.printGame(game)
+}
+
+func (a *app) move(args []string) error {
+	id, err := a.gameArg(args, 2)
+	if err != nil {
+		return err
+	}
+
+	game, err := a.client.game(id)
+	if err != nil {
+		return err
+	}
+
+	if game, err = a.playMove(game, args[1]); err != nil {
+		return err
+	}
+	return a.printGame(game)
+}
+
+func (a *app) undo(args []string) error {
+	id, err := a.gameArg(args, 1)
+	if err != nil {
+		return err
+	}
+
+	game, err := a.client.requestUndo(id)
+	if err != nil {
+		return err
+	}
+	return a.printGame(game)
+}
+
+func (a *app) accept(args []string) error {
+	return a.respondUndo(args, true)
+}
+
+func (a *app) decline(args []string) error {
+	return a.respondUndo(args, false)
+}
+
+func (a *app) respondUndo(args []string, accept bool) error {
+	id, err := a.gameArg(args, 1)
+	if err != nil {
+		return err
+	}
+
+	game, err := a.client.respondUndo(id, accept)
+	if err != nil {
+		return err
+	}
+	return a.printGame(game)
+}
+
+func (a *app) export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "sgf", "record format, sgf, psq or pos")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := a.gameArg(flags.Args(), 1)
+	if err != nil {
+		return err
+	}
+
+	data, err := a.client.export(id, *format)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.out.Write(data)
+	return err
+}
+
+func (a *app) importPuzzles(args []string) error {
+	flags := flag.NewFlagSet("puzzles", flag.ContinueOnError)
+	format := flags.String("format", "", "record format, sgf, psq or pos, by default from the file extension")
+	size := flags.Int("size", 15, "board size of pos records")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := a.requireLogin(); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("expected record files")
+	}
+
+	// Every file is imported on its own, positions without a forced win
+	// are reported and skipped
+	failed := 0
+	for _, path := range flags.Args() {
+		recordFormat := *format
+		if recordFormat == "" {
+			recordFormat = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		puzzle, err := a.client.importPuzzle(recordFormat, string(data), *size)
+		if err != nil {
+			fmt.Fprintf(a.out, "%s: %v\n", path, err)
+			failed++
+			continue
+		}
+
+		fmt.Fprintf(a.out, "%s: puzzle %d, %s to move, difficulty %d\n", path, puzzle.ID, puzzle.Side, puzzle.Difficulty)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d records not imported", failed, flags.NArg())
+	}
+	return nil
+}
+
+// gameArg checks the login and the number of arguments and parses the
+// game ID, the first argument.
+func (a *app) gameArg(args []string, n int) (int, error) {
+	if err := a.requireLogin(); err != nil {
+		return 0, err
+	}
+
+	if len(args) != n {
+		return 0, fmt.Errorf("expected %d arguments, got %d", n, len(args))
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid game ID %q", args[0])
+	}
+	return id, nil
+}
+
+func (a *app) requireLogin() error {
+	if a.sess.Token == "" {
+		return errNotLoggedIn
+	}
+	return nil
+}
+
+// playMove sends a move given in board notation.
+func (a *app) playMove(game *gameState, cell string) (*gameState, error) {
+	p, err := records.ParseCoord(game.Size, cell)
+	if err != nil {
+		return nil, err
+	}
+	return a.client.move(game.ID, point{Row: p.Row, Col: p.Col})
+}
+
+// readSecret reads a password from standard input, the prompt goes to
+// standard error so the output stays clean.
+func (a *app) readSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	return a.readLine()
+}
+
+func (a *app) readLine() (string, error) {
+	line, err := a.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// printGame draws the board followed by the players and whose turn it is.
+func (a *app) printGame(game *gameState) error {
+	stones, err := stonesOf(game, func() ([]byte, error) {
+		return a.client.export(game.ID, "pos")
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Game %d (%s, %dx%d)\n", game.ID, game.Type, game.Size, game.Size)
+	if err := writeBoard(a.out, game, stones); err != nil {
+		return err
+	}
+
+	for _, p := range game.Players {
+		fmt.Fprintf(a.out, "%s: %s  ", map[string]string{"black": "x", "white": "o"}[p.Stone], p.Nickname)
+	}
+	fmt.Fprintln(a.out)
+
+	fmt.Fprintln(a.out, a.status(game))
+	return nil
+}
+
+func (a *app) status(game *gameState) string {
+	switch {
+	case game.Winner != nil:
+		return fmt.Sprintf("%s won", a.nicknameOf(game, *game.Winner))
+	case len(game.Players) < 2:
+		return "Waiting for a second player"
+	case game.UndoRequested != nil:
+		return fmt.Sprintf("%s asks to take back a move", a.nicknameOf(game, *game.UndoRequested))
+	}
+	return fmt.Sprintf("%s to move", a.nicknameOf(game, game.CurrentPlayer))
+}
+
+func (a *app) nicknameOf(game *gameState, id int) string {
+	for _, p := range game.Players {
+		if p.ID == id {
+			if p.Nickname == a.sess.Nickname {
+				return "You"
+			}
+			return p.Nickname
+		}
+	}
+	return fmt.Sprintf("player %d", id)
+}
+
+// play prints the game whenever it changes and asks for a move on the
+// player's turn, the opponent's moves are picked up by polling.
+func (a *app) play(args []string) error {
+	id, err := a.gameArg(args, 1)
+	if err != nil {
+		return err
+	}
+
+	var seen string
+	for {
+		game, err := a.client.game(id)
+		if err != nil {
+			return err
+		}
+
+		if state := fmt.Sprint(game.Hash, len(game.Players), game.UndoRequested); state != seen {
+			seen = state
+			if err := a.printGame(game); err != nil {
+				return err
+			}
+		}
+
+		if game.Winner != nil {
+			return nil
+		}
+
+		me := game.player(a.sess.Nickname)
+		if me == nil || len(game.Players) < 2 || !a.canAct(game, me) {
+			time.Sleep(*pollInterval)
+			continue
+		}
+
+		if err := a.prompt(game, me); err != nil {
+			if errors.Is(err, errQuit) {
+				return nil
+			}
+			fmt.Fprintf(a.out, "%v\n", err)
+		}
+	}
+}
+
+// canAct reports whether the game waits for the player: a move on its
+// turn or an answer to the opponent's takeback request.
+func (a *app) canAct(game *gameState, me *gamePlayer) bool {
+	if game.UndoRequested != nil {
+		return *game.UndoRequested != me.ID || game.Players[0].ID == game.Players[1].ID
+	}
+	return game.CurrentPlayer == me.ID
+}
+
+var errQuit = errors.New("quit")
+
+// prompt reads one command of the player and sends it.
+func (a *app) prompt(game *gameState, me *gamePlayer) error {
+	if game.UndoRequested != nil && *game.UndoRequested != me.ID {
+		fmt.Fprint(a.out, "Take back the last move? (accept, decline, quit): ")
+	} else {
+		fmt.Fprint(a.out, "Your move (e.g. h8, undo, quit): ")
+	}
+
+	line, err := a.readLine()
+	if err != nil {
+		// Standard input is closed, nothing more can be played
+		return errQuit
+	}
+
+	switch strings.ToLower(line) {
+	case "":
+		return nil
+	case "quit", "q":
+		return errQuit
+	case "undo":
+		_, err = a.client.requestUndo(game.ID)
+	case "accept", "yes", "y":
+		_, err = a.client.respondUndo(game.ID, true)
+	case "decline", "no", "n":
+		_, err = a.client.respondUndo(game.ID, false)
+	default:
+		_, err = a.playMove(game, line)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var errNotLoggedIn = errors.New("not logged in, run: gomoku-cli login <nickname>")
+
+// session is the login stored between runs.
+type session struct {
+	Server   string `json:"server"`
+	Nickname string `json:"nickname"`
+	Token    string `json:"token"`
+	// RefreshToken renews Token when it expires
+	RefreshToken string `json:"refresh_token"`
+}
+
+// defaultSessionPath returns the session file in the user configuration
+// directory, e.g. ~/.config/gomoku-cli/session.json.
+func defaultSessionPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "gomoku-cli", "session.json")
+}
+
+func loadSession(path string) (*session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &session{}, nil
+		}
+		return nil, err
+	}
+
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// save writes the session readable by the current user only, the token
+// grants access to the account.
+func (s *session) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,43 @@
+// Command gomoku-engine plays with the built-in AI as a Gomocup (Piskvork)
+// protocol engine on standard input and output, for engine tournaments
+// and offline benchmarks.
+//
+//	gomoku-engine -search mcts -turn-time 2s
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/moLIart/gomoku-backend/internal/ai/engines"
+	"github.com/moLIart/gomoku-backend/internal/ai/gomocup"
+)
+
+const (
+	appName = "gomoku-engine"
+)
+
+var (
+	fs       = flag.NewFlagSet(appName, flag.ExitOnError)
+	search   = fs.String("search", engines.Default, "built-in AI, one of "+strings.Join(engines.Names, ", "))
+	turnTime = fs.Duration("turn-time", gomocup.DefaultTurnTime, "longest time per move, the manager may only lower it")
+)
+
+func main() {
+	fs.Parse(os.Args[1:])
+
+	// Standard output belongs to the protocol
+	log.SetOutput(os.Stderr)
+
+	engine, err := engines.New(*search, *turnTime)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := gomocup.Serve(os.Stdin, os.Stdout, engine, *turnTime); err != nil {
+		log.WithError(err).Fatal("Engine failed")
+	}
+}
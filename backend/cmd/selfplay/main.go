@@ -0,0 +1,154 @@
+// Command selfplay plays a match between two AI engines and reports the
+// Elo difference, to tune the AI offline.
+//
+//	selfplay -a alphabeta -a-time 1s -b mcts -games 200 -openings openings.txt -out games/
+//
+// An engine is the name of a built-in AI, alphabeta or mcts, or the path
+// of a Gomocup protocol executable. Openings hold one position per line in board
+// notation, every opening is played with both colors.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/ai/engines"
+	"github.com/moLIart/gomoku-backend/internal/ai/gomocup"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/records"
+	"github.com/moLIart/gomoku-backend/internal/selfplay"
+)
+
+const (
+	appName = "selfplay"
+)
+
+var (
+	fs           = flag.NewFlagSet(appName, flag.ExitOnError)
+	engineA      = fs.String("a", engines.Default, "first engine, a built-in AI or a Gomocup protocol executable")
+	engineB      = fs.String("b", engines.Default, "second engine, a built-in AI or a Gomocup protocol executable")
+	turnTimeA    = fs.Duration("a-time", time.Second, "time per move of the first engine")
+	turnTimeB    = fs.Duration("b-time", time.Second, "time per move of the second engine")
+	games        = fs.Int("games", 100, "number of games")
+	workers      = fs.Int("workers", max(runtime.NumCPU()/2, 1), "games played at the same time")
+	boardSize    = fs.Int("size", 15, "board size")
+	openingsPath = fs.String("openings", "", "file of opening positions, games start from an empty board without it")
+	outDir       = fs.String("out", "", "directory receiving a PSQ record of every game")
+)
+
+func main() {
+	fs.Parse(os.Args[1:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	match := selfplay.Match{Size: *boardSize, Games: *games, Workers: *workers}
+	if *openingsPath != "" {
+		openings, err := readOpenings(*openingsPath, *boardSize)
+		if err != nil {
+			log.WithError(err).Fatal("Could not read openings")
+		}
+		match.Openings = openings
+	}
+
+	if *outDir != "" {
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			log.WithError(err).Fatal("Could not create output directory")
+		}
+	}
+
+	a := entrant(*engineA, *turnTimeA)
+	b := entrant(*engineB, *turnTimeB)
+	if a.Name == b.Name {
+		a.Name, b.Name = a.Name+" (a)", b.Name+" (b)"
+	}
+
+	results, err := selfplay.Run(ctx, match, a, b, func(r selfplay.Result) {
+		fmt.Fprintf(os.Stderr, "game %d: %s - %s %s\n", r.Game+1, r.Record.Black, r.Record.White, outcome(r))
+		if *outDir != "" {
+			if err := writeRecord(r); err != nil {
+				log.WithError(err).Error("Could not write game record")
+			}
+		}
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Match failed")
+	}
+
+	printSummary(a.Name, b.Name, selfplay.Summarize(results))
+}
+
+// entrant returns the engine of the spec.
+func entrant(spec string, turnTime time.Duration) selfplay.Entrant {
+	if slices.Contains(engines.Names, spec) {
+		return selfplay.Entrant{
+			Name: fmt.Sprintf("%s %v", spec, turnTime),
+			New:  func() (ai.Engine, error) { return engines.New(spec, turnTime) },
+		}
+	}
+
+	return selfplay.Entrant{
+		Name: fmt.Sprintf("%s %v", filepath.Base(spec), turnTime),
+		New:  func() (ai.Engine, error) { return gomocup.New(spec, turnTime), nil },
+	}
+}
+
+func readOpenings(path string, size int) ([][]domain.Point, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return selfplay.ReadOpenings(f, size)
+}
+
+func outcome(r selfplay.Result) string {
+	switch {
+	case r.Forfeit != nil:
+		return fmt.Sprintf("%v wins by forfeit (%v)", r.Record.Winner, r.Forfeit)
+	case r.Record.Winner == domain.Empty:
+		return "draw"
+	}
+	return fmt.Sprintf("%v wins in %d moves", r.Record.Winner, len(r.Record.Moves))
+}
+
+func writeRecord(r selfplay.Result) error {
+	f, err := os.Create(filepath.Join(*outDir, fmt.Sprintf("game-%04d.psq", r.Game+1)))
+	if err != nil {
+		return err
+	}
+
+	if err := records.WritePSQ(f, r.Record); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func printSummary(nameA, nameB string, s selfplay.Summary) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "engine\tgames\twins\tdraws\tlosses\tscore\t")
+	fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f%%\t\n",
+		nameA, s.Games, s.Wins, s.Draws, s.Losses, 100*s.Score())
+	fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f%%\t\n",
+		nameB, s.Games, s.Losses, s.Draws, s.Wins, 100*(1-s.Score()))
+	tw.Flush()
+
+	diff, margin := s.Elo()
+	fmt.Printf("\nElo difference of %s: %+.0f ± %.0f (95%%)\n", nameA, diff, margin)
+	if s.Forfeits > 0 {
+		fmt.Printf("%d games decided by forfeit\n", s.Forfeits)
+	}
+}
@@ -0,0 +1,340 @@
+// Package alphabeta is the built-in AI: an iterative deepening alpha-beta
+// search over the cells next to the stones, ordered by the shapes each
+// cell makes for both sides.
+package alphabeta
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+var ErrBoardFull = errors.New("no empty cell left")
+
+const (
+	// winScore is beyond any evaluation, wins found at a smaller ply
+	// score higher
+	winScore = 1 << 30
+
+	// maxDepth bounds the iterative deepening
+	maxDepth = 12
+	// maxCandidates is the branching factor of the search
+	maxCandidates = 12
+	// neighborhood is the distance from the stones where moves are tried
+	neighborhood = 2
+)
+
+// Engine searches the best move within a time limit per move.
+type Engine struct {
+	turnTime time.Duration
+}
+
+// New returns an engine thinking at most turnTime per move, or less when
+// the context has an earlier deadline.
+func New(turnTime time.Duration) *Engine {
+	return &Engine{turnTime: turnTime}
+}
+
+// BestMove returns the move of the side on the board, the board is left
+// unchanged.
+func (e *Engine) BestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error) {
+	deadline := time.Now().Add(e.turnTime)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	s := &searcher{
+		ctx:      ctx,
+		board:    board.Clone(),
+		lines:    boardLines(board.Size),
+		deadline: deadline,
+	}
+
+	moves := s.candidates(side)
+	if len(moves) == 0 {
+		return domain.Point{}, ErrBoardFull
+	}
+
+	// Nothing to think about with a single reasonable move
+	if len(moves) == 1 || moves[0].five {
+		return moves[0].cell, nil
+	}
+
+	best := moves[0].cell
+	for depth := 1; depth <= maxDepth; depth++ {
+		move, score, ok := s.root(side, depth, best)
+		if !ok {
+			break
+		}
+
+		best = move
+		if score >= winScore-maxDepth || score <= -winScore+maxDepth {
+			// The outcome is decided, deeper search won't change it
+			break
+		}
+	}
+
+	return best, nil
+}
+
+// searcher is the state of one BestMove call.
+type searcher struct {
+	ctx      context.Context
+	board    *domain.Board
+	lines    [][]domain.Point
+	deadline time.Time
+	depth    int
+	nodes    int
+	aborted  bool
+}
+
+// root searches the moves of side to depth, trying first the best move of
+// the previous iteration. ok is false if the search ran out of time.
+func (s *searcher) root(side domain.Stone, depth int, first domain.Point) (best domain.Point, score int, ok bool) {
+	s.depth = depth
+
+	moves := s.candidates(side)
+	for i, m := range moves {
+		if m.cell == first {
+			moves[0], moves[i] = moves[i], moves[0]
+			break
+		}
+	}
+
+	alpha := -winScore - 1
+	for _, m := range moves {
+		s.board.Put(m.cell.Row, m.cell.Col, side)
+		value := -s.negamax(side.Opponent(), depth-1, 1, -winScore-1, -alpha)
+		s.board.Remove(m.cell.Row, m.cell.Col)
+
+		if s.aborted {
+			return domain.Point{}, 0, false
+		}
+
+		if value > alpha {
+			alpha, best = value, m.cell
+		}
+	}
+
+	return best, alpha, true
+}
+
+// negamax returns the score of the position for side, which is to move.
+func (s *searcher) negamax(side domain.Stone, depth, ply, alpha, beta int) int {
+	if s.timeout() {
+		return 0
+	}
+
+	moves := s.candidates(side)
+	if len(moves) == 0 {
+		return 0
+	}
+
+	if moves[0].five {
+		return winScore - ply
+	}
+
+	if depth <= 0 {
+		return s.evaluate(side)
+	}
+
+	for _, m := range moves {
+		s.board.Put(m.cell.Row, m.cell.Col, side)
+		value := -s.negamax(side.Opponent(), depth-1, ply+1, -beta, -alpha)
+		s.board.Remove(m.cell.Row, m.cell.Col)
+
+		if value > alpha {
+			alpha = value
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return alpha
+}
+
+// timeout checks the clock every few hundred nodes. The first iteration
+// always completes, so there is a move to play.
+func (s *searcher) timeout() bool {
+	s.nodes++
+	if s.depth > 1 && s.nodes%256 == 0 && (time.Now().After(s.deadline) || s.ctx.Err() != nil) {
+		s.aborted = true
+	}
+	return s.aborted
+}
+
+// candidate is an empty cell with the shapes a stone there makes for the
+// side to move (attack) and for the opponent (defense).
+type candidate struct {
+	cell    domain.Point
+	attack  int
+	defense int
+	// five and blocks mark cells completing five for the side to move
+	// and for the opponent
+	five   bool
+	blocks bool
+}
+
+// candidates returns the cells worth a move ordered from the most
+// promising. A win comes alone, when the opponent threatens five only
+// the blocking cells are returned.
+func (s *searcher) candidates(side domain.Stone) []candidate {
+	board := s.board
+	center := domain.Point{Row: board.Size / 2, Col: board.Size / 2}
+
+	near := make([]bool, board.Size*board.Size)
+	empty := true
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			if !board.IsOccupied(row, col) {
+				continue
+			}
+			empty = false
+
+			for r := max(row-neighborhood, 0); r <= min(row+neighborhood, board.Size-1); r++ {
+				for c := max(col-neighborhood, 0); c <= min(col+neighborhood, board.Size-1); c++ {
+					near[r*board.Size+c] = true
+				}
+			}
+		}
+	}
+
+	if empty {
+		return []candidate{{cell: center}}
+	}
+
+	var moves, blocks []candidate
+	for i, ok := range near {
+		row, col := i/board.Size, i%board.Size
+		if !ok || board.IsOccupied(row, col) {
+			continue
+		}
+
+		m := candidate{cell: domain.Point{Row: row, Col: col}}
+		m.attack, m.five = shapeScore(board, row, col, side)
+		m.defense, m.blocks = shapeScore(board, row, col, side.Opponent())
+
+		if m.five {
+			return []candidate{m}
+		}
+		if m.blocks {
+			blocks = append(blocks, m)
+		}
+		moves = append(moves, m)
+	}
+
+	if len(blocks) > 0 {
+		moves = blocks
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].attack+moves[i].defense > moves[j].attack+moves[j].defense
+	})
+
+	if len(moves) > maxCandidates {
+		moves = moves[:maxCandidates]
+	}
+	return moves
+}
+
+// cellScores rates the windows of five cells through a new stone by the
+// number of stones of the side in them.
+var cellScores = [...]int{0, 1, 4, 32, 256, 4096}
+
+const (
+	patternRadius = domain.WinLength - 1
+	fiveMask      = 1<<domain.WinLength - 1
+)
+
+// shapeScore rates a stone of the side at (row, col) and tells whether it
+// completes five.
+func shapeScore(board *domain.Board, row, col int, stone domain.Stone) (score int, five bool) {
+	for _, dir := range domain.Directions {
+		own, free := board.LinePattern(row, col, dir, stone, patternRadius)
+		own |= 1 << patternRadius
+
+		for start := 0; start <= patternRadius; start++ {
+			w := uint32(fiveMask) << start
+			if (own|free)&w != w {
+				continue
+			}
+
+			n := bits.OnesCount32(own & w)
+			score += cellScores[n]
+			five = five || n == domain.WinLength
+		}
+	}
+	return score, five
+}
+
+// lineScores rates the windows of five cells holding stones of one side
+// only by the number of stones.
+var lineScores = [...]int{0, 1, 8, 64, 512, 4096}
+
+// evaluate scores the position for side, which is to move: every window
+// of five cells free of the opponent counts for the side with stones in
+// it. Threats of the side to move are worth more, it acts on them first.
+func (s *searcher) evaluate(side domain.Stone) int {
+	var own, opponent int
+
+	for _, line := range s.lines {
+		var mine, theirs uint64
+		for i, p := range line {
+			switch s.board.At(p.Row, p.Col) {
+			case side:
+				mine |= 1 << i
+			case side.Opponent():
+				theirs |= 1 << i
+			}
+		}
+
+		for p := 0; p+domain.WinLength <= len(line); p++ {
+			w := uint64(fiveMask) << p
+			switch {
+			case mine&w != 0 && theirs&w == 0:
+				own += lineScores[bits.OnesCount64(mine&w)]
+			case theirs&w != 0 && mine&w == 0:
+				opponent += lineScores[bits.OnesCount64(theirs&w)]
+			}
+		}
+	}
+
+	return own*3/2 - opponent
+}
+
+// boardLines returns the lines of the board long enough to hold five,
+// cells are ordered along the line.
+func boardLines(size int) [][]domain.Point {
+	var lines [][]domain.Point
+
+	inside := func(row, col int) bool {
+		return row >= 0 && row < size && col >= 0 && col < size
+	}
+
+	for _, dir := range domain.Directions {
+		for row := 0; row < size; row++ {
+			for col := 0; col < size; col++ {
+				// Lines start at cells without a predecessor on the board
+				if inside(row-dir.DRow, col-dir.DCol) {
+					continue
+				}
+
+				var line []domain.Point
+				for r, c := row, col; inside(r, c); r, c = r+dir.DRow, c+dir.DCol {
+					line = append(line, domain.Point{Row: r, Col: c})
+				}
+
+				if len(line) >= domain.WinLength {
+					lines = append(lines, line)
+				}
+			}
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,219 @@
+// Package book is the opening book of the AI: moves to play in known
+// positions, looked up by the canonical hash of the board, so a line is
+// found in every rotation and reflection.
+//
+// Books are read from a text format or imported from RenLib libraries.
+// In the text format each line lists a position as moves in board
+// notation, black first, then a colon and the book moves with optional
+// weights:
+//
+//	# Comments and empty lines are skipped
+//	size 15
+//	: h8
+//	h8 : i9*3 i8
+//	h8 i9 : j10 i10*2
+//
+// The size line applies to the lines after it, boards are 15x15 without
+// it.
+package book
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/records"
+)
+
+var ErrInvalidBook = errors.New("invalid opening book")
+
+// DefaultSize is the board size of book lines without a size line.
+const DefaultSize = 15
+
+// Move is a book move with its relative weight.
+type Move struct {
+	Cell   domain.Point
+	Weight int
+}
+
+// Book maps positions to their book moves. Moves are stored as seen on
+// the canonical variant of the position.
+type Book struct {
+	positions map[uint64][]Move
+}
+
+// New returns an empty book.
+func New() *Book {
+	return &Book{positions: map[uint64][]Move{}}
+}
+
+// Positions returns the number of positions in the book.
+func (b *Book) Positions() int {
+	return len(b.positions)
+}
+
+// Add adds the move for the position on the board, an existing move keeps
+// the larger weight.
+func (b *Book) Add(board *domain.Board, cell domain.Point, weight int) error {
+	if board.IsOutOfBounds(cell.Row, cell.Col) || board.IsOccupied(cell.Row, cell.Col) {
+		return fmt.Errorf("%w: cell (%d, %d) is not free", ErrInvalidBook, cell.Row, cell.Col)
+	}
+	if weight < 1 {
+		return fmt.Errorf("%w: weight %d is not positive", ErrInvalidBook, weight)
+	}
+
+	hash, sym := board.CanonicalHash()
+	row, col := sym.Apply(board.Size, cell.Row, cell.Col)
+	canonical := domain.Point{Row: row, Col: col}
+
+	moves := b.positions[hash]
+	for i, m := range moves {
+		if m.Cell == canonical {
+			moves[i].Weight = max(m.Weight, weight)
+			return nil
+		}
+	}
+
+	b.positions[hash] = append(moves, Move{Cell: canonical, Weight: weight})
+	return nil
+}
+
+// Moves returns the book moves of the position, mapped onto the board.
+// Moves of another position with the same hash are left out when their
+// cell is taken.
+func (b *Book) Moves(board *domain.Board) []Move {
+	hash, sym := board.CanonicalHash()
+	inverse := sym.Inverse()
+
+	var moves []Move
+	for _, m := range b.positions[hash] {
+		row, col := inverse.Apply(board.Size, m.Cell.Row, m.Cell.Col)
+		if board.IsOccupied(row, col) {
+			continue
+		}
+		moves = append(moves, Move{Cell: domain.Point{Row: row, Col: col}, Weight: m.Weight})
+	}
+
+	return moves
+}
+
+// Choose picks one of the moves with a probability proportional to its
+// weight. intN returns a random number in [0, n).
+func Choose(moves []Move, intN func(n int) int) (domain.Point, bool) {
+	total := 0
+	for _, m := range moves {
+		total += m.Weight
+	}
+	if total == 0 {
+		return domain.Point{}, false
+	}
+
+	x := intN(total)
+	for _, m := range moves {
+		if x < m.Weight {
+			return m.Cell, true
+		}
+		x -= m.Weight
+	}
+
+	return moves[len(moves)-1].Cell, true
+}
+
+// Read decodes a book in the text format.
+func Read(r io.Reader) (*Book, error) {
+	b := New()
+	size := DefaultSize
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if value, ok := strings.CutPrefix(line, "size "); ok {
+			s, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || s < 3 || s > domain.MaxBoardSize {
+				return nil, fmt.Errorf("%w: line %d: invalid size %q", ErrInvalidBook, n, value)
+			}
+			size = s
+			continue
+		}
+
+		if err := b.readLine(size, line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (b *Book) readLine(size int, line string) error {
+	position, moves, ok := strings.Cut(line, ":")
+	if !ok {
+		return fmt.Errorf("%w: missing colon", ErrInvalidBook)
+	}
+
+	rec, err := records.ReadPos(strings.NewReader(position), size)
+	if err != nil {
+		return err
+	}
+
+	board, err := play(size, rec.Moves)
+	if err != nil {
+		return err
+	}
+
+	fields := strings.Fields(moves)
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: no book moves", ErrInvalidBook)
+	}
+
+	for _, field := range fields {
+		coord, w, weighted := strings.Cut(field, "*")
+
+		weight := 1
+		if weighted {
+			if weight, err = strconv.Atoi(w); err != nil {
+				return fmt.Errorf("%w: invalid weight %q", ErrInvalidBook, w)
+			}
+		}
+
+		cell, err := records.ParseCoord(size, coord)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBook, err)
+		}
+
+		if err := b.Add(board, cell, weight); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// play places the moves on an empty board, black first.
+func play(size int, moves []domain.Point) (*domain.Board, error) {
+	board, err := domain.NewBoard(size)
+	if err != nil {
+		return nil, err
+	}
+
+	stone := domain.Black
+	for _, m := range moves {
+		if err := board.Put(m.Row, m.Col, stone); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBook, err)
+		}
+		stone = stone.Opponent()
+	}
+
+	return board, nil
+}
@@ -0,0 +1,217 @@
+package book_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/ai/book"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/records"
+)
+
+var _ ai.Engine = (*book.Engine)(nil)
+
+func position(t *testing.T, size int, moves string) *domain.Board {
+	t.Helper()
+
+	rec, err := records.ReadPos(strings.NewReader(moves), size)
+	require.NoError(t, err)
+
+	board, err := domain.NewBoard(size)
+	require.NoError(t, err)
+
+	stone := domain.Black
+	for _, m := range rec.Moves {
+		require.NoError(t, board.Put(m.Row, m.Col, stone))
+		stone = stone.Opponent()
+	}
+	return board
+}
+
+func cells(t *testing.T, size int, moves []book.Move) map[string]int {
+	t.Helper()
+
+	weights := map[string]int{}
+	for _, m := range moves {
+		coord, err := records.FormatCoord(size, m.Cell)
+		require.NoError(t, err)
+		weights[coord] = m.Weight
+	}
+	return weights
+}
+
+const testBook = `
+# test book
+: h8
+h8 : i9*3 i8
+h8 i9 : j10 i10*2
+
+size 9
+e5 : f6
+`
+
+func TestRead(t *testing.T) {
+	b, err := book.Read(strings.NewReader(testBook))
+	require.NoError(t, err)
+	assert.Equal(t, 4, b.Positions())
+
+	assert.Equal(t, map[string]int{"h8": 1}, cells(t, 15, b.Moves(position(t, 15, ""))))
+	assert.Equal(t, map[string]int{"i9": 3, "i8": 1}, cells(t, 15, b.Moves(position(t, 15, "h8"))))
+	assert.Equal(t, map[string]int{"j10": 1, "i10": 2}, cells(t, 15, b.Moves(position(t, 15, "h8 i9"))))
+	assert.Equal(t, map[string]int{"f6": 1}, cells(t, 9, b.Moves(position(t, 9, "e5"))))
+
+	assert.Empty(t, b.Moves(position(t, 15, "a1")))
+	assert.Empty(t, b.Moves(position(t, 9, "")))
+}
+
+func TestMoves_Symmetry(t *testing.T) {
+	b, err := book.Read(strings.NewReader(testBook))
+	require.NoError(t, err)
+
+	// h8 g7 is h8 i9 rotated by 180 degrees
+	assert.Equal(t, map[string]int{"f6": 1, "g6": 2}, cells(t, 15, b.Moves(position(t, 15, "h8 g7"))))
+	// and h8 g9 its reflection. h8 i9 is symmetric along the diagonal, so
+	// i10 is as good as j9 there, which maps to f9.
+	moves := cells(t, 15, b.Moves(position(t, 15, "h8 g9")))
+	assert.Equal(t, 1, moves["f10"])
+	assert.True(t, moves["g10"] == 2 || moves["f9"] == 2, moves)
+}
+
+func TestRead_Invalid(t *testing.T) {
+	for _, src := range []string{
+		"h8 i9",
+		"h8 :",
+		"h8 : h8",
+		"h8 h8 : i9",
+		"h8 : z99",
+		"h8 : i9*0",
+		"h8 : i9*x",
+		"size 100",
+	} {
+		_, err := book.Read(strings.NewReader(src))
+		assert.ErrorIs(t, err, book.ErrInvalidBook, src)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	moves := []book.Move{{Cell: domain.Point{Row: 1}, Weight: 1}, {Cell: domain.Point{Row: 2}, Weight: 3}}
+
+	for x, want := range []int{1, 2, 2, 2} {
+		move, ok := book.Choose(moves, func(n int) int {
+			assert.Equal(t, 4, n)
+			return x
+		})
+		assert.True(t, ok)
+		assert.Equal(t, want, move.Row)
+	}
+
+	_, ok := book.Choose(nil, func(int) int { return 0 })
+	assert.False(t, ok)
+}
+
+// renlib builds a library from nodes of a cell and flags, with an
+// optional text.
+func renlib(nodes ...[]byte) []byte {
+	lib := []byte{0xff, 'R', 'e', 'n', 'L', 'i', 'b', 0xff, 3, 4}
+	lib = append(lib, bytes.Repeat([]byte{0xff}, 10)...)
+	for _, n := range nodes {
+		lib = append(lib, n...)
+	}
+	return lib
+}
+
+func TestReadRenLib(t *testing.T) {
+	lib := renlib(
+		[]byte{0x00, 0x80},                     // root without a move
+		[]byte{0x78, 0x80},                     // h8
+		[]byte{0x69, 0xc0},                     // i9, i8 follows its subtree
+		[]byte{0x5a, 0x08, 'a', 'b', 'c', 0},   // j10 with a comment
+		[]byte{0x79, 0x01, 0x01, 0x00, 'x', 0}, // i8 with a board text
+	)
+
+	b, err := book.ReadRenLib(bytes.NewReader(lib))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"h8": 1}, cells(t, 15, b.Moves(position(t, 15, ""))))
+	assert.Equal(t, map[string]int{"i9": 1, "i8": 1}, cells(t, 15, b.Moves(position(t, 15, "h8"))))
+	assert.Equal(t, map[string]int{"j10": 1}, cells(t, 15, b.Moves(position(t, 15, "h8 i9"))))
+}
+
+func TestReadRenLib_Invalid(t *testing.T) {
+	for name, lib := range map[string][]byte{
+		"header":   []byte("RenLib"),
+		"magic":    append([]byte("not a library"), make([]byte, 20)...),
+		"version":  append(append(renlib()[:8], 2, 0), renlib()[10:]...),
+		"node":     renlib([]byte{0x78}),
+		"text":     renlib([]byte{0x78, 0x08, 'a', 'b'}),
+		"cell":     renlib([]byte{0xf1, 0x00}),
+		"occupied": renlib([]byte{0x78, 0x80}, []byte{0x78, 0x00}),
+	} {
+		_, err := book.ReadRenLib(bytes.NewReader(lib))
+		assert.ErrorIs(t, err, book.ErrInvalidBook, name)
+	}
+}
+
+// engine plays a1 and counts its requests.
+type engine struct{ calls int }
+
+func (e *engine) BestMove(context.Context, *domain.Board, domain.Stone) (domain.Point, error) {
+	e.calls++
+	return domain.Point{Row: 14, Col: 0}, nil
+}
+
+func TestEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.txt")
+	require.NoError(t, os.WriteFile(path, []byte("h8 : i9\n"), 0o644))
+
+	loader := book.NewLoader(path)
+	next := &engine{}
+	e := book.NewEngine(loader, next)
+
+	// Nothing is read before the first reload
+	move, err := e.BestMove(context.Background(), position(t, 15, "h8"), domain.White)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 14, Col: 0}, move)
+
+	_, err = loader.Reload()
+	require.NoError(t, err)
+
+	move, err = e.BestMove(context.Background(), position(t, 15, "h8"), domain.White)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 6, Col: 8}, move)
+
+	// Out of book, or not the side to move of the position
+	_, err = e.BestMove(context.Background(), position(t, 15, "h8 i9"), domain.Black)
+	require.NoError(t, err)
+	_, err = e.BestMove(context.Background(), position(t, 15, "h8"), domain.Black)
+	require.NoError(t, err)
+	assert.Equal(t, 3, next.calls)
+
+	// A failed reload keeps the book
+	require.NoError(t, os.WriteFile(path, []byte("h8 i9"), 0o644))
+	_, err = loader.Reload()
+	assert.ErrorIs(t, err, book.ErrInvalidBook)
+	assert.Equal(t, 1, loader.Book().Positions())
+
+	require.NoError(t, os.Remove(path))
+	_, err = loader.Reload()
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestLoad_RenLib(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "openings.lib")
+	require.NoError(t, os.WriteFile(path, renlib([]byte{0x78, 0x00}), 0o644))
+
+	b, err := book.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.Positions())
+}
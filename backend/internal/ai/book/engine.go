@@ -0,0 +1,92 @@
+package book
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// Load reads the book file at path, files ending in .lib are RenLib
+// libraries.
+func Load(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".lib") {
+		return ReadRenLib(f)
+	}
+	return Read(f)
+}
+
+// Loader keeps the book of a file and replaces it on Reload, while
+// engines keep playing from the previous book.
+type Loader struct {
+	path string
+	book atomic.Pointer[Book]
+}
+
+// NewLoader returns a loader of the file at path, the book is empty until
+// the first Reload.
+func NewLoader(path string) *Loader {
+	l := &Loader{path: path}
+	l.book.Store(New())
+	return l
+}
+
+// Reload reads the file again. The current book is kept if it fails.
+func (l *Loader) Reload() (*Book, error) {
+	b, err := Load(l.path)
+	if err != nil {
+		return nil, err
+	}
+
+	l.book.Store(b)
+	return b, nil
+}
+
+// Book returns the current book.
+func (l *Loader) Book() *Book {
+	return l.book.Load()
+}
+
+// Engine plays book moves and asks the next engine out of book.
+type Engine struct {
+	loader *Loader
+	next   ai.Engine
+	intN   func(n int) int
+}
+
+// NewEngine returns an engine choosing among the moves of the loaded book
+// at random by weight, for variety between games.
+func NewEngine(loader *Loader, next ai.Engine) *Engine {
+	return &Engine{loader: loader, next: next, intN: rand.IntN}
+}
+
+// BestMove returns a book move when the side is to move in a book
+// position, the move of the next engine otherwise.
+func (e *Engine) BestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error) {
+	if side == toMove(board) {
+		if move, ok := Choose(e.loader.Book().Moves(board), e.intN); ok {
+			return move, nil
+		}
+	}
+
+	return e.next.BestMove(ctx, board, side)
+}
+
+// toMove returns the side to move when black started the game.
+func toMove(board *domain.Board) domain.Stone {
+	if board.Count(domain.Black) > board.Count(domain.White) {
+		return domain.White
+	}
+	return domain.Black
+}
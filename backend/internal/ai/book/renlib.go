@@ -0,0 +1,153 @@
+package book
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// RenLib libraries are 15x15 game trees. After a 20 byte header every node
+// is a cell byte and a flags byte, stored depth first: a node with
+// renlibDown is followed by its first child, a node with renlibRight has
+// a sibling after its subtree.
+const renlibSize = 15
+
+var renlibMagic = []byte{0xff, 'R', 'e', 'n', 'L', 'i', 'b', 0xff}
+
+const (
+	renlibHeaderSize = 20
+	renlibVersion    = 3
+)
+
+const (
+	renlibDown       = 0x80
+	renlibRight      = 0x40
+	renlibOldComment = 0x20
+	renlibComment    = 0x08
+	renlibExtension  = 0x01
+
+	// renlibBoardText is set in the first extension byte
+	renlibBoardText = 0x01
+)
+
+// ReadRenLib imports a RenLib library: every move of the tree becomes a
+// book move of the position before it, with weight 1.
+func ReadRenLib(r io.Reader) (*Book, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, renlibHeaderSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: missing RenLib header", ErrInvalidBook)
+	}
+	if !bytes.Equal(header[:len(renlibMagic)], renlibMagic) {
+		return nil, fmt.Errorf("%w: not a RenLib library", ErrInvalidBook)
+	}
+	if header[8] != renlibVersion {
+		return nil, fmt.Errorf("%w: unsupported RenLib version %d.%d", ErrInvalidBook, header[8], header[9])
+	}
+
+	b := New()
+
+	// path holds the cells from the root to the parent of the next node,
+	// nil for nodes without a move. branches holds the path lengths where
+	// siblings are pending.
+	var path []*domain.Point
+	var branches []int
+
+	for {
+		var node [2]byte
+		if _, err := io.ReadFull(br, node[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: truncated node", ErrInvalidBook)
+		}
+
+		cell, flags := node[0], node[1]
+		if err := skipRenLibData(br, flags); err != nil {
+			return nil, err
+		}
+
+		var move *domain.Point
+		if cell != 0 {
+			move = &domain.Point{Row: int(cell >> 4), Col: int(cell&0x0f) - 1}
+			if move.Row >= renlibSize || move.Col < 0 {
+				return nil, fmt.Errorf("%w: invalid RenLib cell %#x", ErrInvalidBook, cell)
+			}
+
+			board, err := renlibBoard(path)
+			if err != nil {
+				return nil, err
+			}
+			if err := b.Add(board, *move, 1); err != nil {
+				return nil, err
+			}
+		}
+
+		if flags&renlibRight != 0 {
+			branches = append(branches, len(path))
+		}
+
+		if flags&renlibDown != 0 {
+			path = append(path, move)
+			continue
+		}
+
+		if len(branches) == 0 {
+			break
+		}
+		path = path[:branches[len(branches)-1]]
+		branches = branches[:len(branches)-1]
+	}
+
+	return b, nil
+}
+
+// skipRenLibData skips the extension flags and texts following a node.
+func skipRenLibData(br *bufio.Reader, flags byte) error {
+	var extension [2]byte
+	if flags&renlibExtension != 0 {
+		if _, err := io.ReadFull(br, extension[:]); err != nil {
+			return fmt.Errorf("%w: truncated node", ErrInvalidBook)
+		}
+	}
+
+	texts := 0
+	if flags&renlibComment != 0 {
+		texts++
+	}
+	if flags&renlibOldComment != 0 {
+		texts++
+	}
+	if extension[0]&renlibBoardText != 0 {
+		texts++
+	}
+
+	// Texts are zero terminated and padded to an even length
+	for ; texts > 0; texts-- {
+		for {
+			var pair [2]byte
+			if _, err := io.ReadFull(br, pair[:]); err != nil {
+				return fmt.Errorf("%w: truncated text", ErrInvalidBook)
+			}
+			if pair[0] == 0 || pair[1] == 0 {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// renlibBoard places the moves of the path, black first.
+func renlibBoard(path []*domain.Point) (*domain.Board, error) {
+	var moves []domain.Point
+	for _, p := range path {
+		if p != nil {
+			moves = append(moves, *p)
+		}
+	}
+	return play(renlibSize, moves)
+}
@@ -0,0 +1,20 @@
+// Package ai defines the computer opponent used by PvA games and
+// analysis.
+package ai
+
+import (
+	"context"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// PlayerNickname is the account of the computer opponent created by the
+// migrations, PvA games seat it as the second player.
+const PlayerNickname = "gomoku-ai"
+
+// Engine chooses moves. Implementations are safe for concurrent use.
+type Engine interface {
+	// BestMove returns the move of the side on the board, the board is
+	// left unchanged.
+	BestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error)
+}
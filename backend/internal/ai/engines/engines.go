@@ -0,0 +1,35 @@
+// Package engines creates the built-in AI engines by name, for the flags
+// of the commands.
+package engines
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/ai/alphabeta"
+	"github.com/moLIart/gomoku-backend/internal/ai/mcts"
+	"github.com/moLIart/gomoku-backend/internal/solver"
+)
+
+// Default is the engine of the server.
+const Default = "alphabeta"
+
+// Names lists the built-in engines.
+var Names = []string{"alphabeta", "mcts"}
+
+// New returns the built-in engine with the name, thinking at most
+// turnTime per move. Forced wins are played before the engine searches.
+func New(name string, turnTime time.Duration) (ai.Engine, error) {
+	var engine ai.Engine
+	switch name {
+	case "alphabeta":
+		engine = alphabeta.New(turnTime)
+	case "mcts":
+		engine = mcts.New(mcts.Config{TurnTime: turnTime})
+	default:
+		return nil, fmt.Errorf("unknown engine %q, expected one of %v", name, Names)
+	}
+
+	return solver.NewEngine(engine, solver.DefaultBudget), nil
+}
@@ -0,0 +1,21 @@
+package engines_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/moLIart/gomoku-backend/internal/ai/engines"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range engines.Names {
+		engine, err := engines.New(name, time.Second)
+		assert.NoError(t, err, name)
+		assert.NotNil(t, engine, name)
+	}
+
+	_, err := engines.New("random", time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,275 @@
+// Package gomocup drives gomoku engines speaking the Gomocup (Piskvork)
+// protocol on standard input and output.
+//
+// The manager sends START with the board size and INFO settings once,
+// then a BOARD listing every stone for each move, or BEGIN on an empty
+// board. The engine answers "x,y" where x is the column. Lines starting
+// with MESSAGE or DEBUG are ignored, ERROR and UNKNOWN fail the request.
+package gomocup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+var (
+	ErrEngineExited  = errors.New("engine exited")
+	ErrEngineTimeout = errors.New("engine exceeded its time limit")
+	ErrIllegalMove   = errors.New("engine returned an illegal move")
+)
+
+// grace is the time an engine gets to answer beyond its turn time, the
+// engine is stopped afterwards.
+const grace = time.Second
+
+// startTimeout limits the engine start up including the START command.
+const startTimeout = 10 * time.Second
+
+// Engine runs a Gomocup protocol executable. The process is started on the
+// first request and kept for the next ones, it is restarted when the board
+// size changes or after any failure, since its state is unknown then.
+type Engine struct {
+	path     string
+	args     []string
+	turnTime time.Duration
+
+	// busy holds a token while a request talks to the process, waiting
+	// for it stops with the context of the request
+	busy chan struct{}
+	proc *process
+}
+
+// New returns an engine running the executable at path with args, allowed
+// to think turnTime per move.
+func New(path string, turnTime time.Duration, args ...string) *Engine {
+	return &Engine{path: path, args: args, turnTime: turnTime, busy: make(chan struct{}, 1)}
+}
+
+// BestMove asks the engine for the move of side. Requests are served one
+// at a time, a request gives up waiting for its turn once its context is
+// done.
+func (e *Engine) BestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error) {
+	if side != domain.Black && side != domain.White {
+		return domain.Point{}, fmt.Errorf("invalid side %v", side)
+	}
+
+	select {
+	case e.busy <- struct{}{}:
+		defer func() { <-e.busy }()
+	case <-ctx.Done():
+		return domain.Point{}, ctx.Err()
+	}
+
+	if e.proc != nil && e.proc.size != board.Size {
+		e.proc.kill()
+		e.proc = nil
+	}
+
+	if e.proc == nil {
+		proc, err := e.start(ctx, board.Size)
+		if err != nil {
+			return domain.Point{}, err
+		}
+		e.proc = proc
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.turnTime+grace)
+	defer cancel()
+
+	move, err := e.proc.bestMove(ctx, board, side)
+	if err == nil && (board.IsOutOfBounds(move.Row, move.Col) || board.IsOccupied(move.Row, move.Col)) {
+		err = fmt.Errorf("%w: %d,%d", ErrIllegalMove, move.Col, move.Row)
+	}
+
+	if err != nil {
+		e.proc.kill()
+		e.proc = nil
+		return domain.Point{}, err
+	}
+
+	return move, nil
+}
+
+// Close asks the engine to exit and stops it if it doesn't.
+func (e *Engine) Close() error {
+	e.busy <- struct{}{}
+	defer func() { <-e.busy }()
+
+	if e.proc == nil {
+		return nil
+	}
+
+	e.proc.send("END")
+	select {
+	case <-e.proc.done:
+	case <-time.After(grace):
+		e.proc.kill()
+	}
+
+	e.proc = nil
+	return nil
+}
+
+func (e *Engine) start(ctx context.Context, size int) (*process, error) {
+	cmd := exec.Command(e.path, e.args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start engine: %w", err)
+	}
+
+	proc := &process{
+		cmd:   cmd,
+		stdin: stdin,
+		lines: make(chan string),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+		size:  size,
+	}
+	go proc.read(stdout)
+
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+
+	if err := proc.send(fmt.Sprintf("START %d", size)); err != nil {
+		proc.kill()
+		return nil, err
+	}
+
+	answer, err := proc.expect(ctx)
+	if err == nil && !strings.EqualFold(answer, "OK") {
+		err = fmt.Errorf("unexpected answer %q", answer)
+	}
+	if err != nil {
+		proc.kill()
+		return nil, fmt.Errorf("start engine: %w", err)
+	}
+
+	// Rule 0 is five or more in a row, the match itself has no time limit
+	err = proc.send(
+		fmt.Sprintf("INFO timeout_turn %d", e.turnTime.Milliseconds()),
+		"INFO timeout_match 0",
+		"INFO rule 0",
+	)
+	if err != nil {
+		proc.kill()
+		return nil, err
+	}
+
+	return proc, nil
+}
+
+// process is a running engine.
+type process struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+	stop  chan struct{}
+	done  chan struct{}
+	size  int
+}
+
+// read forwards the output lines of the engine until it exits.
+func (p *process) read(stdout io.Reader) {
+	defer close(p.done)
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		select {
+		case p.lines <- strings.TrimSpace(scanner.Text()):
+		case <-p.stop:
+		}
+	}
+
+	p.cmd.Wait()
+}
+
+func (p *process) send(lines ...string) error {
+	_, err := io.WriteString(p.stdin, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// expect returns the next answer of the engine, skipping messages.
+func (p *process) expect(ctx context.Context) (string, error) {
+	for {
+		select {
+		case line := <-p.lines:
+			switch command, _, _ := strings.Cut(line, " "); strings.ToUpper(command) {
+			case "", "MESSAGE", "DEBUG", "SUGGEST":
+				continue
+			case "ERROR", "UNKNOWN":
+				return "", fmt.Errorf("engine: %s", line)
+			}
+			return line, nil
+		case <-p.done:
+			return "", ErrEngineExited
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ErrEngineTimeout
+			}
+			return "", ctx.Err()
+		}
+	}
+}
+
+func (p *process) bestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error) {
+	var lines []string
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			// 1 marks the stones of the engine, 2 the opponent's
+			switch board.At(row, col) {
+			case side:
+				lines = append(lines, fmt.Sprintf("%d,%d,1", col, row))
+			case side.Opponent():
+				lines = append(lines, fmt.Sprintf("%d,%d,2", col, row))
+			}
+		}
+	}
+
+	var err error
+	if len(lines) == 0 {
+		err = p.send("BEGIN")
+	} else {
+		err = p.send(append(append([]string{"BOARD"}, lines...), "DONE")...)
+	}
+	if err != nil {
+		return domain.Point{}, err
+	}
+
+	answer, err := p.expect(ctx)
+	if err != nil {
+		return domain.Point{}, err
+	}
+
+	var move domain.Point
+	if _, err := fmt.Sscanf(answer, "%d,%d", &move.Col, &move.Row); err != nil {
+		return domain.Point{}, fmt.Errorf("%w: %q", ErrIllegalMove, answer)
+	}
+
+	return move, nil
+}
+
+// kill stops the process and waits for it to exit.
+func (p *process) kill() {
+	close(p.stop)
+	p.stdin.Close()
+	p.cmd.Process.Kill()
+	<-p.done
+}
@@ -0,0 +1,216 @@
+package gomocup_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/ai/alphabeta"
+	"github.com/moLIart/gomoku-backend/internal/ai/gomocup"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// The test binary doubles as a trivial engine when started with
+// testEngineEnv set, its value selects the behavior.
+const testEngineEnv = "GOMOCUP_TEST_ENGINE"
+
+var _ ai.Engine = (*gomocup.Engine)(nil)
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(testEngineEnv); mode != "" {
+		runTestEngine(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runTestEngine plays the first empty cell in reading order. Modes:
+// "ok" plays, "slow" never answers, "illegal" plays an occupied cell and
+// "crash" exits instead of answering. "serve" runs Serve with the
+// built-in AI instead.
+func runTestEngine(mode string) {
+	if mode == "serve" {
+		gomocup.Serve(os.Stdin, os.Stdout, alphabeta.New(time.Second), time.Second)
+		return
+	}
+
+	var size int
+	var stones [][2]int
+
+	answer := func() {
+		switch mode {
+		case "slow":
+			time.Sleep(time.Minute)
+		case "crash":
+			os.Exit(1)
+		case "illegal":
+			if len(stones) > 0 {
+				fmt.Printf("%d,%d\n", stones[0][0], stones[0][1])
+				return
+			}
+		}
+
+		taken := map[[2]int]bool{}
+		for _, s := range stones {
+			taken[s] = true
+		}
+
+		fmt.Println("MESSAGE thinking")
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if !taken[[2]int{x, y}] {
+					fmt.Printf("%d,%d\n", x, y)
+					return
+				}
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		command, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		switch command {
+		case "START":
+			fmt.Sscanf(arg, "%d", &size)
+			fmt.Println("OK")
+		case "INFO":
+		case "BEGIN":
+			stones = nil
+			answer()
+		case "BOARD":
+			stones = nil
+			for scanner.Scan() && scanner.Text() != "DONE" {
+				var x, y, field int
+				fmt.Sscanf(scanner.Text(), "%d,%d,%d", &x, &y, &field)
+				stones = append(stones, [2]int{x, y})
+			}
+			answer()
+		case "END":
+			return
+		default:
+			fmt.Println("UNKNOWN", command)
+		}
+	}
+}
+
+func newTestEngine(t *testing.T, mode string) *gomocup.Engine {
+	t.Setenv(testEngineEnv, mode)
+
+	engine := gomocup.New(os.Args[0], 200*time.Millisecond)
+	t.Cleanup(func() { engine.Close() })
+	return engine
+}
+
+func TestEngine_BestMove(t *testing.T) {
+	engine := newTestEngine(t, "ok")
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+
+	move, err := engine.BestMove(context.Background(), board, domain.Black)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 0, Col: 0}, move)
+
+	require.NoError(t, board.Put(0, 0, domain.Black))
+	require.NoError(t, board.Put(0, 1, domain.White))
+
+	move, err = engine.BestMove(context.Background(), board, domain.Black)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 0, Col: 2}, move)
+}
+
+func TestEngine_RestartsOnSizeChange(t *testing.T) {
+	engine := newTestEngine(t, "ok")
+
+	for _, size := range []int{15, 3} {
+		board, err := domain.NewBoard(size)
+		require.NoError(t, err)
+		for col := 0; col < size; col++ {
+			require.NoError(t, board.Put(0, col, domain.White))
+		}
+
+		move, err := engine.BestMove(context.Background(), board, domain.Black)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Point{Row: 1, Col: 0}, move)
+	}
+}
+
+func TestEngine_Timeout(t *testing.T) {
+	engine := newTestEngine(t, "slow")
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = engine.BestMove(context.Background(), board, domain.Black)
+	assert.ErrorIs(t, err, gomocup.ErrEngineTimeout)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestEngine_WaitCancelled(t *testing.T) {
+	engine := newTestEngine(t, "slow")
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+
+	busy := make(chan struct{})
+	go func() {
+		defer close(busy)
+		engine.BestMove(context.Background(), board, domain.Black)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// The first request holds the engine past the deadline of the second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = engine.BestMove(ctx, board, domain.Black)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	<-busy
+}
+
+func TestEngine_IllegalMove(t *testing.T) {
+	engine := newTestEngine(t, "illegal")
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+	require.NoError(t, board.Put(7, 7, domain.White))
+
+	_, err = engine.BestMove(context.Background(), board, domain.Black)
+	assert.ErrorIs(t, err, gomocup.ErrIllegalMove)
+}
+
+func TestEngine_Crash(t *testing.T) {
+	engine := newTestEngine(t, "crash")
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+
+	_, err = engine.BestMove(context.Background(), board, domain.Black)
+	assert.ErrorIs(t, err, gomocup.ErrEngineExited)
+
+	// The engine is started again for the next request
+	_, err = engine.BestMove(context.Background(), board, domain.Black)
+	assert.ErrorIs(t, err, gomocup.ErrEngineExited)
+}
+
+func TestEngine_MissingExecutable(t *testing.T) {
+	engine := gomocup.New("/nonexistent/engine", time.Second)
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+
+	_, err = engine.BestMove(context.Background(), board, domain.Black)
+	assert.Error(t, err)
+}
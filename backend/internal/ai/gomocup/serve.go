@@ -0,0 +1,302 @@
+package gomocup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// DefaultTurnTime is the default longest time per move of the engine
+// command.
+const DefaultTurnTime = 5 * time.Second
+
+// turnReserve is kept from the time per move for the protocol round trip.
+const turnReserve = 50 * time.Millisecond
+
+// About is the answer to the ABOUT command.
+var About = `name="gomoku-api", version="1.0"`
+
+// server is the engine side of a Gomocup session: the position as the
+// manager sees it, stones of the engine and of its opponent, and the
+// time limits.
+type server struct {
+	engine ai.Engine
+	w      io.Writer
+
+	board    *domain.Board
+	own      []domain.Point
+	opponent []domain.Point
+
+	// maxTurnTime is the configured time per move, the manager may only
+	// lower it
+	maxTurnTime time.Duration
+	turnTime    time.Duration
+	timeLeft    time.Duration
+}
+
+// Serve plays as engine for the Gomocup manager reading commands from r
+// and answering to w, moves are chosen by the engine within turnTime. It
+// returns when the manager sends END or closes r.
+func Serve(r io.Reader, w io.Writer, engine ai.Engine, turnTime time.Duration) error {
+	s := &server{engine: engine, w: w, maxTurnTime: turnTime, turnTime: turnTime}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		command, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		arg = strings.TrimSpace(arg)
+
+		var err error
+		switch strings.ToUpper(command) {
+		case "":
+			continue
+		case "START":
+			err = s.start(arg)
+		case "RESTART":
+			err = s.restart()
+		case "RECTSTART":
+			s.reply("ERROR rectangular boards are not supported")
+		case "INFO":
+			s.info(arg)
+		case "BEGIN":
+			err = s.play()
+		case "TURN":
+			err = s.turn(arg)
+		case "BOARD":
+			err = s.position(scanner)
+		case "TAKEBACK":
+			err = s.takeback(arg)
+		case "ABOUT":
+			s.reply(About)
+		case "END":
+			return nil
+		default:
+			s.reply("UNKNOWN " + command)
+		}
+
+		if err != nil {
+			s.reply("ERROR " + err.Error())
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (s *server) reply(line string) {
+	fmt.Fprintln(s.w, line)
+}
+
+func (s *server) start(arg string) error {
+	size, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("invalid board size %q", arg)
+	}
+
+	board, err := domain.NewBoard(size)
+	if err != nil {
+		return err
+	}
+
+	s.board, s.own, s.opponent = board, nil, nil
+	s.reply("OK")
+	return nil
+}
+
+func (s *server) restart() error {
+	if s.board == nil {
+		return fmt.Errorf("game was not started")
+	}
+	return s.start(strconv.Itoa(s.board.Size))
+}
+
+// info applies the time limits, other settings are ignored. A turn
+// timeout of zero means no limit, the configured time per move applies.
+func (s *server) info(arg string) {
+	key, value, _ := strings.Cut(arg, " ")
+	ms, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return
+	}
+
+	switch strings.ToLower(key) {
+	case "timeout_turn":
+		s.turnTime = s.maxTurnTime
+		if ms > 0 {
+			s.turnTime = min(time.Duration(ms)*time.Millisecond, s.maxTurnTime)
+		}
+	case "time_left":
+		s.timeLeft = time.Duration(ms) * time.Millisecond
+	}
+}
+
+// turn records the move of the opponent and answers it.
+func (s *server) turn(arg string) error {
+	p, err := s.parseCell(arg)
+	if err != nil {
+		return err
+	}
+
+	s.opponent = append(s.opponent, p)
+	if err := s.setup(); err != nil {
+		s.opponent = s.opponent[:len(s.opponent)-1]
+		return err
+	}
+
+	return s.play()
+}
+
+// position reads the stones of a BOARD command up to DONE and answers
+// with a move. Field 1 marks stones of the engine, other fields stones of
+// the opponent.
+func (s *server) position(scanner *bufio.Scanner) error {
+	own, opponent := []domain.Point{}, []domain.Point{}
+
+	var err error
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.EqualFold(line, "DONE") {
+			break
+		}
+		if err != nil {
+			// Read the rest of the command before reporting the error
+			continue
+		}
+
+		x, rest, _ := strings.Cut(line, ",")
+		y, field, _ := strings.Cut(rest, ",")
+
+		var p domain.Point
+		if p, err = s.parseCell(x + "," + y); err != nil {
+			continue
+		}
+
+		if strings.TrimSpace(field) == "1" {
+			own = append(own, p)
+		} else {
+			opponent = append(opponent, p)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	previous := [2][]domain.Point{s.own, s.opponent}
+	s.own, s.opponent = own, opponent
+	if err := s.setup(); err != nil {
+		s.own, s.opponent = previous[0], previous[1]
+		return err
+	}
+
+	return s.play()
+}
+
+// takeback removes the stone from the position.
+func (s *server) takeback(arg string) error {
+	p, err := s.parseCell(arg)
+	if err != nil {
+		return err
+	}
+
+	for _, stones := range []*[]domain.Point{&s.own, &s.opponent} {
+		for i, q := range *stones {
+			if q == p {
+				*stones = append((*stones)[:i], (*stones)[i+1:]...)
+				if err := s.setup(); err != nil {
+					return err
+				}
+				s.reply("OK")
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("no stone at %s", arg)
+}
+
+// side returns the stone of the engine: black moves first, so the engine
+// plays black when both sides have as many stones.
+func (s *server) side() domain.Stone {
+	if len(s.own) == len(s.opponent) {
+		return domain.Black
+	}
+	return domain.White
+}
+
+// setup rebuilds the board from the stones of both sides.
+func (s *server) setup() error {
+	if s.board == nil {
+		return fmt.Errorf("game was not started")
+	}
+
+	board, err := domain.NewBoard(s.board.Size)
+	if err != nil {
+		return err
+	}
+
+	side := s.side()
+	for _, p := range s.own {
+		if err := board.Put(p.Row, p.Col, side); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.opponent {
+		if err := board.Put(p.Row, p.Col, side.Opponent()); err != nil {
+			return err
+		}
+	}
+
+	s.board = board
+	return nil
+}
+
+// play answers with the move of the engine.
+func (s *server) play() error {
+	if s.board == nil {
+		return fmt.Errorf("game was not started")
+	}
+
+	limit := s.turnTime
+	if s.timeLeft > 0 && s.timeLeft < limit {
+		limit = s.timeLeft
+	}
+	limit = max(limit-turnReserve, turnReserve)
+
+	ctx, cancel := context.WithTimeout(context.Background(), limit)
+	defer cancel()
+
+	side := s.side()
+	move, err := s.engine.BestMove(ctx, s.board, side)
+	if err != nil {
+		return err
+	}
+
+	if err := s.board.Put(move.Row, move.Col, side); err != nil {
+		return err
+	}
+	s.own = append(s.own, move)
+
+	s.reply(fmt.Sprintf("%d,%d", move.Col, move.Row))
+	return nil
+}
+
+// parseCell parses "x,y" where x is the column.
+func (s *server) parseCell(arg string) (domain.Point, error) {
+	if s.board == nil {
+		return domain.Point{}, fmt.Errorf("game was not started")
+	}
+
+	x, y, ok := strings.Cut(arg, ",")
+	col, errX := strconv.Atoi(strings.TrimSpace(x))
+	row, errY := strconv.Atoi(strings.TrimSpace(y))
+	if !ok || errX != nil || errY != nil || s.board.IsOutOfBounds(row, col) {
+		return domain.Point{}, fmt.Errorf("invalid cell %q", arg)
+	}
+
+	return domain.Point{Row: row, Col: col}, nil
+}
@@ -0,0 +1,154 @@
+package gomocup_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/ai/alphabeta"
+	"github.com/moLIart/gomoku-backend/internal/ai/gomocup"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// firstEmpty plays the first empty cell in reading order.
+type firstEmpty struct{}
+
+func (firstEmpty) BestMove(_ context.Context, board *domain.Board, _ domain.Stone) (domain.Point, error) {
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			if !board.IsOccupied(row, col) {
+				return domain.Point{Row: row, Col: col}, nil
+			}
+		}
+	}
+	return domain.Point{}, alphabeta.ErrBoardFull
+}
+
+func serve(t *testing.T, commands ...string) []string {
+	t.Helper()
+
+	var out strings.Builder
+	err := gomocup.Serve(strings.NewReader(strings.Join(commands, "\n")+"\n"), &out, firstEmpty{}, gomocup.DefaultTurnTime)
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+func TestServe_Begin(t *testing.T) {
+	answers := serve(t, "START 15", "INFO timeout_turn 1000", "BEGIN", "TURN 1,0", "END")
+
+	assert.Equal(t, []string{"OK", "0,0", "2,0"}, answers)
+}
+
+// deadline records the time the engine got for its last move.
+type deadline struct {
+	firstEmpty
+	got time.Duration
+}
+
+func (d *deadline) BestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error) {
+	if until, ok := ctx.Deadline(); ok {
+		d.got = time.Until(until)
+	}
+	return d.firstEmpty.BestMove(ctx, board, side)
+}
+
+func TestServe_TurnTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout string
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"no limit", "0", 1500 * time.Millisecond, 2 * time.Second},
+		{"lower", "500", 300 * time.Millisecond, 500 * time.Millisecond},
+		{"above the configured time", "60000", 1500 * time.Millisecond, 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &deadline{}
+			commands := "START 15\nINFO timeout_turn " + tt.timeout + "\nBEGIN\nEND\n"
+
+			var out strings.Builder
+			require.NoError(t, gomocup.Serve(strings.NewReader(commands), &out, engine, 2*time.Second))
+
+			assert.Equal(t, "OK\n0,0\n", out.String())
+			assert.Greater(t, engine.got, tt.min)
+			assert.LessOrEqual(t, engine.got, tt.max)
+		})
+	}
+}
+
+func TestServe_Board(t *testing.T) {
+	answers := serve(t, "START 15", "BOARD", "0,0,2", "1,0,1", "2,0,2", "DONE", "TURN 4,0", "END")
+
+	assert.Equal(t, []string{"OK", "3,0", "5,0"}, answers)
+}
+
+func TestServe_Takeback(t *testing.T) {
+	answers := serve(t, "START 15", "BEGIN", "TURN 1,0", "TAKEBACK 2,0", "TAKEBACK 1,0", "TURN 0,1", "END")
+
+	assert.Equal(t, []string{"OK", "0,0", "2,0", "OK", "OK", "1,0"}, answers)
+}
+
+func TestServe_Restart(t *testing.T) {
+	answers := serve(t, "START 9", "BEGIN", "RESTART", "BEGIN", "END")
+
+	assert.Equal(t, []string{"OK", "0,0", "OK", "0,0"}, answers)
+}
+
+func TestServe_Errors(t *testing.T) {
+	answers := serve(t,
+		"BEGIN",
+		"START 2",
+		"RECTSTART 15,20",
+		"START 15",
+		"TURN 15,0",
+		"TURN x",
+		"BOARD", "0,0,1", "0,0,2", "DONE",
+		"TAKEBACK 7,7",
+		"SWAP2BOARD",
+		"END",
+	)
+
+	require.Len(t, answers, 9)
+	assert.True(t, strings.HasPrefix(answers[0], "ERROR"))
+	assert.True(t, strings.HasPrefix(answers[1], "ERROR"))
+	assert.True(t, strings.HasPrefix(answers[2], "ERROR"))
+	assert.Equal(t, "OK", answers[3])
+	for _, answer := range answers[4:8] {
+		assert.True(t, strings.HasPrefix(answer, "ERROR"), answer)
+	}
+	assert.Equal(t, "UNKNOWN SWAP2BOARD", answers[8])
+}
+
+func TestServe_About(t *testing.T) {
+	assert.Equal(t, []string{gomocup.About}, serve(t, "ABOUT", "END"))
+}
+
+func TestServe_AsEngine(t *testing.T) {
+	// The built-in AI served over the protocol and driven by Engine
+	engine := newTestEngine(t, "serve")
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+	require.NoError(t, board.Put(7, 3, domain.White))
+	for col := 4; col < 8; col++ {
+		require.NoError(t, board.Put(7, col, domain.Black))
+	}
+
+	move, err := engine.BestMove(context.Background(), board, domain.Black)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 7, Col: 8}, move)
+
+	start := time.Now()
+	move, err = engine.BestMove(context.Background(), board, domain.White)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 7, Col: 8}, move)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,301 @@
+// Package mcts is a Monte Carlo tree search AI: UCT selection over the
+// cells next to the stones and playouts biased towards threats. It plays
+// less precisely than the alpha-beta search and makes a livelier
+// opponent.
+package mcts
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/analysis"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+var ErrBoardFull = errors.New("no empty cell left")
+
+const (
+	// DefaultExploration is the UCT exploration constant, sqrt(2)
+	DefaultExploration = math.Sqrt2
+
+	// neighborhood is the distance from the stones where moves are tried
+	neighborhood = 2
+	// maxChildren bounds the moves expanded below a node
+	maxChildren = 16
+	// playoutSamples is the number of cells a playout weighs per move
+	playoutSamples = 6
+	// playoutMoves bounds the length of a playout, longer games are
+	// scored as draws
+	playoutMoves = 30
+)
+
+// Config sets the search budget. The search stops at whichever of
+// TurnTime, Iterations or the context deadline comes first, at least one
+// of them must be set.
+type Config struct {
+	// TurnTime limits the time per move
+	TurnTime time.Duration
+	// Iterations limits the playouts per move, shared by the workers
+	Iterations int
+	// Workers search separate trees in parallel, their root statistics
+	// are summed. It defaults to the number of CPUs.
+	Workers int
+	// Exploration is the UCT constant, DefaultExploration when zero
+	Exploration float64
+}
+
+// Engine searches moves with the configured budget.
+type Engine struct {
+	cfg Config
+}
+
+// New returns an engine searching with the budget of cfg.
+func New(cfg Config) *Engine {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.Exploration == 0 {
+		cfg.Exploration = DefaultExploration
+	}
+	return &Engine{cfg: cfg}
+}
+
+// BestMove returns the move of the side on the board, the board is left
+// unchanged.
+func (e *Engine) BestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error) {
+	moves := candidates(board, side)
+	if len(moves) == 0 {
+		return domain.Point{}, ErrBoardFull
+	}
+	if len(moves) == 1 {
+		return moves[0], nil
+	}
+
+	if e.cfg.TurnTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.cfg.TurnTime)
+		defer cancel()
+	}
+
+	iterations := math.MaxInt
+	if e.cfg.Iterations > 0 {
+		iterations = (e.cfg.Iterations + e.cfg.Workers - 1) / e.cfg.Workers
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		visits = map[domain.Point]int{}
+	)
+	for w := 0; w < e.cfg.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			t := &tree{
+				root:        &node{side: side.Opponent(), untried: moves},
+				board:       board.Clone(),
+				rnd:         rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+				exploration: e.cfg.Exploration,
+			}
+
+			// The first iteration always runs, so there is a move to play
+			for i := 0; i < iterations && (i == 0 || ctx.Err() == nil); i++ {
+				t.iterate()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, child := range t.root.children {
+				visits[child.move] += child.visits
+			}
+		}()
+	}
+	wg.Wait()
+
+	best, most := moves[0], -1
+	for _, m := range moves {
+		if visits[m] > most {
+			best, most = m, visits[m]
+		}
+	}
+
+	return best, nil
+}
+
+// node is a position of the tree, reached by move of side. wins counts
+// the playouts won by side, draws count half.
+type node struct {
+	move     domain.Point
+	side     domain.Stone
+	parent   *node
+	children []*node
+	untried  []domain.Point
+	visits   int
+	wins     float64
+	// won marks moves completing five, the game ends there
+	won bool
+}
+
+// tree is the search of one worker.
+type tree struct {
+	root        *node
+	board       *domain.Board
+	rnd         *rand.Rand
+	exploration float64
+}
+
+// iterate selects a leaf, expands it, plays the game out from there and
+// backs the result up to the root. The board is restored afterwards.
+func (t *tree) iterate() {
+	n := t.root
+	var played []domain.Point
+
+	for len(n.untried) == 0 && len(n.children) > 0 && !n.won {
+		n = t.selectChild(n)
+		t.board.Put(n.move.Row, n.move.Col, n.side)
+		played = append(played, n.move)
+	}
+
+	if !n.won && len(n.untried) > 0 {
+		n = t.expand(n)
+		played = append(played, n.move)
+	}
+
+	var winner domain.Stone
+	if n.won {
+		winner = n.side
+	} else {
+		winner, played = t.playout(n.side.Opponent(), played)
+	}
+
+	for ; n != nil; n = n.parent {
+		n.visits++
+		switch winner {
+		case n.side:
+			n.wins++
+		case domain.Empty:
+			n.wins += 0.5
+		}
+	}
+
+	for _, p := range played {
+		t.board.Remove(p.Row, p.Col)
+	}
+}
+
+// selectChild returns the child with the best upper confidence bound.
+func (t *tree) selectChild(n *node) *node {
+	logVisits := math.Log(float64(n.visits))
+
+	var best *node
+	bestScore := math.Inf(-1)
+	for _, child := range n.children {
+		score := child.wins/float64(child.visits) + t.exploration*math.Sqrt(logVisits/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
+
+// expand plays the most promising untried move of n and adds its node.
+func (t *tree) expand(n *node) *node {
+	move := n.untried[0]
+	n.untried = n.untried[1:]
+
+	side := n.side.Opponent()
+	t.board.Put(move.Row, move.Col, side)
+
+	child := &node{move: move, side: side, parent: n}
+	if t.board.CheckWin(move.Row, move.Col, side, domain.WinLength) {
+		child.won = true
+	} else {
+		child.untried = candidates(t.board, side.Opponent())
+	}
+
+	n.children = append(n.children, child)
+	return child
+}
+
+// candidates returns the moves worth expanding for side, most promising
+// first. A win comes alone, when the opponent threatens five only the
+// blocks are returned.
+func candidates(board *domain.Board, side domain.Stone) []domain.Point {
+	type scored struct {
+		cell  domain.Point
+		score int
+	}
+
+	cells := nearCells(board)
+	if len(cells) == 0 {
+		if board.IsOccupied(board.Size/2, board.Size/2) {
+			return nil
+		}
+		return []domain.Point{{Row: board.Size / 2, Col: board.Size / 2}}
+	}
+
+	var moves, blocks []scored
+	for _, c := range cells {
+		fours, threes, five := analysis.ThreatsThrough(board, c.Row, c.Col, side)
+		if five {
+			return []domain.Point{c}
+		}
+		theirFours, theirThrees, block := analysis.ThreatsThrough(board, c.Row, c.Col, side.Opponent())
+
+		m := scored{cell: c, score: 8*fours + 4*threes + 6*theirFours + 3*theirThrees}
+		if block {
+			blocks = append(blocks, m)
+		}
+		moves = append(moves, m)
+	}
+
+	if len(blocks) > 0 {
+		moves = blocks
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].score > moves[j].score })
+	if len(moves) > maxChildren {
+		moves = moves[:maxChildren]
+	}
+
+	points := make([]domain.Point, len(moves))
+	for i, m := range moves {
+		points[i] = m.cell
+	}
+	return points
+}
+
+// nearCells returns the empty cells close to a stone.
+func nearCells(board *domain.Board) []domain.Point {
+	near := make([]bool, board.Size*board.Size)
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			if board.IsOccupied(row, col) {
+				markNear(board, near, row, col)
+			}
+		}
+	}
+
+	var cells []domain.Point
+	for i, ok := range near {
+		if ok && !board.IsOccupied(i/board.Size, i%board.Size) {
+			cells = append(cells, domain.Point{Row: i / board.Size, Col: i % board.Size})
+		}
+	}
+	return cells
+}
+
+func markNear(board *domain.Board, near []bool, row, col int) {
+	for r := max(row-neighborhood, 0); r <= min(row+neighborhood, board.Size-1); r++ {
+		for c := max(col-neighborhood, 0); c <= min(col+neighborhood, board.Size-1); c++ {
+			near[r*board.Size+c] = true
+		}
+	}
+}
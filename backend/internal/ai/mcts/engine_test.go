@@ -0,0 +1,110 @@
+package mcts_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/ai/mcts"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/domain/domaintest"
+)
+
+var _ ai.Engine = (*mcts.Engine)(nil)
+
+func bestMove(t *testing.T, board *domain.Board, side domain.Stone) domain.Point {
+	t.Helper()
+
+	engine := mcts.New(mcts.Config{TurnTime: time.Second, Iterations: 4000, Workers: 2})
+	move, err := engine.BestMove(context.Background(), board, side)
+	require.NoError(t, err)
+	return move
+}
+
+func TestBestMove_EmptyBoard(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, nil, nil)
+
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, bestMove(t, board, domain.Black))
+}
+
+func TestBestMove_CompletesFive(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 3, 4, 5, 6), append(domaintest.Row(8, 3, 4, 5), domaintest.Row(9, 3, 4)...))
+
+	assert.Contains(t, []domain.Point{{Row: 7, Col: 2}, {Row: 7, Col: 7}}, bestMove(t, board, domain.Black))
+}
+
+func TestBestMove_BlocksFour(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 3, 4, 5, 6), append(domaintest.Row(2, 1), domaintest.Row(7, 2)...))
+
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, bestMove(t, board, domain.White))
+}
+
+func TestBestMove_MakesOpenFour(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 5, 6, 7), domaintest.Row(0, 0, 14))
+
+	move := bestMove(t, board, domain.Black)
+	assert.Contains(t, []domain.Point{{Row: 7, Col: 4}, {Row: 7, Col: 8}}, move)
+}
+
+func TestBestMove_BlocksOpenThree(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 5, 6, 7), domaintest.Row(0, 0, 14))
+
+	move := bestMove(t, board, domain.White)
+	assert.Contains(t, []domain.Point{{Row: 7, Col: 4}, {Row: 7, Col: 8}, {Row: 7, Col: 3}, {Row: 7, Col: 9}}, move)
+}
+
+func TestBestMove_RespectsDeadline(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 6, 8), domaintest.Row(8, 7))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	move, err := mcts.New(mcts.Config{TurnTime: time.Minute}).BestMove(ctx, board, domain.White)
+	require.NoError(t, err)
+	assert.False(t, board.IsOccupied(move.Row, move.Col))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestBestMove_LeavesBoardUnchanged(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 6, 8), domaintest.Row(8, 7))
+	text, err := board.MarshalText()
+	require.NoError(t, err)
+
+	bestMove(t, board, domain.White)
+
+	after, err := board.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, string(text), string(after))
+}
+
+func TestBestMove_FullBoard(t *testing.T) {
+	board := domaintest.NewBoard(t, 3, domaintest.Row(0, 0, 2), domaintest.Row(0, 1))
+	for _, p := range append(domaintest.Row(1, 0, 1, 2), domaintest.Row(2, 0, 1, 2)...) {
+		require.NoError(t, board.Put(p.Row, p.Col, domain.Black))
+	}
+
+	_, err := mcts.New(mcts.Config{Iterations: 10}).BestMove(context.Background(), board, domain.White)
+	assert.ErrorIs(t, err, mcts.ErrBoardFull)
+}
+
+func TestBestMove_SelfPlayFinishes(t *testing.T) {
+	engine := mcts.New(mcts.Config{Iterations: 100, Workers: 2})
+	board := domaintest.NewBoard(t, 9, nil, nil)
+
+	side := domain.Black
+	for i := 0; i < 81; i++ {
+		move, err := engine.BestMove(context.Background(), board, side)
+		require.NoError(t, err)
+		require.NoError(t, board.Put(move.Row, move.Col, side))
+
+		if board.CheckWin(move.Row, move.Col, side, domain.WinLength) {
+			return
+		}
+		side = side.Opponent()
+	}
+}
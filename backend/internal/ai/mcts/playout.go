@@ -0,0 +1,193 @@
+package mcts
+
+import (
+	"math/bits"
+	"slices"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// playout plays the game out from the position on the board, side to
+// move, for at most playoutMoves moves and returns the winner, Empty for
+// a draw. Moves are appended to played so the caller can take them back.
+//
+// Each move completes five or blocks a five of the opponent when it can.
+// Otherwise a few random cells near the stones are weighed by the lines
+// they make or prevent, and one is drawn by weight.
+func (t *tree) playout(side domain.Stone, played []domain.Point) (domain.Stone, []domain.Point) {
+	board := t.board
+
+	near := make([]bool, board.Size*board.Size)
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			if board.IsOccupied(row, col) {
+				markNear(board, near, row, col)
+			}
+		}
+	}
+
+	cells := nearCells(board)
+
+	// fives holds the cells completing five for each side. A stone only
+	// creates such cells on its own lines, so they are updated from there.
+	var fives [3][]domain.Point
+	for _, c := range cells {
+		for _, stone := range []domain.Stone{domain.Black, domain.White} {
+			if makesFive(board, c.Row, c.Col, stone) {
+				fives[stone] = append(fives[stone], c)
+			}
+		}
+	}
+
+	for i := 0; i < playoutMoves; i++ {
+		// Drop the cells taken since the last move
+		free := cells[:0]
+		for _, c := range cells {
+			if !board.IsOccupied(c.Row, c.Col) {
+				free = append(free, c)
+			}
+		}
+		cells = free
+
+		if len(cells) == 0 {
+			return domain.Empty, played
+		}
+
+		var move domain.Point
+		switch {
+		case len(fives[side]) > 0:
+			move = fives[side][0]
+		case len(fives[side.Opponent()]) > 0:
+			move = fives[side.Opponent()][0]
+		default:
+			move = t.playoutMove(cells, side)
+		}
+
+		board.Put(move.Row, move.Col, side)
+		played = append(played, move)
+		if board.CheckWin(move.Row, move.Col, side, domain.WinLength) {
+			return side, played
+		}
+
+		fives[domain.Black] = without(fives[domain.Black], move)
+		fives[domain.White] = without(fives[domain.White], move)
+		fives[side] = addFives(board, fives[side], move, side)
+
+		// Cells around the new stone become candidates
+		for r := max(move.Row-neighborhood, 0); r <= min(move.Row+neighborhood, board.Size-1); r++ {
+			for c := max(move.Col-neighborhood, 0); c <= min(move.Col+neighborhood, board.Size-1); c++ {
+				if !near[r*board.Size+c] {
+					near[r*board.Size+c] = true
+					cells = append(cells, domain.Point{Row: r, Col: c})
+				}
+			}
+		}
+
+		side = side.Opponent()
+	}
+
+	return domain.Empty, played
+}
+
+// playoutMove draws the move of side among a few of the free cells.
+func (t *tree) playoutMove(cells []domain.Point, side domain.Stone) domain.Point {
+	board := t.board
+
+	var (
+		samples [playoutSamples]domain.Point
+		weights [playoutSamples]int
+		total   int
+	)
+	n := min(playoutSamples, len(cells))
+	for i := 0; i < n; i++ {
+		c := cells[t.rnd.IntN(len(cells))]
+
+		samples[i] = c
+		weights[i] = 1 + 2*shapeWeight(board, c.Row, c.Col, side) + shapeWeight(board, c.Row, c.Col, side.Opponent())
+		total += weights[i]
+	}
+
+	x := t.rnd.IntN(total)
+	for i := 0; i < n; i++ {
+		if x < weights[i] {
+			return samples[i]
+		}
+		x -= weights[i]
+	}
+	return samples[n-1]
+}
+
+// without removes the cell from cells.
+func without(cells []domain.Point, cell domain.Point) []domain.Point {
+	for i, c := range cells {
+		if c == cell {
+			return append(cells[:i], cells[i+1:]...)
+		}
+	}
+	return cells
+}
+
+// addFives adds the empty cells on the lines through the stone at move
+// which complete five for the side.
+func addFives(board *domain.Board, cells []domain.Point, move domain.Point, stone domain.Stone) []domain.Point {
+	for _, dir := range domain.Directions {
+		for d := -(domain.WinLength - 1); d < domain.WinLength; d++ {
+			row, col := move.Row+d*dir.DRow, move.Col+d*dir.DCol
+			if d == 0 || board.IsOutOfBounds(row, col) || board.IsOccupied(row, col) {
+				continue
+			}
+
+			c := domain.Point{Row: row, Col: col}
+			if makesFive(board, row, col, stone) && !slices.Contains(cells, c) {
+				cells = append(cells, c)
+			}
+		}
+	}
+	return cells
+}
+
+const fiveMask = 1<<domain.WinLength - 1
+
+// lineWeights rates the longest line a stone makes in a direction by
+// its stones, a four or three counts far more than loose stones.
+var lineWeights = [...]int{0, 0, 1, 4, 16, 16}
+
+// shapeWeight is a quick threat estimate of a stone of the side at
+// (row, col): in each direction the window of five free of the opponent
+// with the most stones of the side counts.
+func shapeWeight(board *domain.Board, row, col int, stone domain.Stone) int {
+	const radius = domain.WinLength - 1
+
+	weight := 0
+	for _, dir := range domain.Directions {
+		own, free := board.LinePattern(row, col, dir, stone, radius)
+		own |= 1 << radius
+
+		most := 0
+		for start := 0; start <= radius; start++ {
+			if w := uint32(fiveMask) << start; (own|free)&w == w {
+				most = max(most, bits.OnesCount32(own&w))
+			}
+		}
+		weight += lineWeights[most]
+	}
+	return weight
+}
+
+// makesFive reports whether a stone of the side at (row, col) completes
+// five in a row.
+func makesFive(board *domain.Board, row, col int, stone domain.Stone) bool {
+	const radius = domain.WinLength - 1
+
+	for _, dir := range domain.Directions {
+		own, _ := board.LinePattern(row, col, dir, stone, radius)
+		own |= 1 << radius
+
+		for start := 0; start <= radius; start++ {
+			if w := uint32(fiveMask) << start; own&w == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package analysis
+
+import "github.com/moLIart/gomoku-backend/internal/domain"
+
+// line is a full board line, cells are ordered along dir.
+type line struct {
+	dir   domain.Direction
+	cells []domain.Point
+}
+
+// boardLines returns every line of the board long enough to hold five.
+func boardLines(board *domain.Board) []line {
+	var lines []line
+
+	for _, dir := range domain.Directions {
+		for row := 0; row < board.Size; row++ {
+			for col := 0; col < board.Size; col++ {
+				// Lines start at cells without a predecessor on the board
+				if !board.IsOutOfBounds(row-dir.DRow, col-dir.DCol) {
+					continue
+				}
+
+				l := line{dir: dir}
+				for r, c := row, col; !board.IsOutOfBounds(r, c); r, c = r+dir.DRow, c+dir.DCol {
+					l.cells = append(l.cells, domain.Point{Row: r, Col: c})
+				}
+
+				if len(l.cells) >= lineLength {
+					lines = append(lines, l)
+				}
+			}
+		}
+	}
+
+	return lines
+}
+
+// masks returns the stones of the side and the empty cells as line bits.
+func (l line) masks(board *domain.Board, stone domain.Stone) (own, free uint64) {
+	for i, p := range l.cells {
+		switch board.At(p.Row, p.Col) {
+		case stone:
+			own |= 1 << i
+		case domain.Empty:
+			free |= 1 << i
+		}
+	}
+	return own, free
+}
+
+// points maps line bits back to board cells.
+func (l line) points(mask uint64) []domain.Point {
+	var points []domain.Point
+	for i, p := range l.cells {
+		if mask&(1<<i) != 0 {
+			points = append(points, p)
+		}
+	}
+	return points
+}
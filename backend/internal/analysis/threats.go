@@ -0,0 +1,312 @@
+// Package analysis finds gomoku threats: fours and threes of each side,
+// the cells completing them and the cells defending against them.
+package analysis
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+const lineLength = domain.WinLength
+
+type ThreatKind string
+
+const (
+	OpenFour  ThreatKind = "open_four"  // two ways to complete five, can't be stopped
+	Four      ThreatKind = "four"       // one way to complete five
+	OpenThree ThreatKind = "open_three" // becomes an open four
+	Three     ThreatKind = "three"      // becomes a four
+)
+
+// Threat is a group of stones on one line threatening to become five.
+type Threat struct {
+	Kind      ThreatKind
+	Stone     domain.Stone
+	Direction domain.Direction
+
+	// Stones forming the threat
+	Stones []domain.Point
+	// Gains are the cells upgrading the threat: fives for fours
+	// and fours (open fours for open threes) for threes
+	Gains []domain.Point
+	// Defenses are the cells where the opponent stops the threat
+	Defenses []domain.Point
+}
+
+type ForkKind string
+
+const (
+	FourFour   ForkKind = "four_four"
+	FourThree  ForkKind = "four_three"
+	ThreeThree ForkKind = "three_three"
+)
+
+// Fork is an empty cell creating two threats on different lines at once.
+type Fork struct {
+	Kind  ForkKind
+	Stone domain.Stone
+	Cell  domain.Point
+}
+
+// Threats returns the threats of the given side, fours first.
+func Threats(board *domain.Board, stone domain.Stone) []Threat {
+	var threats []Threat
+
+	for _, line := range boardLines(board) {
+		own, free := line.masks(board, stone)
+		for _, p := range scanLine(own, free, len(line.cells)) {
+			threats = append(threats, Threat{
+				Kind:      p.kind,
+				Stone:     stone,
+				Direction: line.dir,
+				Stones:    line.points(p.stones),
+				Gains:     line.points(p.gains),
+				Defenses:  line.points(p.defenses),
+			})
+		}
+	}
+
+	sort.SliceStable(threats, func(i, j int) bool {
+		return kindOrder[threats[i].Kind] < kindOrder[threats[j].Kind]
+	})
+
+	return threats
+}
+
+var kindOrder = map[ThreatKind]int{OpenFour: 0, Four: 1, OpenThree: 2, Three: 3}
+
+// Forks returns the empty cells where the side creates a double threat.
+// Cells completing five are wins rather than forks and are left out.
+func Forks(board *domain.Board, stone domain.Stone) []Fork {
+	var forks []Fork
+
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			if board.IsOccupied(row, col) {
+				continue
+			}
+
+			fours, threes, five := ThreatsThrough(board, row, col, stone)
+			if five {
+				continue
+			}
+
+			var kind ForkKind
+			switch {
+			case fours >= 2:
+				kind = FourFour
+			case fours == 1 && threes >= 1:
+				kind = FourThree
+			case threes >= 2:
+				kind = ThreeThree
+			default:
+				continue
+			}
+
+			forks = append(forks, Fork{Kind: kind, Stone: stone, Cell: domain.Point{Row: row, Col: col}})
+		}
+	}
+
+	return forks
+}
+
+// patternRadius covers every threat through a cell: an open three needs
+// the cells of its straight four and one more cell on each end.
+const patternRadius = lineLength
+
+// ThreatsThrough counts the lines where a stone placed at (row, col)
+// makes a four or an open three, and whether it completes five.
+func ThreatsThrough(board *domain.Board, row, col int, stone domain.Stone) (fours, threes int, five bool) {
+	const center = uint64(1) << patternRadius
+
+	for _, dir := range domain.Directions {
+		own32, free32 := board.LinePattern(row, col, dir, stone, patternRadius)
+		own, free := uint64(own32)|center, uint64(free32)&^center
+
+		if hasFive(own, center) {
+			return 0, 0, true
+		}
+
+		found := Three
+		for _, p := range scanLine(own, free, 2*patternRadius+1) {
+			if p.stones&center == 0 {
+				continue
+			}
+
+			if p.kind == Four || p.kind == OpenFour {
+				found = Four
+				break
+			}
+			if p.kind == OpenThree {
+				found = OpenThree
+			}
+		}
+
+		switch found {
+		case Four:
+			fours++
+		case OpenThree:
+			threes++
+		}
+	}
+
+	return fours, threes, false
+}
+
+// ThreeDefenses returns the cells stopping the open threes a stone of the
+// side at (row, col) makes, on every line through it.
+func ThreeDefenses(board *domain.Board, row, col int, stone domain.Stone) []domain.Point {
+	const center = uint64(1) << patternRadius
+
+	var defenses []domain.Point
+	for _, dir := range domain.Directions {
+		own32, free32 := board.LinePattern(row, col, dir, stone, patternRadius)
+		own, free := uint64(own32)|center, uint64(free32)&^center
+
+		var mask uint64
+		for _, p := range scanLine(own, free, 2*patternRadius+1) {
+			if p.kind == OpenThree && p.stones&center != 0 {
+				mask |= p.defenses
+			}
+		}
+
+		for ; mask != 0; mask &= mask - 1 {
+			d := bits.TrailingZeros64(mask) - patternRadius
+			defenses = append(defenses, domain.Point{Row: row + d*dir.DRow, Col: col + d*dir.DCol})
+		}
+	}
+	return defenses
+}
+
+// hasFive reports whether own has lineLength stones in a row covering mask.
+func hasFive(own, mask uint64) bool {
+	window := uint64(1)<<lineLength - 1
+	for p := 0; p+lineLength <= bits.Len64(own); p++ {
+		w := window << p
+		if own&w == w && w&mask == mask {
+			return true
+		}
+	}
+	return false
+}
+
+// pattern is a threat on a single line, cells are bits of the line.
+type pattern struct {
+	kind     ThreatKind
+	stones   uint64
+	gains    uint64
+	defenses uint64
+}
+
+// scanLine finds the threats on a line of n cells, own marks stones of
+// the side and free marks empty cells.
+func scanLine(own, free uint64, n int) []pattern {
+	fours := map[uint64]uint64{}
+	threes := map[uint64]uint64{}
+
+	window := uint64(1)<<lineLength - 1
+	for p := 0; p+lineLength <= n; p++ {
+		w := window << p
+		if (own|free)&w != w {
+			// Blocked by the opponent or the edge of the board
+			continue
+		}
+
+		switch stones := own & w; bits.OnesCount64(stones) {
+		case lineLength - 1:
+			fours[stones] |= free & w
+		case lineLength - 2:
+			threes[stones] |= free & w
+		}
+	}
+
+	var patterns []pattern
+	for _, stones := range sortedKeys(fours) {
+		kind := Four
+		if bits.OnesCount64(fours[stones]) >= 2 {
+			kind = OpenFour
+		}
+		patterns = append(patterns, pattern{kind: kind, stones: stones, gains: fours[stones], defenses: fours[stones]})
+	}
+
+	for _, stones := range sortedKeys(threes) {
+		if partOfFour(stones, fours) {
+			continue
+		}
+
+		// An open three turns into a straight four: four stones in a row
+		// with free cells on both ends, which can't be blocked anymore
+		if straight := straightFourGains(own, free, stones, threes[stones]); straight != 0 {
+			var defenses uint64
+			for d := uint64(1); d != 0; d <<= 1 {
+				if free&d != 0 && d&spanMask(stones) != 0 &&
+					straightFourGains(own, free&^d, stones, threes[stones]) == 0 {
+					defenses |= d
+				}
+			}
+
+			patterns = append(patterns, pattern{kind: OpenThree, stones: stones, gains: straight, defenses: defenses})
+			continue
+		}
+
+		patterns = append(patterns, pattern{kind: Three, stones: stones, gains: threes[stones], defenses: threes[stones]})
+	}
+
+	return patterns
+}
+
+// straightFourGains returns the candidate cells which turn the stones into
+// a straight four.
+func straightFourGains(own, free, stones, candidates uint64) uint64 {
+	var gains uint64
+
+	four := uint64(1)<<(lineLength-1) - 1
+	for g := candidates & free; g != 0; g &= g - 1 {
+		cell := g & -g
+		placed := own | cell
+
+		// The end past the four is the highest cell used
+		for p := 1; p+lineLength <= bits.Len64(free); p++ {
+			w := four << p
+			ends := uint64(1)<<(p-1) | uint64(1)<<(p+lineLength-1)
+			if placed&w == w && w&(stones|cell) == stones|cell && free&ends == ends {
+				gains |= cell
+				break
+			}
+		}
+	}
+
+	return gains
+}
+
+// spanMask covers the stones and lineLength-1 cells around them, where
+// every cell of a threat built on these stones lies.
+func spanMask(stones uint64) uint64 {
+	lo := bits.TrailingZeros64(stones)
+	hi := 63 - bits.LeadingZeros64(stones)
+
+	lo = max(lo-(lineLength-1), 0)
+	hi = min(hi+lineLength-1, 63)
+	return (^uint64(0) >> (63 - hi)) &^ (uint64(1)<<lo - 1)
+}
+
+func partOfFour(stones uint64, fours map[uint64]uint64) bool {
+	for four := range fours {
+		if four&stones == stones {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[uint64]uint64) []uint64 {
+	keys := make([]uint64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,142 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/analysis"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/domain/domaintest"
+)
+
+func TestThreats_OpenFour(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 3, 4, 5, 6), nil)
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.OpenFour, threats[0].Kind)
+	assert.Equal(t, domain.Directions[0], threats[0].Direction)
+	assert.Equal(t, domaintest.Row(7, 3, 4, 5, 6), threats[0].Stones)
+	assert.Equal(t, domaintest.Row(7, 2, 7), threats[0].Gains)
+	assert.Equal(t, domaintest.Row(7, 2, 7), threats[0].Defenses)
+}
+
+func TestThreats_ClosedFour(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 3, 4, 5, 6), domaintest.Row(7, 2))
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.Four, threats[0].Kind)
+	assert.Equal(t, domaintest.Row(7, 7), threats[0].Gains)
+}
+
+func TestThreats_BrokenFour(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 3, 4, 6, 7), nil)
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.Four, threats[0].Kind)
+	assert.Equal(t, domaintest.Row(7, 5), threats[0].Gains)
+}
+
+func TestThreats_OpenThree(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 5, 6, 7), nil)
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.OpenThree, threats[0].Kind)
+	assert.Equal(t, domaintest.Row(7, 4, 8), threats[0].Gains)
+	assert.Equal(t, domaintest.Row(7, 4, 8), threats[0].Defenses)
+}
+
+func TestThreats_SplitThree(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 4, 6, 7), nil)
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.OpenThree, threats[0].Kind)
+	assert.Equal(t, domaintest.Row(7, 5), threats[0].Gains)
+	assert.Equal(t, domaintest.Row(7, 3, 5, 8), threats[0].Defenses)
+}
+
+func TestThreats_ClosedThree(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 3, 4, 5), domaintest.Row(7, 2))
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.Three, threats[0].Kind)
+	assert.Equal(t, domaintest.Row(7, 6, 7), threats[0].Gains)
+}
+
+func TestThreats_EdgeBlocksThree(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 0, 1, 2), nil)
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.Three, threats[0].Kind)
+}
+
+func TestThreats_DeadThree(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 3, 4, 5), domaintest.Row(7, 2, 7))
+
+	assert.Empty(t, analysis.Threats(board, domain.Black))
+}
+
+func TestThreats_OtherSide(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 5, 6, 7), nil)
+
+	assert.Empty(t, analysis.Threats(board, domain.White))
+}
+
+func TestThreats_Diagonal(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, []domain.Point{{Row: 3, Col: 3}, {Row: 4, Col: 4}, {Row: 5, Col: 5}, {Row: 6, Col: 6}}, nil)
+
+	threats := analysis.Threats(board, domain.Black)
+	require.Len(t, threats, 1)
+	assert.Equal(t, analysis.OpenFour, threats[0].Kind)
+	assert.Equal(t, domain.Directions[2], threats[0].Direction)
+	assert.Equal(t, []domain.Point{{Row: 2, Col: 2}, {Row: 7, Col: 7}}, threats[0].Gains)
+}
+
+func TestThreeDefenses(t *testing.T) {
+	black := append(domaintest.Row(7, 4, 6), domain.Point{Row: 5, Col: 7}, domain.Point{Row: 6, Col: 7})
+	board := domaintest.NewBoard(t, 15, black, nil)
+
+	defenses := analysis.ThreeDefenses(board, 7, 7, domain.Black)
+	assert.ElementsMatch(t, append(domaintest.Row(7, 3, 5, 8), domain.Point{Row: 4, Col: 7}, domain.Point{Row: 8, Col: 7}), defenses)
+}
+
+func TestThreeDefenses_ClosedThree(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, domaintest.Row(7, 4, 5), domaintest.Row(7, 3))
+
+	assert.Empty(t, analysis.ThreeDefenses(board, 7, 6, domain.Black))
+}
+
+func TestForks_FourThree(t *testing.T) {
+	black := append(domaintest.Row(7, 4, 5, 6), domain.Point{Row: 5, Col: 7}, domain.Point{Row: 6, Col: 7})
+	board := domaintest.NewBoard(t, 15, black, domaintest.Row(7, 3))
+
+	forks := analysis.Forks(board, domain.Black)
+	require.Len(t, forks, 1)
+	assert.Equal(t, analysis.FourThree, forks[0].Kind)
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, forks[0].Cell)
+}
+
+func TestForks_ThreeThree(t *testing.T) {
+	black := []domain.Point{{Row: 7, Col: 5}, {Row: 7, Col: 6}, {Row: 5, Col: 7}, {Row: 6, Col: 7}}
+	board := domaintest.NewBoard(t, 15, black, nil)
+
+	forks := analysis.Forks(board, domain.Black)
+	assert.Contains(t, forks, analysis.Fork{Kind: analysis.ThreeThree, Stone: domain.Black, Cell: domain.Point{Row: 7, Col: 7}})
+}
+
+func TestForks_FiveIsNotFork(t *testing.T) {
+	black := append(domaintest.Row(7, 3, 4, 5, 6), domain.Point{Row: 5, Col: 7}, domain.Point{Row: 6, Col: 7})
+	board := domaintest.NewBoard(t, 15, black, nil)
+
+	for _, fork := range analysis.Forks(board, domain.Black) {
+		assert.NotEqual(t, domain.Point{Row: 7, Col: 7}, fork.Cell)
+	}
+}
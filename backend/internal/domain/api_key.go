@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// The scopes of the API keys: read lists and shows the games, play starts,
+// joins and plays them.
+const (
+	ScopeRead = "read"
+	ScopePlay = "play"
+)
+
+var Scopes = []string{ScopeRead, ScopePlay}
+
+// APIKey authenticates a bot. Keys are long-lived until revoked, only the
+// hash of the key is kept.
+type APIKey struct {
+	Entity
+
+	PlayerID int32
+	Name     string
+	// Prefix is the start of the key, it tells the keys apart in the list
+	Prefix     string
+	Hash       string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrAPIKeyInvalid  = errors.New("invalid or revoked API key")
+	ErrAPIKeyScope    = errors.New("API key lacks the scope")
+)
+
+// NewAPIKey returns the key of the bot, without scopes it gets all of
+// them.
+func NewAPIKey(bot *Player, name string, scopes []string, prefix, hash string, now time.Time) (*APIKey, error) {
+	if !bot.Bot {
+		return nil, ErrPlayerNotFound
+	}
+	if len(name) < 1 || len(name) > 50 {
+		return nil, fmt.Errorf("key name must be between 1 and 50 characters long")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	// Kept in the order of Scopes, without repeats
+	granted := slices.Clone(Scopes)
+	if len(scopes) > 0 {
+		granted = slices.DeleteFunc(granted, func(scope string) bool {
+			return !slices.Contains(scopes, scope)
+		})
+	}
+
+	return &APIKey{
+		PlayerID:  bot.ID,
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    granted,
+		CreatedAt: now,
+	}, nil
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Revoke denies the key from now on, revoking a revoked key keeps the
+// first revocation.
+func (k *APIKey) Revoke(now time.Time) {
+	if k.RevokedAt == nil {
+		k.RevokedAt = &now
+	}
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,54 @@
+package domain_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	bot := &domain.Player{Entity: domain.Entity{ID: 3}, Nickname: "AliceBot", Bot: true}
+	now := time.Now()
+
+	key, err := domain.NewAPIKey(bot, "ci", nil, "gmk_12345678", "hash", now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if key.PlayerID != 3 || !slices.Equal(key.Scopes, domain.Scopes) {
+		t.Errorf("expected a key of the bot with all the scopes, got %+v", key)
+	}
+
+	key, err = domain.NewAPIKey(bot, "viewer", []string{domain.ScopeRead, domain.ScopeRead}, "gmk_12345678", "hash", now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !key.HasScope(domain.ScopeRead) || key.HasScope(domain.ScopePlay) || len(key.Scopes) != 1 {
+		t.Errorf("expected the read scope only, got %v", key.Scopes)
+	}
+
+	if _, err := domain.NewAPIKey(bot, "ci", []string{"admin"}, "gmk_12345678", "hash", now); err == nil {
+		t.Error("expected error for an unknown scope, got nil")
+	}
+	if _, err := domain.NewAPIKey(bot, "", nil, "gmk_12345678", "hash", now); err == nil {
+		t.Error("expected error for an empty name, got nil")
+	}
+
+	player := &domain.Player{Entity: domain.Entity{ID: 4}, Nickname: "Alice"}
+	if _, err := domain.NewAPIKey(player, "ci", nil, "gmk_12345678", "hash", now); err != domain.ErrPlayerNotFound {
+		t.Errorf("expected only bots to have keys, got %v", err)
+	}
+}
+
+func TestAPIKey_Revoke(t *testing.T) {
+	key := &domain.APIKey{}
+	first := time.Now()
+
+	key.Revoke(first)
+	key.Revoke(first.Add(time.Hour))
+
+	if !key.IsRevoked() || !key.RevokedAt.Equal(first) {
+		t.Errorf("expected the first revocation to be kept, got %v", key.RevokedAt)
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// The actions of the administration API, written to the audit log.
+const (
+	AuditBanPlayer    = "player.ban"
+	AuditUnbanPlayer  = "player.unban"
+	AuditSetRole      = "player.role"
+	AuditAdjustScore  = "player.score"
+	AuditFinishGame   = "game.finish"
+	AuditAnnulGame    = "game.annul"
+	AuditReloadBook   = "book.reload"
+	AuditImportPuzzle = "puzzle.import"
+)
+
+// The kinds of the audited targets.
+const (
+	AuditTargetPlayer = "player"
+	AuditTargetGame   = "game"
+	AuditTargetBook   = "book"
+	AuditTargetPuzzle = "puzzle"
+)
+
+// AuditEntry records an action of a moderator or an admin, it is written
+// in the transaction of the action.
+type AuditEntry struct {
+	Entity
+
+	ActorID    int32
+	Action     string
+	TargetType string
+	// TargetID is zero for the targets without an ID, the opening book
+	TargetID int32
+	// Details hold what changed, e.g. the old and the new role
+	Details   map[string]any
+	CreatedAt time.Time
+}
+
+func NewAuditEntry(actorID int32, action, targetType string, targetID int32, details map[string]any) *AuditEntry {
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	return &AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    details,
+		CreatedAt:  time.Now(),
+	}
+}
@@ -1,10 +1,70 @@
 package domain
 
-import "fmt"
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
 
+// Stone identifies the side occupying a cell. Black always moves first,
+// the mapping from stones to players lives on the Game.
+type Stone int8
+
+const (
+	Empty Stone = iota
+	Black
+	White
+)
+
+// Opponent returns the opposite side, Empty stays Empty.
+func (s Stone) Opponent() Stone {
+	switch s {
+	case Black:
+		return White
+	case White:
+		return Black
+	}
+	return Empty
+}
+
+func (s Stone) String() string {
+	switch s {
+	case Black:
+		return "black"
+	case White:
+		return "white"
+	}
+	return "empty"
+}
+
+// Direction is a unit step along one of the board lines.
+type Direction struct {
+	DRow, DCol int
+}
+
+// Directions lists horizontal, vertical and both diagonal lines.
+var Directions = [...]Direction{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+// Point is a cell on the board.
+type Point struct {
+	Row, Col int
+}
+
+// MaxPatternRadius is the largest radius accepted by LinePattern.
+const MaxPatternRadius = 15
+
+// MaxBoardSize is the largest supported board, every line fits one word.
+const MaxBoardSize = 64
+
+// Board keeps the stones of each side as bitboards, one per line
+// direction: line i of Directions[d] holds its cells as bits of
+// lines[side][d][i], so line patterns are a shift and a mask away.
+// The Zobrist hash of the position is kept up to date on every change.
 type Board struct {
-	Size int
-	Data [][]int32
+	Size  int
+	lines [2][len(Directions)][]uint64
+	hash  uint64
 }
 
 func NewBoard(size int) (*Board, error) {
@@ -12,19 +72,69 @@ func NewBoard(size int) (*Board, error) {
 		return nil, fmt.Errorf("board size must be at least 3x3")
 	}
 
-	g := &Board{
-		Size: size,
-		Data: make([][]int32, size),
+	if size > MaxBoardSize {
+		return nil, fmt.Errorf("board size must be at most %dx%d", MaxBoardSize, MaxBoardSize)
 	}
 
-	for i := 0; i < size; i++ {
-		g.Data[i] = make([]int32, size)
+	g := &Board{Size: size, hash: zobristSizeKeys[size]}
+	for side := range g.lines {
+		g.lines[side][0] = make([]uint64, size)
+		g.lines[side][1] = make([]uint64, size)
+		g.lines[side][2] = make([]uint64, 2*size-1)
+		g.lines[side][3] = make([]uint64, 2*size-1)
 	}
 
 	return g, nil
 }
 
-func (g *Board) Put(row, col int, player *Player) error {
+// lineOf returns the line through (row, col) along Directions[d] and the
+// position of the cell on that line.
+func (g *Board) lineOf(row, col, d int) (line, pos int) {
+	switch d {
+	case 0:
+		return row, col
+	case 1:
+		return col, row
+	case 2:
+		return col - row + g.Size - 1, row
+	default:
+		return row + col, row
+	}
+}
+
+// lineMask returns the bits of positions that lie on the board.
+func (g *Board) lineMask(line, d int) uint64 {
+	lo, hi := 0, g.Size-1
+	switch d {
+	case 2:
+		lo, hi = max(lo, g.Size-1-line), min(hi, 2*g.Size-2-line)
+	case 3:
+		lo, hi = max(lo, line-g.Size+1), min(hi, line)
+	}
+	return (^uint64(0) >> (63 - hi)) &^ (1<<lo - 1)
+}
+
+func (g *Board) set(row, col int, stone Stone) {
+	for d := range Directions {
+		line, pos := g.lineOf(row, col, d)
+		g.lines[stone-1][d][line] |= 1 << pos
+	}
+	g.hash ^= zobristKey(stone, row, col)
+}
+
+func (g *Board) unset(row, col int, stone Stone) {
+	for d := range Directions {
+		line, pos := g.lineOf(row, col, d)
+		g.lines[stone-1][d][line] &^= 1 << pos
+	}
+	g.hash ^= zobristKey(stone, row, col)
+}
+
+func (g *Board) Put(row, col int, stone Stone) error {
+	if stone != Black && stone != White {
+		return fmt.Errorf("invalid stone %d", stone)
+	}
+
 	if g.IsOutOfBounds(row, col) {
 		return fmt.Errorf("invalid position (%d, %d)", row, col)
 	}
@@ -33,7 +143,22 @@ func (g *Board) Put(row, col int, player *Player) error {
 		return fmt.Errorf("position (%d, %d) is already occupied", row, col)
 	}
 
-	g.Data[row][col] = player.ID
+	g.set(row, col, stone)
+	return nil
+}
+
+// Remove takes the stone back from the given cell.
+func (g *Board) Remove(row, col int) error {
+	if g.IsOutOfBounds(row, col) {
+		return fmt.Errorf("invalid position (%d, %d)", row, col)
+	}
+
+	stone := g.At(row, col)
+	if stone == Empty {
+		return fmt.Errorf("position (%d, %d) is empty", row, col)
+	}
+
+	g.unset(row, col, stone)
 	return nil
 }
 
@@ -41,27 +166,105 @@ func (g *Board) GetSize() int {
 	return g.Size
 }
 
-func (g *Board) CheckWin(row, col int, player *Player, maxLine int) bool {
-	maxLine = min(maxLine, g.Size)
+// At returns the stone at the given cell, Empty for free or out of bounds cells.
+func (g *Board) At(row, col int) Stone {
+	if g.IsOutOfBounds(row, col) {
+		return Empty
+	}
 
-	// horizontal, vertical and diagonal
-	directions := [...][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	switch {
+	case g.lines[0][0][row]&(1<<col) != 0:
+		return Black
+	case g.lines[1][0][row]&(1<<col) != 0:
+		return White
+	}
+	return Empty
+}
 
-	for _, dir := range directions {
-		dr, dc := dir[0], dir[1]
-		count := 1
+// Count returns the number of stones of the given side on the board.
+func (g *Board) Count(stone Stone) int {
+	if stone != Black && stone != White {
+		return 0
+	}
 
-		// Check in both directions
-		for _, step := range []int{-1, 1} {
-			r, c := row+step*dr, col+step*dc
-			for !g.IsOutOfBounds(r, c) && g.IsOccupied(r, c, player) {
-				count++
-				r += step * dr
-				c += step * dc
-			}
+	n := 0
+	for _, row := range g.lines[stone-1][0] {
+		n += bits.OnesCount64(row)
+	}
+	return n
+}
+
+// Clone returns a deep copy of the board.
+func (g *Board) Clone() *Board {
+	c := &Board{Size: g.Size, hash: g.hash}
+	for side := range g.lines {
+		for d := range g.lines[side] {
+			c.lines[side][d] = append([]uint64(nil), g.lines[side][d]...)
 		}
+	}
+	return c
+}
 
-		if count >= maxLine {
+// LinePattern extracts 2*radius+1 cells centered at (row, col) along dir.
+// Bit i of the result stands for the cell at offset i-radius: own marks
+// stones of the given side and free marks empty cells, cells outside of
+// the board are set in neither mask.
+func (g *Board) LinePattern(row, col int, dir Direction, stone Stone, radius int) (own, free uint32) {
+	if g.IsOutOfBounds(row, col) || stone != Black && stone != White {
+		return 0, 0
+	}
+
+	var d int
+	switch dir {
+	case Directions[0]:
+		d = 0
+	case Directions[1]:
+		d = 1
+	case Directions[2]:
+		d = 2
+	case Directions[3]:
+		d = 3
+	case Direction{-Directions[0].DRow, -Directions[0].DCol},
+		Direction{-Directions[1].DRow, -Directions[1].DCol},
+		Direction{-Directions[2].DRow, -Directions[2].DCol},
+		Direction{-Directions[3].DRow, -Directions[3].DCol}:
+		// Opposite direction, walk the same line backwards
+		own, free = g.LinePattern(row, col, Direction{-dir.DRow, -dir.DCol}, stone, radius)
+		n := 2*min(radius, MaxPatternRadius) + 1
+		return bits.Reverse32(own) >> (32 - n), bits.Reverse32(free) >> (32 - n)
+	default:
+		return 0, 0
+	}
+
+	radius = min(radius, MaxPatternRadius)
+	line, pos := g.lineOf(row, col, d)
+
+	mine := g.lines[stone-1][d][line]
+	theirs := g.lines[2-stone][d][line]
+	empty := g.lineMask(line, d) &^ (mine | theirs)
+
+	return window(mine, pos, radius), window(empty, pos, radius)
+}
+
+// window returns 2*radius+1 bits of word centered at pos.
+func window(word uint64, pos, radius int) uint32 {
+	if shift := pos - radius; shift >= 0 {
+		word >>= shift
+	} else {
+		word <<= -shift
+	}
+	return uint32(word & (1<<(2*radius+1) - 1))
+}
+
+func (g *Board) CheckWin(row, col int, stone Stone, maxLine int) bool {
+	maxLine = min(maxLine, g.Size)
+
+	if !g.IsOccupied(row, col, stone) {
+		return false
+	}
+
+	for d := range Directions {
+		if before, after := g.run(row, col, d, stone); 1+before+after >= maxLine {
 			return true
 		}
 	}
@@ -69,20 +272,144 @@ func (g *Board) CheckWin(row, col int, player *Player, maxLine int) bool {
 	return false
 }
 
+// WinningLine returns the cells of the line of at least maxLine stones
+// through (row, col), ordered along its direction, or nil if there is none.
+func (g *Board) WinningLine(row, col int, stone Stone, maxLine int) []Point {
+	maxLine = min(maxLine, g.Size)
+
+	if !g.IsOccupied(row, col, stone) {
+		return nil
+	}
+
+	for d, dir := range Directions {
+		before, after := g.run(row, col, d, stone)
+		if 1+before+after < maxLine {
+			continue
+		}
+
+		line := make([]Point, 0, 1+before+after)
+		for i := -before; i <= after; i++ {
+			line = append(line, Point{Row: row + i*dir.DRow, Col: col + i*dir.DCol})
+		}
+		return line
+	}
+
+	return nil
+}
+
+// run counts the stones of the side next to (row, col) along Directions[d],
+// before and after the cell.
+func (g *Board) run(row, col, d int, stone Stone) (before, after int) {
+	line, pos := g.lineOf(row, col, d)
+	own := g.lines[stone-1][d][line]
+
+	after = bits.TrailingZeros64(^(own >> (pos + 1)))
+	before = bits.LeadingZeros64(^(own << (64 - pos)))
+	return before, after
+}
+
 func (g *Board) IsOutOfBounds(row, col int) bool {
 	return row < 0 || row >= g.Size || col < 0 || col >= g.Size
 }
 
-func (g *Board) IsOccupied(row, col int, player ...*Player) bool {
+func (g *Board) IsOccupied(row, col int, stone ...Stone) bool {
 	if g.IsOutOfBounds(row, col) {
 		return false
 	}
 
-	if len(player) > 0 && player[0] != nil {
-		return g.Data[row][col] == player[0].ID
+	if len(stone) > 0 && stone[0] != Empty {
+		return g.At(row, col) == stone[0]
 	}
 
-	return g.Data[row][col] != 0
+	return g.At(row, col) != Empty
+}
+
+var stoneRunes = [...]byte{Empty: '.', Black: 'x', White: 'o'}
+
+// MarshalText encodes the board as "<size>:<runs>", every run is an
+// optional repeat count followed by '.', 'x' or 'o' for empty, black
+// and white cells: an empty 15x15 board is "15:225.".
+func (g *Board) MarshalText() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(g.Size))
+	sb.WriteByte(':')
+
+	total := g.Size * g.Size
+	for i := 0; i < total; {
+		stone := g.At(i/g.Size, i%g.Size)
+
+		j := i + 1
+		for j < total && g.At(j/g.Size, j%g.Size) == stone {
+			j++
+		}
+
+		if j-i > 1 {
+			sb.WriteString(strconv.Itoa(j - i))
+		}
+		sb.WriteByte(stoneRunes[stone])
+		i = j
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// UnmarshalText decodes the format produced by MarshalText.
+func (g *Board) UnmarshalText(text []byte) error {
+	sizeText, runs, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("invalid board encoding: missing size")
+	}
+
+	size, err := strconv.Atoi(sizeText)
+	if err != nil {
+		return fmt.Errorf("invalid board encoding: %w", err)
+	}
+
+	board, err := NewBoard(size)
+	if err != nil {
+		return err
+	}
+
+	pos, total := 0, size*size
+	for i := 0; i < len(runs); i++ {
+		count := 1
+		if j := strings.IndexFunc(runs[i:], func(r rune) bool { return r < '0' || r > '9' }); j > 0 {
+			if count, err = strconv.Atoi(runs[i : i+j]); err != nil {
+				return fmt.Errorf("invalid board encoding: %w", err)
+			}
+			i += j
+		}
+
+		var stone Stone
+		switch runs[i] {
+		case '.':
+			stone = Empty
+		case 'x':
+			stone = Black
+		case 'o':
+			stone = White
+		default:
+			return fmt.Errorf("invalid board encoding: unexpected %q", runs[i])
+		}
+
+		if pos+count > total {
+			return fmt.Errorf("invalid board encoding: too many cells")
+		}
+
+		for ; count > 0; count-- {
+			if stone != Empty {
+				board.set(pos/size, pos%size, stone)
+			}
+			pos++
+		}
+	}
+
+	if pos != total {
+		return fmt.Errorf("invalid board encoding: expected %d cells, got %d", total, pos)
+	}
+
+	*g = *board
+	return nil
 }
 
 // min is a helper function for CheckWin
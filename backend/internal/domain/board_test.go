@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/moLIart/gomoku-backend/internal/domain"
@@ -31,18 +32,15 @@ func TestBoard_PutAndIsOccupied(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("TestPlayer", "securepassword")
-	player.ID = 1
-
-	err = board.Put(1, 1, player)
+	err = board.Put(1, 1, domain.Black)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 	if !board.IsOccupied(1, 1) {
 		t.Errorf("expected position (1,1) to be occupied")
 	}
-	if !board.IsOccupied(1, 1, player) {
-		t.Errorf("expected position (1,1) to be occupied by player")
+	if !board.IsOccupied(1, 1, domain.Black) {
+		t.Errorf("expected position (1,1) to be occupied by black")
 	}
 }
 
@@ -51,10 +49,7 @@ func TestBoard_Put_OutOfBounds(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("TestPlayer", "securepassword")
-	player.ID = 1
-
-	err = board.Put(3, 3, player)
+	err = board.Put(3, 3, domain.Black)
 	if err == nil {
 		t.Fatal("expected error for out of bounds, got nil")
 	}
@@ -65,11 +60,8 @@ func TestBoard_Put_AlreadyOccupied(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("TestPlayer", "securepassword")
-	player.ID = 1
-
-	_ = board.Put(0, 0, player)
-	err = board.Put(0, 0, player)
+	_ = board.Put(0, 0, domain.Black)
+	err = board.Put(0, 0, domain.Black)
 	if err == nil {
 		t.Fatal("expected error for already occupied position, got nil")
 	}
@@ -94,13 +86,10 @@ func TestBoard_CheckWin_Horizontal(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("Winner", "securepassword")
-	player.ID = 1
-
 	for col := 0; col < 3; col++ {
-		_ = board.Put(0, col, player)
+		_ = board.Put(0, col, domain.Black)
 	}
-	if !board.CheckWin(0, 2, player, 3) {
+	if !board.CheckWin(0, 2, domain.Black, 3) {
 		t.Errorf("expected horizontal win")
 	}
 }
@@ -110,13 +99,10 @@ func TestBoard_CheckWin_Vertical(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("Winner", "securepassword")
-	player.ID = 1
-
 	for row := 0; row < 3; row++ {
-		_ = board.Put(row, 0, player)
+		_ = board.Put(row, 0, domain.Black)
 	}
-	if !board.CheckWin(2, 0, player, 3) {
+	if !board.CheckWin(2, 0, domain.Black, 3) {
 		t.Errorf("expected vertical win")
 	}
 }
@@ -126,13 +112,10 @@ func TestBoard_CheckWin_Diagonal(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("Winner", "securepassword")
-	player.ID = 1
-
 	for i := 0; i < 3; i++ {
-		_ = board.Put(i, i, player)
+		_ = board.Put(i, i, domain.Black)
 	}
-	if !board.CheckWin(2, 2, player, 3) {
+	if !board.CheckWin(2, 2, domain.Black, 3) {
 		t.Errorf("expected diagonal win")
 	}
 }
@@ -142,13 +125,10 @@ func TestBoard_CheckWin_AntiDiagonal(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("Winner", "securepassword")
-	player.ID = 1
-
 	for i := 0; i < 3; i++ {
-		_ = board.Put(i, 2-i, player)
+		_ = board.Put(i, 2-i, domain.Black)
 	}
-	if !board.CheckWin(2, 0, player, 3) {
+	if !board.CheckWin(2, 0, domain.Black, 3) {
 		t.Errorf("expected anti-diagonal win")
 	}
 }
@@ -158,12 +138,325 @@ func TestBoard_CheckWin_NoWin(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	player, _ := domain.NewPlayer("NoWin", "securepassword")
-	player.ID = 1
-
-	_ = board.Put(0, 0, player)
-	_ = board.Put(0, 1, player)
-	if board.CheckWin(0, 1, player, 3) {
+	_ = board.Put(0, 0, domain.Black)
+	_ = board.Put(0, 1, domain.Black)
+	if board.CheckWin(0, 1, domain.Black, 3) {
 		t.Errorf("did not expect win")
 	}
 }
+
+func TestBoard_CheckWin_OtherStone(t *testing.T) {
+	board, err := domain.NewBoard(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for col := 0; col < 3; col++ {
+		_ = board.Put(0, col, domain.White)
+	}
+	if board.CheckWin(0, 2, domain.Black, 3) {
+		t.Errorf("did not expect win for black")
+	}
+	if !board.CheckWin(0, 1, domain.White, 3) {
+		t.Errorf("expected win for white through the middle stone")
+	}
+}
+
+func TestBoard_WinningLine(t *testing.T) {
+	board, _ := domain.NewBoard(7)
+	for i := 1; i < 6; i++ {
+		_ = board.Put(i, 6-i, domain.White)
+	}
+
+	line := board.WinningLine(3, 3, domain.White, 5)
+	expected := []domain.Point{{Row: 1, Col: 5}, {Row: 2, Col: 4}, {Row: 3, Col: 3}, {Row: 4, Col: 2}, {Row: 5, Col: 1}}
+	if len(line) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, line)
+	}
+	for i := range expected {
+		if line[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, line)
+			break
+		}
+	}
+
+	if board.WinningLine(3, 3, domain.Black, 5) != nil {
+		t.Errorf("did not expect a winning line for black")
+	}
+	if board.WinningLine(3, 3, domain.White, 6) != nil {
+		t.Errorf("did not expect a winning line of 6 stones")
+	}
+}
+
+func TestBoard_Put_InvalidStone(t *testing.T) {
+	board, err := domain.NewBoard(3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := board.Put(0, 0, domain.Empty); err == nil {
+		t.Fatal("expected error for empty stone, got nil")
+	}
+}
+
+func TestBoard_AtAndCount(t *testing.T) {
+	board, err := domain.NewBoard(9)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = board.Put(8, 8, domain.Black)
+	_ = board.Put(4, 5, domain.White)
+	_ = board.Put(0, 3, domain.Black)
+
+	if board.At(8, 8) != domain.Black || board.At(4, 5) != domain.White || board.At(1, 1) != domain.Empty {
+		t.Errorf("unexpected stones on the board")
+	}
+	if board.At(9, 9) != domain.Empty {
+		t.Errorf("expected out of bounds cell to be empty")
+	}
+	if board.Count(domain.Black) != 2 || board.Count(domain.White) != 1 {
+		t.Errorf("expected 2 black and 1 white stones, got %d and %d",
+			board.Count(domain.Black), board.Count(domain.White))
+	}
+}
+
+func TestBoard_LinePattern(t *testing.T) {
+	board, err := domain.NewBoard(5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_ = board.Put(2, 0, domain.Black)
+	_ = board.Put(2, 2, domain.Black)
+	_ = board.Put(2, 3, domain.White)
+
+	own, free := board.LinePattern(2, 2, domain.Directions[0], domain.Black, 3)
+	// offsets -3..3 map to columns -1..5
+	if own != 0b0001010 {
+		t.Errorf("unexpected own mask %07b", own)
+	}
+	if free != 0b0100100 {
+		t.Errorf("unexpected free mask %07b", free)
+	}
+}
+
+func TestBoard_Clone(t *testing.T) {
+	board, _ := domain.NewBoard(5)
+	_ = board.Put(1, 1, domain.Black)
+
+	clone := board.Clone()
+	_ = clone.Put(2, 2, domain.White)
+
+	if board.IsOccupied(2, 2) {
+		t.Errorf("expected clone to be independent from the original board")
+	}
+	if !clone.IsOccupied(1, 1, domain.Black) {
+		t.Errorf("expected clone to keep existing stones")
+	}
+}
+
+func TestBoard_MarshalText(t *testing.T) {
+	board, _ := domain.NewBoard(3)
+	_ = board.Put(0, 0, domain.Black)
+	_ = board.Put(0, 1, domain.Black)
+	_ = board.Put(2, 2, domain.White)
+
+	text, err := board.MarshalText()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(text) != "3:2x6.o" {
+		t.Errorf("expected \"3:2x6.o\", got %q", text)
+	}
+
+	var decoded domain.Board
+	if err := decoded.UnmarshalText(text); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Size != 3 || decoded.At(0, 1) != domain.Black || decoded.At(2, 2) != domain.White ||
+		decoded.Count(domain.Black) != 2 || decoded.Count(domain.White) != 1 {
+		t.Errorf("decoded board does not match the original")
+	}
+}
+
+func TestBoard_UnmarshalText_Unencoded(t *testing.T) {
+	var board domain.Board
+	if err := board.UnmarshalText([]byte("3:x...o...x")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if board.At(0, 0) != domain.Black || board.At(1, 1) != domain.White || board.At(2, 2) != domain.Black {
+		t.Errorf("decoded board does not match the input")
+	}
+}
+
+func TestBoard_UnmarshalText_Invalid(t *testing.T) {
+	for _, text := range []string{"", "3", "x:9.", "3:8.", "3:10.", "3:9?", "2:4.", "3:9.5"} {
+		var board domain.Board
+		if err := board.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("expected error for %q, got nil", text)
+		}
+	}
+}
+
+func TestBoard_Remove(t *testing.T) {
+	board, _ := domain.NewBoard(5)
+	empty := board.Hash()
+
+	_ = board.Put(2, 3, domain.White)
+	if err := board.Remove(2, 3); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if board.IsOccupied(2, 3) {
+		t.Errorf("expected position (2,3) to be free")
+	}
+	if board.Hash() != empty {
+		t.Errorf("expected hash to be restored after remove")
+	}
+	if own, _ := board.LinePattern(2, 2, domain.Directions[0], domain.White, 1); own != 0 {
+		t.Errorf("expected no white stones on the line, got %03b", own)
+	}
+}
+
+func TestBoard_Remove_Invalid(t *testing.T) {
+	board, _ := domain.NewBoard(3)
+	if err := board.Remove(1, 1); err == nil {
+		t.Error("expected error for empty position, got nil")
+	}
+	if err := board.Remove(3, 0); err == nil {
+		t.Error("expected error for out of bounds, got nil")
+	}
+}
+
+// legacyBoard mirrors the former [][]int32 layout holding player IDs,
+// it is kept to benchmark the bitboard against.
+type legacyBoard [][]int32
+
+func (g legacyBoard) checkWin(row, col int, id int32, maxLine int) bool {
+	for _, dir := range domain.Directions {
+		count := 1
+		for _, step := range []int{-1, 1} {
+			r, c := row+step*dir.DRow, col+step*dir.DCol
+			for r >= 0 && r < len(g) && c >= 0 && c < len(g) && g[r][c] == id {
+				count++
+				r += step * dir.DRow
+				c += step * dir.DCol
+			}
+		}
+		if count >= maxLine {
+			return true
+		}
+	}
+	return false
+}
+
+// benchmarkMoves is a scattered 15x15 position without five in a row.
+var benchmarkMoves = [][2]int{
+	{7, 7}, {7, 8}, {8, 8}, {6, 6}, {8, 6}, {9, 5}, {6, 8}, {5, 9}, {8, 7}, {8, 9},
+	{9, 7}, {10, 7}, {6, 7}, {5, 7}, {9, 8}, {10, 9}, {7, 6}, {9, 9}, {4, 4}, {11, 10},
+}
+
+func BenchmarkBoard_CheckWin(b *testing.B) {
+	board, _ := domain.NewBoard(15)
+	for i, m := range benchmarkMoves {
+		_ = board.Put(m[0], m[1], domain.Stone(i%2+1))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := benchmarkMoves[i%len(benchmarkMoves)]
+		board.CheckWin(m[0], m[1], board.At(m[0], m[1]), 5)
+	}
+}
+
+func BenchmarkLegacyBoard_CheckWin(b *testing.B) {
+	board := make(legacyBoard, 15)
+	for i := range board {
+		board[i] = make([]int32, 15)
+	}
+	for i, m := range benchmarkMoves {
+		board[m[0]][m[1]] = int32(i%2 + 1)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := benchmarkMoves[i%len(benchmarkMoves)]
+		board.checkWin(m[0], m[1], board[m[0]][m[1]], 5)
+	}
+}
+
+func BenchmarkBoard_MarshalText(b *testing.B) {
+	board, _ := domain.NewBoard(15)
+	for i, m := range benchmarkMoves {
+		_ = board.Put(m[0], m[1], domain.Stone(i%2+1))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = board.MarshalText()
+	}
+}
+
+func BenchmarkLegacyBoard_MarshalJSON(b *testing.B) {
+	board := make(legacyBoard, 15)
+	for i := range board {
+		board[i] = make([]int32, 15)
+	}
+	for i, m := range benchmarkMoves {
+		board[m[0]][m[1]] = int32(i%2 + 1)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = json.Marshal(struct {
+			Size int       `json:"size"`
+			Data [][]int32 `json:"data"`
+		}{15, board})
+	}
+}
+
+func (g legacyBoard) linePattern(row, col int, dir domain.Direction, id int32, radius int) (own, free uint32) {
+	for i := 0; i <= 2*radius; i++ {
+		r, c := row+(i-radius)*dir.DRow, col+(i-radius)*dir.DCol
+		if r < 0 || r >= len(g) || c < 0 || c >= len(g) {
+			continue
+		}
+		switch g[r][c] {
+		case id:
+			own |= 1 << i
+		case 0:
+			free |= 1 << i
+		}
+	}
+	return own, free
+}
+
+func BenchmarkBoard_LinePattern(b *testing.B) {
+	board, _ := domain.NewBoard(15)
+	for i, m := range benchmarkMoves {
+		_ = board.Put(m[0], m[1], domain.Stone(i%2+1))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := benchmarkMoves[i%len(benchmarkMoves)]
+		for _, dir := range domain.Directions {
+			board.LinePattern(m[0], m[1], dir, domain.Black, 5)
+		}
+	}
+}
+
+func BenchmarkLegacyBoard_LinePattern(b *testing.B) {
+	board := make(legacyBoard, 15)
+	for i := range board {
+		board[i] = make([]int32, 15)
+	}
+	for i, m := range benchmarkMoves {
+		board[m[0]][m[1]] = int32(i%2 + 1)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := benchmarkMoves[i%len(benchmarkMoves)]
+		for _, dir := range domain.Directions {
+			board.linePattern(m[0], m[1], dir, 1, 5)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Package domaintest provides helpers for the tests of the packages
+// working with the domain.
+package domaintest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// NewBoard returns a board of the size with the black and the white
+// stones, the test fails if a stone can't be put.
+func NewBoard(t testing.TB, size int, black, white []domain.Point) *domain.Board {
+	t.Helper()
+
+	board, err := domain.NewBoard(size)
+	require.NoError(t, err)
+
+	for _, p := range black {
+		require.NoError(t, board.Put(p.Row, p.Col, domain.Black))
+	}
+	for _, p := range white {
+		require.NoError(t, board.Put(p.Row, p.Col, domain.White))
+	}
+	return board
+}
+
+// Row returns the cells of the row in the columns.
+func Row(r int, cols ...int) []domain.Point {
+	points := make([]domain.Point, len(cols))
+	for i, c := range cols {
+		points[i] = domain.Point{Row: r, Col: c}
+	}
+	return points
+}
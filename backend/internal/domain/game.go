@@ -2,24 +2,41 @@ package domain
 
 import (
 	"errors"
+	"slices"
 	"time"
 )
 
 type GameType string
 
+// WinLength is the number of stones in a row winning the game.
+const WinLength = 5
+
 const (
 	PvP GameType = "pvp"
 	PvA GameType = "pva"
+
+	// Analysis is an offline board where the owner plays both sides
+	Analysis GameType = "analysis"
 )
 
 var (
 	ErrInvalidGameType    = errors.New("invalid game type")
 	ErrFullGame           = errors.New("game is full")
 	ErrCantJoinToSameGame = errors.New("can't join to the same game")
+	ErrCantJoinOwnBot     = errors.New("can't play against your own bot")
 	ErrInvalidBoard       = errors.New("invalid board")
 	ErrNotYourTurn        = errors.New("it's not your turn")
 	ErrGameNotReady       = errors.New("game is not ready")
 	ErrGameNotFound       = errors.New("game is not found")
+	ErrGameFinished       = errors.New("game is finished")
+	ErrNotParticipant     = errors.New("player does not participate in the game")
+	ErrUndoInRatedGame    = errors.New("undo is allowed only in unrated games")
+	ErrNothingToUndo      = errors.New("there is no move to undo")
+	ErrUndoPending        = errors.New("undo is already requested")
+	ErrUndoNotRequested   = errors.New("undo is not requested")
+	ErrOwnUndoRequest     = errors.New("can't respond to your own undo request")
+	ErrGuestRatedGame     = errors.New("guests play only unrated games")
+	ErrGameAnnulled       = errors.New("game is annulled")
 )
 
 type Game struct {
@@ -27,16 +44,30 @@ type Game struct {
 
 	Type  GameType
 	Board *Board
+	Rated bool
+
+	// Moves lists played cells in order, black moves first
+	Moves []Point
 
 	CurrentPlayer *Player
 	WinnerPlayer  *Player
 	Players       [2]*Player
 
+	// WinningLine holds the cells of the winner's line
+	WinningLine []Point
+
+	// UndoRequestedBy is the player waiting for a takeback approval
+	UndoRequestedBy *Player
+
+	// AnnulledAt is set when a moderator annulled the game, it ends the
+	// game and its result doesn't count
+	AnnulledAt *time.Time
+
 	LastActivity time.Time
 }
 
 func NewGame(gtype GameType, board *Board, firstPlayer *Player) (*Game, error) {
-	if gtype != PvP && gtype != PvA {
+	if gtype != PvP && gtype != PvA && gtype != Analysis {
 		return nil, ErrInvalidGameType
 	}
 
@@ -47,15 +78,48 @@ func NewGame(gtype GameType, board *Board, firstPlayer *Player) (*Game, error) {
 	game := &Game{
 		Type:          gtype,
 		Board:         board,
+		Rated:         true,
 		CurrentPlayer: firstPlayer,
 		WinnerPlayer:  nil,
 		Players:       [2]*Player{firstPlayer, nil},
 		LastActivity:  time.Now(),
 	}
 
+	if gtype == Analysis {
+		game.Players[1] = firstPlayer
+	}
+
+	// Only the games between two players are rated, the AI doesn't score
+	if gtype != PvP {
+		game.Rated = false
+	}
+
+	if firstPlayer != nil && firstPlayer.Guest {
+		game.Rated = false
+	}
+
 	return game, nil
 }
 
+// SetRated makes the game rated or not. Analysis boards and games against
+// the AI are never rated, the games of guests neither.
+func (g *Game) SetRated(rated bool) error {
+	if g.Type != PvP {
+		return nil
+	}
+
+	if rated {
+		for _, p := range g.Players {
+			if p != nil && p.Guest {
+				return ErrGuestRatedGame
+			}
+		}
+	}
+
+	g.Rated = rated
+	return nil
+}
+
 func (g *Game) Join(player *Player) error {
 	if g.Players[1] != nil {
 		return ErrFullGame
@@ -65,6 +129,15 @@ func (g *Game) Join(player *Player) error {
 		return ErrCantJoinToSameGame
 	}
 
+	// A bot losing on purpose would hand its owner the points
+	if player.IsBotOf(g.Players[0].ID) || g.Players[0].IsBotOf(player.ID) {
+		return ErrCantJoinOwnBot
+	}
+
+	if g.Rated && player.Guest {
+		return ErrGuestRatedGame
+	}
+
 	g.Players[1] = player
 	g.LastActivity = time.Now()
 	return nil
@@ -79,16 +152,26 @@ func (g *Game) Move(row, col int, player *Player) error {
 		return ErrGameNotReady
 	}
 
+	if g.IsFinished() {
+		return ErrGameFinished
+	}
+
 	if g.CurrentPlayer == nil || !g.CurrentPlayer.Equal(player) {
 		return ErrNotYourTurn
 	}
 
-	if err := g.Board.Put(row, col, player); err != nil {
+	stone := g.Turn()
+	if err := g.Board.Put(row, col, stone); err != nil {
 		return err
 	}
 
-	if g.Board.CheckWin(row, col, player, 3) { // Assuming 3 in a row to win
+	g.Moves = append(g.Moves, Point{Row: row, Col: col})
+	// Playing on declines a pending takeback request
+	g.UndoRequestedBy = nil
+
+	if line := g.Board.WinningLine(row, col, stone, WinLength); line != nil {
 		g.WinnerPlayer = player
+		g.WinningLine = line
 	} else {
 		if g.Players[0].Equal(player) {
 			// Switch to the second player
@@ -103,6 +186,172 @@ func (g *Game) Move(row, col int, player *Player) error {
 	return nil
 }
 
+// Turn returns the side to move, black moves first.
+func (g *Game) Turn() Stone {
+	if g.Board.Count(Black) > g.Board.Count(White) {
+		return White
+	}
+	return Black
+}
+
+// PlayerOf returns the player playing the given side, the first player plays black.
+func (g *Game) PlayerOf(stone Stone) *Player {
+	switch stone {
+	case Black:
+		return g.Players[0]
+	case White:
+		return g.Players[1]
+	}
+	return nil
+}
+
+// HasBot reports whether a bot plays in the game.
+func (g *Game) HasBot() bool {
+	return slices.ContainsFunc(g.Players[:], func(p *Player) bool {
+		return p != nil && p.Bot
+	})
+}
+
+// StoneOf returns the side played by the player, Empty for outsiders.
+func (g *Game) StoneOf(player *Player) Stone {
+	switch {
+	case g.Players[0].Equal(player):
+		return Black
+	case g.Players[1].Equal(player):
+		return White
+	}
+	return Empty
+}
+
+// RequestUndo asks the opponent to take back the last move of the player,
+// along with the opponent's reply if there is one.
+func (g *Game) RequestUndo(player *Player) error {
+	if g.Rated {
+		return ErrUndoInRatedGame
+	}
+
+	if !g.IsReady() {
+		return ErrGameNotReady
+	}
+
+	if g.IsFinished() {
+		return ErrGameFinished
+	}
+
+	if g.StoneOf(player) == Empty {
+		return ErrNotParticipant
+	}
+
+	if g.UndoRequestedBy != nil {
+		return ErrUndoPending
+	}
+
+	if g.undoPlies(player) == 0 {
+		return ErrNothingToUndo
+	}
+
+	g.UndoRequestedBy = player
+	g.LastActivity = time.Now()
+	return nil
+}
+
+// RespondUndo accepts or declines the pending takeback request. On accept
+// the moves are removed from the board and the requester moves again.
+func (g *Game) RespondUndo(player *Player, accept bool) error {
+	if g.UndoRequestedBy == nil {
+		return ErrUndoNotRequested
+	}
+
+	if g.StoneOf(player) == Empty {
+		return ErrNotParticipant
+	}
+
+	// Both sides of an analysis board belong to the same player
+	if g.UndoRequestedBy.Equal(player) && !g.Players[0].Equal(g.Players[1]) {
+		return ErrOwnUndoRequest
+	}
+
+	requester := g.UndoRequestedBy
+	g.UndoRequestedBy = nil
+	g.LastActivity = time.Now()
+
+	if !accept {
+		return nil
+	}
+
+	for plies := g.undoPlies(requester); plies > 0; plies-- {
+		last := g.Moves[len(g.Moves)-1]
+		if err := g.Board.Remove(last.Row, last.Col); err != nil {
+			return err
+		}
+		g.Moves = g.Moves[:len(g.Moves)-1]
+	}
+
+	g.CurrentPlayer = g.PlayerOf(g.Turn())
+	return nil
+}
+
+// undoPlies returns how many moves have to be taken back for the player
+// to replay its last move, zero if it has not moved yet.
+func (g *Game) undoPlies(player *Player) int {
+	stone := g.StoneOf(player)
+	if g.Players[0].Equal(g.Players[1]) {
+		// Analysis board, take back the very last move
+		return min(len(g.Moves), 1)
+	}
+
+	for i := len(g.Moves) - 1; i >= 0 && i >= len(g.Moves)-2; i-- {
+		if m := g.Moves[i]; g.Board.At(m.Row, m.Col) == stone {
+			return len(g.Moves) - i
+		}
+	}
+	return 0
+}
+
+// IsFinished reports whether the game is over, won or annulled.
+func (g *Game) IsFinished() bool {
+	return g.WinnerPlayer != nil || g.IsAnnulled()
+}
+
+func (g *Game) IsAnnulled() bool {
+	return g.AnnulledAt != nil
+}
+
+// Finish ends the game with the winner without playing it out, for the
+// moderators settling an abandoned or disputed game.
+func (g *Game) Finish(winner *Player) error {
+	if !g.IsReady() {
+		return ErrGameNotReady
+	}
+
+	if g.IsFinished() {
+		return ErrGameFinished
+	}
+
+	if g.StoneOf(winner) == Empty {
+		return ErrNotParticipant
+	}
+
+	g.WinnerPlayer = winner
+	g.CurrentPlayer = winner
+	g.UndoRequestedBy = nil
+	g.LastActivity = time.Now()
+	return nil
+}
+
+// Annul ends the game without a result, a finished game keeps its winner
+// for the record but the result doesn't count anymore.
+func (g *Game) Annul(now time.Time) error {
+	if g.IsAnnulled() {
+		return ErrGameAnnulled
+	}
+
+	g.AnnulledAt = &now
+	g.UndoRequestedBy = nil
+	g.LastActivity = now
+	return nil
+}
+
 func (g *Game) HasWinner() (bool, *Player) {
 	if g.WinnerPlayer != nil {
 		return true, g.WinnerPlayer
@@ -53,6 +53,55 @@ func TestNewGame_SuccessPvA(t *testing.T) {
 	if game.Type != PvA {
 		t.Errorf("expected type %v, got %v", PvA, game.Type)
 	}
+	if game.Rated {
+		t.Errorf("expected an unrated game against the AI")
+	}
+
+	if err := game.SetRated(true); err != nil || game.Rated {
+		t.Errorf("expected the game to stay unrated, got %v", err)
+	}
+}
+
+func TestNewGame_SuccessAnalysis(t *testing.T) {
+	board, _ := NewBoard(9)
+	player := &Player{Entity: Entity{ID: 1}}
+	game, err := NewGame(Analysis, board, player)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if game.Players[1] != player || game.Rated {
+		t.Errorf("expected an unrated game owned by the player on both sides")
+	}
+
+	if err := game.Move(0, 0, player); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := game.Move(1, 1, player); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if game.Board.At(1, 1) != White {
+		t.Errorf("expected the second move to be white")
+	}
+}
+
+func TestGame_Undo_AnalysisBoard(t *testing.T) {
+	board, _ := NewBoard(9)
+	player := &Player{Entity: Entity{ID: 1}}
+	game, _ := NewGame(Analysis, board, player)
+	game.Move(0, 0, player)
+	game.Move(1, 1, player)
+
+	if err := game.RequestUndo(player); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The owner plays both sides and answers its own request
+	if err := game.RespondUndo(player, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(game.Moves) != 1 || game.Board.At(1, 1) != Empty || game.Turn() != White {
+		t.Errorf("expected only the last move taken back, got %v", game.Moves)
+	}
 }
 
 func TestNewGame_InvalidGameType(t *testing.T) {
@@ -112,6 +161,26 @@ func TestGame_Join_FullGame(t *testing.T) {
 	}
 }
 
+func TestGame_Join_OwnBot(t *testing.T) {
+	owner := &Player{Entity: Entity{ID: 1}}
+	bot := &Player{Entity: Entity{ID: 2}, Bot: true, OwnerID: 1}
+	other := &Player{Entity: Entity{ID: 3}}
+
+	for _, pair := range [][2]*Player{{owner, bot}, {bot, owner}} {
+		board, _ := NewBoard(15)
+		game, _ := NewGame(PvP, board, pair[0])
+		if err := game.Join(pair[1]); err != ErrCantJoinOwnBot {
+			t.Errorf("expected ErrCantJoinOwnBot, got %v", err)
+		}
+	}
+
+	board, _ := NewBoard(15)
+	game, _ := NewGame(PvP, board, other)
+	if err := game.Join(bot); err != nil {
+		t.Errorf("expected the bot to join another player, got %v", err)
+	}
+}
+
 func TestGame_Join_SamePlayer(t *testing.T) {
 	board := &mockBoard{}
 	player1 := &mockPlayer{Player: Player{Entity: Entity{ID: 1}}}
@@ -126,6 +195,39 @@ func TestGame_Join_SamePlayer(t *testing.T) {
 	}
 }
 
+func TestNewGame_GuestUnrated(t *testing.T) {
+	board, _ := NewBoard(15)
+	guest := &Player{Entity: Entity{ID: 1}, Guest: true}
+
+	game, err := NewGame(PvP, board, guest)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if game.Rated {
+		t.Errorf("expected the game of a guest to be unrated")
+	}
+	if err := game.SetRated(true); err != ErrGuestRatedGame {
+		t.Errorf("expected ErrGuestRatedGame, got %v", err)
+	}
+}
+
+func TestGame_Join_GuestRated(t *testing.T) {
+	board, _ := NewBoard(15)
+	game, _ := NewGame(PvP, board, &Player{Entity: Entity{ID: 1}})
+	guest := &Player{Entity: Entity{ID: 2}, Guest: true}
+
+	if err := game.Join(guest); err != ErrGuestRatedGame {
+		t.Errorf("expected ErrGuestRatedGame, got %v", err)
+	}
+
+	if err := game.SetRated(false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := game.Join(guest); err != nil {
+		t.Errorf("expected a guest to join an unrated game, got %v", err)
+	}
+}
+
 func TestGame_IsReady_FalseWhenSecondPlayerNil(t *testing.T) {
 	board := &mockBoard{}
 	player1 := &mockPlayer{Player: Player{Entity: Entity{ID: 1}}}
@@ -154,3 +256,263 @@ func TestGame_IsReady_TrueWhenSecondPlayerSet(t *testing.T) {
 		t.Errorf("expected IsReady to be true when second player is set")
 	}
 }
+
+func TestGame_Move_AlternatesStones(t *testing.T) {
+	board, _ := NewBoard(5)
+	player1 := &mockPlayer{Player: Player{Entity: Entity{ID: 1}}}
+	player2 := &mockPlayer{Player: Player{Entity: Entity{ID: 2}}}
+	game, _ := NewGame(PvP, board, &player1.Player)
+	_ = game.Join(&player2.Player)
+
+	if game.Turn() != Black {
+		t.Fatalf("expected black to move first, got %v", game.Turn())
+	}
+	if err := game.Move(0, 0, &player1.Player); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if game.Turn() != White {
+		t.Fatalf("expected white to move second, got %v", game.Turn())
+	}
+	if err := game.Move(1, 1, &player2.Player); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if board.At(0, 0) != Black || board.At(1, 1) != White {
+		t.Errorf("expected stones to follow the move order")
+	}
+	if game.PlayerOf(board.At(1, 1)) != &player2.Player {
+		t.Errorf("expected white stone to belong to the second player")
+	}
+	if game.PlayerOf(Empty) != nil {
+		t.Errorf("expected no player for an empty cell")
+	}
+}
+
+func newUnratedGame(t *testing.T) (*Game, *Player, *Player) {
+	t.Helper()
+
+	board, _ := NewBoard(5)
+	player1 := &Player{Entity: Entity{ID: 1}}
+	player2 := &Player{Entity: Entity{ID: 2}}
+	game, _ := NewGame(PvP, board, player1)
+	game.Rated = false
+	_ = game.Join(player2)
+
+	return game, player1, player2
+}
+
+func TestGame_Move_RecordsMoves(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+	_ = game.Move(0, 0, player1)
+	_ = game.Move(4, 4, player2)
+
+	if len(game.Moves) != 2 || game.Moves[0] != (Point{0, 0}) || game.Moves[1] != (Point{4, 4}) {
+		t.Errorf("unexpected moves %v", game.Moves)
+	}
+}
+
+func TestGame_Undo_OwnLastMove(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+	_ = game.Move(0, 0, player1)
+	_ = game.Move(4, 4, player2)
+
+	if err := game.RequestUndo(player2); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if game.UndoRequestedBy != player2 {
+		t.Errorf("expected pending request from the second player")
+	}
+	if err := game.RespondUndo(player1, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if game.Board.IsOccupied(4, 4) || !game.Board.IsOccupied(0, 0) {
+		t.Errorf("expected only the last move to be taken back")
+	}
+	if len(game.Moves) != 1 {
+		t.Errorf("expected 1 move left, got %d", len(game.Moves))
+	}
+	if game.CurrentPlayer != player2 {
+		t.Errorf("expected the requester to move again")
+	}
+	if game.UndoRequestedBy != nil {
+		t.Errorf("expected request to be cleared")
+	}
+}
+
+func TestGame_Undo_AfterOpponentReply(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+	_ = game.Move(0, 0, player1)
+	_ = game.Move(4, 4, player2)
+
+	if err := game.RequestUndo(player1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := game.RespondUndo(player2, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if game.Board.Count(Black) != 0 || game.Board.Count(White) != 0 || len(game.Moves) != 0 {
+		t.Errorf("expected both moves to be taken back")
+	}
+	if game.CurrentPlayer != player1 || game.Turn() != Black {
+		t.Errorf("expected the first player to move again")
+	}
+}
+
+func TestGame_Undo_Declined(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+	_ = game.Move(0, 0, player1)
+
+	_ = game.RequestUndo(player1)
+	if err := game.RespondUndo(player2, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !game.Board.IsOccupied(0, 0) || game.CurrentPlayer != player2 {
+		t.Errorf("expected the position to stay the same")
+	}
+	if game.UndoRequestedBy != nil {
+		t.Errorf("expected request to be cleared")
+	}
+}
+
+func TestGame_Undo_Errors(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+	outsider := &Player{Entity: Entity{ID: 3}}
+
+	if err := game.RequestUndo(player1); err != ErrNothingToUndo {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+	if err := game.RespondUndo(player2, true); err != ErrUndoNotRequested {
+		t.Errorf("expected ErrUndoNotRequested, got %v", err)
+	}
+
+	_ = game.Move(0, 0, player1)
+	if err := game.RequestUndo(outsider); err != ErrNotParticipant {
+		t.Errorf("expected ErrNotParticipant, got %v", err)
+	}
+	if err := game.RequestUndo(player2); err != ErrNothingToUndo {
+		t.Errorf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	_ = game.RequestUndo(player1)
+	if err := game.RequestUndo(player1); err != ErrUndoPending {
+		t.Errorf("expected ErrUndoPending, got %v", err)
+	}
+	if err := game.RespondUndo(player1, true); err != ErrOwnUndoRequest {
+		t.Errorf("expected ErrOwnUndoRequest, got %v", err)
+	}
+
+	// Playing on declines the request
+	_ = game.Move(1, 1, player2)
+	if game.UndoRequestedBy != nil {
+		t.Errorf("expected move to decline the pending request")
+	}
+}
+
+func TestGame_Undo_RatedGame(t *testing.T) {
+	game, player1, _ := newUnratedGame(t)
+	game.Rated = true
+	_ = game.Move(0, 0, player1)
+
+	if err := game.RequestUndo(player1); err != ErrUndoInRatedGame {
+		t.Errorf("expected ErrUndoInRatedGame, got %v", err)
+	}
+}
+
+func TestGame_Move_Win(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+	board, _ := NewBoard(9)
+	game.Board = board
+
+	for i := 0; i < WinLength-1; i++ {
+		_ = game.Move(0, i, player1)
+		_ = game.Move(1, i, player2)
+	}
+	if ok, _ := game.HasWinner(); ok {
+		t.Fatalf("did not expect a winner before %d in a row", WinLength)
+	}
+
+	if err := game.Move(0, WinLength-1, player1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, winner := game.HasWinner(); !ok || winner != player1 {
+		t.Fatalf("expected the first player to win")
+	}
+	if len(game.WinningLine) != WinLength || game.WinningLine[0] != (Point{0, 0}) {
+		t.Errorf("unexpected winning line %v", game.WinningLine)
+	}
+}
+
+func TestGame_Move_AfterWin(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+
+	for i := 0; i < WinLength-1; i++ {
+		_ = game.Move(0, i, player1)
+		_ = game.Move(1, i, player2)
+	}
+	_ = game.Move(0, WinLength-1, player1)
+
+	if err := game.Move(2, 0, player2); err != ErrGameFinished {
+		t.Errorf("expected %v, got %v", ErrGameFinished, err)
+	}
+}
+
+func TestGame_HasBot(t *testing.T) {
+	board, _ := NewBoard(15)
+	game, _ := NewGame(PvP, board, &Player{Entity: Entity{ID: 1}})
+
+	if game.HasBot() {
+		t.Error("expected no bot in the game")
+	}
+
+	if err := game.Join(&Player{Entity: Entity{ID: 2}, Bot: true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !game.HasBot() {
+		t.Error("expected the bot to be found")
+	}
+}
+
+func TestGame_Finish(t *testing.T) {
+	game, player1, player2 := newUnratedGame(t)
+	_ = game.Move(0, 0, player1)
+
+	if err := game.Finish(&Player{Entity: Entity{ID: 99}}); err != ErrNotParticipant {
+		t.Errorf("expected %v, got %v", ErrNotParticipant, err)
+	}
+
+	if err := game.Finish(player2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, winner := game.HasWinner(); !ok || winner != player2 {
+		t.Fatal("expected the second player to win")
+	}
+	if err := game.Move(1, 1, player2); err != ErrGameFinished {
+		t.Errorf("expected %v, got %v", ErrGameFinished, err)
+	}
+	if err := game.Finish(player1); err != ErrGameFinished {
+		t.Errorf("expected %v, got %v", ErrGameFinished, err)
+	}
+}
+
+func TestGame_Annul(t *testing.T) {
+	game, player1, _ := newUnratedGame(t)
+	_ = game.Move(0, 0, player1)
+
+	if err := game.Annul(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !game.IsAnnulled() || !game.IsFinished() {
+		t.Fatal("expected the game to be annulled")
+	}
+	if ok, _ := game.HasWinner(); ok {
+		t.Error("did not expect a winner")
+	}
+	if err := game.Annul(time.Now()); err != ErrGameAnnulled {
+		t.Errorf("expected %v, got %v", ErrGameAnnulled, err)
+	}
+	if err := game.Finish(player1); err != ErrGameFinished {
+		t.Errorf("expected %v, got %v", ErrGameFinished, err)
+	}
+}
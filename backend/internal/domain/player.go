@@ -3,6 +3,8 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type Player struct {
@@ -11,34 +13,247 @@ type Player struct {
 	Nickname string
 	Password string
 	Score    int
+	// TokenGeneration is bumped by logging out of all sessions
+	TokenGeneration int
+	// DeletedAt is set when the player deleted the account, the player is
+	// kept anonymized for the games played
+	DeletedAt *time.Time
+	// Guest players have a generated nickname and no password, they play
+	// only unrated games until they upgrade to a full account
+	Guest bool
+	// Bot players are run by a program of their owner, they have no
+	// password and authenticate with API keys
+	Bot     bool
+	OwnerID int32
+	Role    Role
+	// BannedAt is set while a moderator bans the player, a banned player
+	// can't log in
+	BannedAt  *time.Time
+	BanReason string
 }
 
 var (
 	ErrPlayerNotFound      = errors.New("player not found")
 	ErrPlayerAlreadyExists = errors.New("player with same nickname already exists")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrNicknameReserved    = errors.New("nickname is reserved")
+	ErrGuestAccount        = errors.New("guests must upgrade to a full account first")
+	ErrNotGuest            = errors.New("player is not a guest")
+	ErrBotAccount          = errors.New("bots authenticate with API keys")
+	ErrPlayerBanned        = errors.New("player is banned")
+)
+
+// The generated nicknames start with a prefix valid nicknames can't start
+// with, so they never clash with a registered one.
+const (
+	DeletedNicknamePrefix = "~deleted-"
+	guestNicknamePrefix   = "~guest-"
 )
 
 func NewPlayer(nickname string, password string) (*Player, error) {
-	if len(nickname) < 3 {
-		return nil, fmt.Errorf("nickname must be at least 3 characters long")
-	}
-	if len(nickname) > 20 {
-		return nil, fmt.Errorf("nickname must be at most 20 characters long")
+	if err := validateNickname(nickname); err != nil {
+		return nil, err
 	}
-
-	if len(password) < 6 || len(password) > 20 {
-		return nil, fmt.Errorf("password must be between 6 and 20 characters long")
+	if err := validatePassword(password); err != nil {
+		return nil, err
 	}
 
 	p := &Player{
 		Nickname: nickname,
 		Password: password,
 		Score:    0,
+		Role:     RolePlayer,
 	}
 
 	return p, nil
 }
 
+// NewGuest returns a guest player, the tag tells the guests apart in the
+// generated nickname.
+func NewGuest(tag string) *Player {
+	return &Player{
+		Nickname: guestNicknamePrefix + tag,
+		Guest:    true,
+		Role:     RolePlayer,
+	}
+}
+
+// NewBot returns a bot of the owner, bots are owned by full accounts.
+func NewBot(nickname string, owner *Player) (*Player, error) {
+	if owner.Guest {
+		return nil, ErrGuestAccount
+	}
+	if owner.Bot || owner.IsDeleted() {
+		return nil, ErrPlayerNotFound
+	}
+	if err := validateNickname(nickname); err != nil {
+		return nil, err
+	}
+
+	return &Player{
+		Nickname: nickname,
+		Bot:      true,
+		OwnerID:  owner.ID,
+		Role:     RolePlayer,
+	}, nil
+}
+
+// IsBotOf reports whether the player is a bot of the owner.
+func (p *Player) IsBotOf(ownerID int32) bool {
+	return p.Bot && !p.IsDeleted() && p.OwnerID == ownerID
+}
+
+// Upgrade turns the guest into a full account with the nickname and the
+// password, keeping its games.
+func (p *Player) Upgrade(nickname, password string) error {
+	if !p.Guest || p.IsDeleted() {
+		return ErrNotGuest
+	}
+	if err := validateNickname(nickname); err != nil {
+		return err
+	}
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	p.Nickname = nickname
+	p.Password = password
+	p.Guest = false
+	return nil
+}
+
+func validateNickname(nickname string) error {
+	if len(nickname) < 3 {
+		return fmt.Errorf("nickname must be at least 3 characters long")
+	}
+	if len(nickname) > 20 {
+		return fmt.Errorf("nickname must be at most 20 characters long")
+	}
+	if strings.HasPrefix(nickname, "~") {
+		return fmt.Errorf("nickname must not start with ~")
+	}
+	return nil
+}
+
+func validatePassword(password string) error {
+	if len(password) < 6 || len(password) > 20 {
+		return fmt.Errorf("password must be between 6 and 20 characters long")
+	}
+	return nil
+}
+
+// CheckPassword returns ErrInvalidCredentials unless the password is the
+// one of the player. Deleted players, guests and bots can't log in, banned
+// players get ErrPlayerBanned once the password is right.
+func (p *Player) CheckPassword(password string) error {
+	if p.IsDeleted() || p.Guest || p.Bot || p.Password != password {
+		return ErrInvalidCredentials
+	}
+	if p.IsBanned() {
+		return ErrPlayerBanned
+	}
+	return nil
+}
+
+// ChangePassword replaces the password, the current one proves the player
+// is the owner of the account.
+func (p *Player) ChangePassword(current, password string) error {
+	if p.Guest {
+		return ErrGuestAccount
+	}
+	if p.Bot {
+		return ErrBotAccount
+	}
+	if err := p.CheckPassword(current); err != nil {
+		return err
+	}
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	p.Password = password
+	return nil
+}
+
+// Rename changes the nickname, the games refer to the player by the ID and
+// keep their players.
+func (p *Player) Rename(nickname string) error {
+	if p.IsDeleted() {
+		return ErrPlayerNotFound
+	}
+	if p.Guest {
+		return ErrGuestAccount
+	}
+	if err := validateNickname(nickname); err != nil {
+		return err
+	}
+
+	p.Nickname = nickname
+	return nil
+}
+
+// Delete anonymizes the player: the nickname is freed and nobody can log
+// in anymore, the games keep the player as their participant.
+func (p *Player) Delete(now time.Time) {
+	p.Nickname = fmt.Sprintf("%s%d", DeletedNicknamePrefix, p.ID)
+	p.Password = ""
+	p.DeletedAt = &now
+}
+
+func (p *Player) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// CanManage reports whether the player may ban the target or change its
+// role or score: moderators manage the players below them.
+func (p *Player) CanManage(target *Player) bool {
+	return p.Role.AtLeast(RoleModerator) && p.Role.Outranks(target.Role) && !target.IsDeleted()
+}
+
+// SetRole grants the role, bots and guests keep the player role.
+func (p *Player) SetRole(role Role) error {
+	if p.IsDeleted() {
+		return ErrPlayerNotFound
+	}
+	if role != RolePlayer && (p.Guest || p.Bot) {
+		return ErrNotPermitted
+	}
+
+	p.Role = role
+	return nil
+}
+
+// Ban denies the player from now on, banning a banned player replaces the
+// reason and keeps the first ban.
+func (p *Player) Ban(reason string, now time.Time) error {
+	if p.IsDeleted() {
+		return ErrPlayerNotFound
+	}
+	if len(reason) > 200 {
+		return fmt.Errorf("ban reason must be at most 200 characters long")
+	}
+
+	if p.BannedAt == nil {
+		p.BannedAt = &now
+	}
+	p.BanReason = reason
+	return nil
+}
+
+func (p *Player) Unban() {
+	p.BannedAt = nil
+	p.BanReason = ""
+}
+
+func (p *Player) IsBanned() bool {
+	return p.BannedAt != nil
+}
+
+// AdjustScore corrects the score by the delta.
+func (p *Player) AdjustScore(delta int) {
+	p.Score += delta
+}
+
 func (p *Player) AddScore() {
 	p.Score += 1
 }
@@ -2,6 +2,7 @@ package domain_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/moLIart/gomoku-backend/internal/domain"
 )
@@ -99,3 +100,197 @@ func TestPlayer_Equal_BothNil(t *testing.T) {
 		t.Errorf("expected nil players to not be equal")
 	}
 }
+
+func TestNewPlayer_ReservedNickname(t *testing.T) {
+	_, err := domain.NewPlayer("~deleted-1", "securepassword")
+	if err == nil {
+		t.Fatal("expected error for a nickname starting with ~, got nil")
+	}
+}
+
+func TestPlayer_ChangePassword(t *testing.T) {
+	player, _ := domain.NewPlayer("Bob", "securepassword")
+
+	if err := player.ChangePassword("wrongpassword", "newpassword"); err != domain.ErrInvalidCredentials {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if err := player.ChangePassword("securepassword", "short"); err == nil {
+		t.Error("expected error for short password, got nil")
+	}
+	if err := player.ChangePassword("securepassword", "newpassword"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := player.CheckPassword("newpassword"); err != nil {
+		t.Errorf("expected the new password to be valid, got %v", err)
+	}
+}
+
+func TestPlayer_Rename(t *testing.T) {
+	player, _ := domain.NewPlayer("Bob", "securepassword")
+
+	if err := player.Rename("Al"); err == nil {
+		t.Error("expected error for short nickname, got nil")
+	}
+	if err := player.Rename("Robert"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if player.Nickname != "Robert" {
+		t.Errorf("expected nickname 'Robert', got %s", player.Nickname)
+	}
+}
+
+func TestPlayer_Delete(t *testing.T) {
+	player, _ := domain.NewPlayer("Bob", "securepassword")
+	player.ID = 42
+
+	player.Delete(time.Now())
+
+	if !player.IsDeleted() {
+		t.Fatal("expected the player to be deleted")
+	}
+	if player.Nickname != "~deleted-42" {
+		t.Errorf("expected nickname '~deleted-42', got %s", player.Nickname)
+	}
+	if err := player.CheckPassword(""); err != domain.ErrInvalidCredentials {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if err := player.Rename("Robert"); err == nil {
+		t.Error("expected a deleted player not to be renamed")
+	}
+}
+
+func TestPlayer_Upgrade(t *testing.T) {
+	guest := domain.NewGuest("1a2b3c4d")
+	if !guest.Guest || guest.Nickname != "~guest-1a2b3c4d" {
+		t.Fatalf("unexpected guest %+v", guest)
+	}
+	if err := guest.CheckPassword(""); err != domain.ErrInvalidCredentials {
+		t.Errorf("expected a guest not to log in, got %v", err)
+	}
+	if err := guest.Rename("Robert"); err != domain.ErrGuestAccount {
+		t.Errorf("expected ErrGuestAccount, got %v", err)
+	}
+
+	if err := guest.Upgrade("~guest-1", "securepassword"); err == nil {
+		t.Error("expected error for a reserved nickname, got nil")
+	}
+	if err := guest.Upgrade("Robert", "short"); err == nil {
+		t.Error("expected error for short password, got nil")
+	}
+	if err := guest.Upgrade("Robert", "securepassword"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if guest.Guest || guest.Nickname != "Robert" {
+		t.Errorf("expected a full account, got %+v", guest)
+	}
+	if err := guest.CheckPassword("securepassword"); err != nil {
+		t.Errorf("expected the password to be valid, got %v", err)
+	}
+	if err := guest.Upgrade("Bob", "securepassword"); err != domain.ErrNotGuest {
+		t.Errorf("expected ErrNotGuest, got %v", err)
+	}
+}
+
+func TestNewBot(t *testing.T) {
+	owner := &domain.Player{Entity: domain.Entity{ID: 7}, Nickname: "Alice"}
+
+	bot, err := domain.NewBot("AliceBot", owner)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !bot.Bot || bot.OwnerID != 7 {
+		t.Errorf("expected a bot of the owner, got %+v", bot)
+	}
+	if !bot.IsBotOf(7) || bot.IsBotOf(8) {
+		t.Error("expected the bot to belong to its owner only")
+	}
+	if err := bot.CheckPassword(""); err != domain.ErrInvalidCredentials {
+		t.Errorf("expected a bot not to log in, got %v", err)
+	}
+
+	if _, err := domain.NewBot("GuestBot", domain.NewGuest("1a2b3c4d")); err != domain.ErrGuestAccount {
+		t.Errorf("expected ErrGuestAccount, got %v", err)
+	}
+	if _, err := domain.NewBot("BotBot", bot); err != domain.ErrPlayerNotFound {
+		t.Errorf("expected a bot not to own bots, got %v", err)
+	}
+	if _, err := domain.NewBot("~bot", owner); err == nil {
+		t.Error("expected error for a reserved nickname, got nil")
+	}
+}
+
+func TestPlayer_Ban(t *testing.T) {
+	player, _ := domain.NewPlayer("Alice", "securepassword")
+
+	if err := player.Ban("cheating", time.Now()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !player.IsBanned() || player.BanReason != "cheating" {
+		t.Fatal("expected the player to be banned for cheating")
+	}
+	if err := player.CheckPassword("securepassword"); err != domain.ErrPlayerBanned {
+		t.Errorf("expected ErrPlayerBanned, got %v", err)
+	}
+	if err := player.CheckPassword("wrongpassword"); err != domain.ErrInvalidCredentials {
+		t.Errorf("expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+
+	player.Unban()
+	if player.IsBanned() || player.BanReason != "" {
+		t.Fatal("expected the ban to be lifted")
+	}
+	if err := player.CheckPassword("securepassword"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestPlayer_CanManage(t *testing.T) {
+	admin := &domain.Player{Entity: domain.Entity{ID: 1}, Role: domain.RoleAdmin}
+	moderator := &domain.Player{Entity: domain.Entity{ID: 2}, Role: domain.RoleModerator}
+	player := &domain.Player{Entity: domain.Entity{ID: 3}, Role: domain.RolePlayer}
+
+	for _, tc := range []struct {
+		name          string
+		actor, target *domain.Player
+		want          bool
+	}{
+		{"admin manages moderators", admin, moderator, true},
+		{"moderator manages players", moderator, player, true},
+		{"moderator can't manage moderators", moderator, moderator, false},
+		{"moderator can't manage admins", moderator, admin, false},
+		{"player manages nobody", player, player, false},
+	} {
+		if got := tc.actor.CanManage(tc.target); got != tc.want {
+			t.Errorf("%s: expected %v", tc.name, tc.want)
+		}
+	}
+}
+
+func TestPlayer_SetRole(t *testing.T) {
+	player, _ := domain.NewPlayer("Alice", "securepassword")
+	if player.Role != domain.RolePlayer {
+		t.Fatalf("expected new players to have the player role, got %q", player.Role)
+	}
+
+	if err := player.SetRole(domain.RoleModerator); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if player.Role != domain.RoleModerator {
+		t.Errorf("expected moderator, got %q", player.Role)
+	}
+
+	guest := domain.NewGuest("1a2b3c4d")
+	if err := guest.SetRole(domain.RoleModerator); err != domain.ErrNotPermitted {
+		t.Errorf("expected ErrNotPermitted for a guest, got %v", err)
+	}
+}
+
+func TestPlayer_AdjustScore(t *testing.T) {
+	player, _ := domain.NewPlayer("Bob", "securepassword")
+	player.AdjustScore(5)
+	player.AdjustScore(-2)
+	if player.Score != 3 {
+		t.Errorf("expected score 3, got %d", player.Score)
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"slices"
+)
+
+// Puzzle is a position where the side to move has a forced win.
+type Puzzle struct {
+	Entity
+
+	Board *Board
+	// Side is the stone to move, it wins
+	Side Stone
+	// Solutions are the winning lines: moves of Side and of the opponent
+	// alternating, the last move of Side completes five
+	Solutions [][]Point
+	// Difficulty is the number of moves of Side in the shortest solution
+	Difficulty int
+	Rating     int
+}
+
+// PuzzleRating is the puzzle strength of a player, it moves with the first
+// attempt at every puzzle.
+type PuzzleRating struct {
+	PlayerID int32
+	Rating   int
+	Attempts int
+	Solved   int
+}
+
+const (
+	DefaultPuzzleRating = 1500
+
+	// puzzleRatingK is the Elo factor of the rating updates
+	puzzleRatingK = 32
+)
+
+var (
+	ErrPuzzleNotFound = errors.New("puzzle not found")
+	ErrInvalidPuzzle  = errors.New("puzzle solution doesn't win")
+)
+
+// NewPuzzle validates the solutions by replaying them and rates the puzzle
+// by its shortest solution.
+func NewPuzzle(board *Board, side Stone, solutions [][]Point) (*Puzzle, error) {
+	if side != Black && side != White || len(solutions) == 0 {
+		return nil, ErrInvalidPuzzle
+	}
+
+	difficulty := math.MaxInt
+	for _, line := range solutions {
+		if !winsWith(board, side, line) {
+			return nil, ErrInvalidPuzzle
+		}
+		difficulty = min(difficulty, (len(line)+1)/2)
+	}
+
+	p := &Puzzle{
+		Board:      board,
+		Side:       side,
+		Solutions:  solutions,
+		Difficulty: difficulty,
+		Rating:     PuzzleRatingOf(difficulty),
+	}
+
+	return p, nil
+}
+
+// PuzzleRatingOf is the starting rating of a puzzle: a five in one move is
+// easy, every further move of the solution makes it harder.
+func PuzzleRatingOf(difficulty int) int {
+	return DefaultPuzzleRating + 150*(difficulty-3)
+}
+
+// winsWith reports whether the line, played by side and the opponent in
+// turn, ends with side completing five and no earlier five.
+func winsWith(board *Board, side Stone, line []Point) bool {
+	if len(line)%2 == 0 {
+		return false
+	}
+
+	b := board.Clone()
+	stone := side
+	for i, p := range line {
+		if err := b.Put(p.Row, p.Col, stone); err != nil {
+			return false
+		}
+		if b.CheckWin(p.Row, p.Col, stone, WinLength) != (i == len(line)-1) {
+			return false
+		}
+		stone = stone.Opponent()
+	}
+	return true
+}
+
+// Check reports whether the moves follow one of the solutions to the end.
+func (p *Puzzle) Check(moves []Point) bool {
+	for _, line := range p.Solutions {
+		if slices.Equal(line, moves) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPuzzleRating returns the rating of a player without attempts.
+func NewPuzzleRating(playerID int32) *PuzzleRating {
+	return &PuzzleRating{PlayerID: playerID, Rating: DefaultPuzzleRating}
+}
+
+// Record counts an attempt at the puzzle: solving it gains rating as a won
+// Elo game against the puzzle rating, failing loses some.
+func (r *PuzzleRating) Record(p *Puzzle, solved bool) {
+	expected := 1 / (1 + math.Pow(10, float64(p.Rating-r.Rating)/400))
+
+	score := 0.0
+	if solved {
+		score = 1
+		r.Solved++
+	}
+
+	r.Attempts++
+	r.Rating += int(math.Round(puzzleRatingK * (score - expected)))
+}
@@ -0,0 +1,98 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// openThreeBoard has an open three of black on row 7 and white stones far
+// away, black wins in two moves.
+func openThreeBoard(t *testing.T) *domain.Board {
+	t.Helper()
+
+	board, err := domain.NewBoard(15)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, col := range []int{5, 6, 7} {
+		_ = board.Put(7, col, domain.Black)
+	}
+	_ = board.Put(0, 0, domain.White)
+	_ = board.Put(0, 14, domain.White)
+	return board
+}
+
+var openThreeSolution = []domain.Point{{Row: 7, Col: 4}, {Row: 7, Col: 3}, {Row: 7, Col: 8}}
+
+func TestNewPuzzle(t *testing.T) {
+	puzzle, err := domain.NewPuzzle(openThreeBoard(t), domain.Black, [][]domain.Point{openThreeSolution})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if puzzle.Difficulty != 2 {
+		t.Errorf("expected difficulty 2, got %d", puzzle.Difficulty)
+	}
+	if puzzle.Rating != domain.PuzzleRatingOf(2) {
+		t.Errorf("expected rating %d, got %d", domain.PuzzleRatingOf(2), puzzle.Rating)
+	}
+}
+
+func TestNewPuzzle_InvalidSolution(t *testing.T) {
+	board := openThreeBoard(t)
+
+	invalid := [][]domain.Point{
+		// Ends with a move of the opponent
+		{{Row: 7, Col: 4}, {Row: 7, Col: 3}},
+		// Doesn't complete five
+		{{Row: 7, Col: 4}, {Row: 7, Col: 3}, {Row: 9, Col: 9}},
+		// Plays on an occupied cell
+		{{Row: 7, Col: 5}},
+		// Goes on after five
+		{{Row: 7, Col: 4}, {Row: 7, Col: 8}, {Row: 7, Col: 3}, {Row: 9, Col: 9}, {Row: 7, Col: 9}},
+	}
+	for _, line := range invalid {
+		if _, err := domain.NewPuzzle(board, domain.Black, [][]domain.Point{line}); err != domain.ErrInvalidPuzzle {
+			t.Errorf("expected ErrInvalidPuzzle for %v, got %v", line, err)
+		}
+	}
+
+	if _, err := domain.NewPuzzle(board, domain.Black, nil); err != domain.ErrInvalidPuzzle {
+		t.Errorf("expected ErrInvalidPuzzle without solutions, got %v", err)
+	}
+}
+
+func TestPuzzle_Check(t *testing.T) {
+	puzzle, err := domain.NewPuzzle(openThreeBoard(t), domain.Black, [][]domain.Point{openThreeSolution})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !puzzle.Check(openThreeSolution) {
+		t.Errorf("expected the solution to be accepted")
+	}
+	if puzzle.Check(openThreeSolution[:1]) {
+		t.Errorf("expected an unfinished line to be rejected")
+	}
+	if puzzle.Check([]domain.Point{{Row: 7, Col: 8}, {Row: 7, Col: 9}, {Row: 7, Col: 4}}) {
+		t.Errorf("expected a line outside of the solutions to be rejected")
+	}
+}
+
+func TestPuzzleRating_Record(t *testing.T) {
+	puzzle := &domain.Puzzle{Rating: domain.DefaultPuzzleRating}
+
+	rating := domain.NewPuzzleRating(1)
+	rating.Record(puzzle, true)
+	if rating.Rating != domain.DefaultPuzzleRating+16 {
+		t.Errorf("expected rating %d after solving an even puzzle, got %d", domain.DefaultPuzzleRating+16, rating.Rating)
+	}
+
+	rating.Record(puzzle, false)
+	if rating.Rating >= domain.DefaultPuzzleRating+16 {
+		t.Errorf("expected the rating to drop after a failure, got %d", rating.Rating)
+	}
+	if rating.Attempts != 2 || rating.Solved != 1 {
+		t.Errorf("expected 2 attempts and 1 solved, got %d and %d", rating.Attempts, rating.Solved)
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// RefreshToken renews the access token of a player. Every refresh rotates
+// it: the token is used up and a new one of the same family replaces it.
+// Only the hash of the token is kept.
+type RefreshToken struct {
+	Entity
+
+	PlayerID int32
+	// Family is shared by the tokens rotated from the same login
+	Family    string
+	Hash      string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, its family is revoked")
+)
+
+// Use spends the token. A token used before means it leaked, either the
+// player or somebody else holds its replacement, so the caller revokes
+// the whole family on ErrRefreshTokenReused.
+func (t *RefreshToken) Use(now time.Time) error {
+	if t.RevokedAt != nil || !now.Before(t.ExpiresAt) {
+		return ErrRefreshTokenInvalid
+	}
+	if t.UsedAt != nil {
+		return ErrRefreshTokenReused
+	}
+
+	t.UsedAt = &now
+	return nil
+}
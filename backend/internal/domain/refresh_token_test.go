@@ -0,0 +1,48 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+func TestRefreshToken_Use(t *testing.T) {
+	now := time.Now()
+	token := &domain.RefreshToken{ExpiresAt: now.Add(time.Hour)}
+
+	if err := token.Use(now); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if token.UsedAt == nil || !token.UsedAt.Equal(now) {
+		t.Errorf("expected the token to be used at %v, got %v", now, token.UsedAt)
+	}
+}
+
+func TestRefreshToken_Use_Reused(t *testing.T) {
+	now := time.Now()
+	token := &domain.RefreshToken{ExpiresAt: now.Add(time.Hour)}
+
+	_ = token.Use(now)
+	if err := token.Use(now); err != domain.ErrRefreshTokenReused {
+		t.Errorf("expected ErrRefreshTokenReused, got %v", err)
+	}
+}
+
+func TestRefreshToken_Use_Expired(t *testing.T) {
+	now := time.Now()
+	token := &domain.RefreshToken{ExpiresAt: now}
+
+	if err := token.Use(now); err != domain.ErrRefreshTokenInvalid {
+		t.Errorf("expected ErrRefreshTokenInvalid, got %v", err)
+	}
+}
+
+func TestRefreshToken_Use_Revoked(t *testing.T) {
+	now := time.Now()
+	token := &domain.RefreshToken{ExpiresAt: now.Add(time.Hour), RevokedAt: &now}
+
+	if err := token.Use(now); err != domain.ErrRefreshTokenInvalid {
+		t.Errorf("expected ErrRefreshTokenInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+// Role grants the administration rights of a player, each role has the
+// rights of the ones below it.
+type Role string
+
+const (
+	RolePlayer Role = "player"
+	// RoleModerator bans players and finishes or annuls games
+	RoleModerator Role = "moderator"
+	// RoleAdmin also manages the roles, the ratings and the content
+	RoleAdmin Role = "admin"
+)
+
+// roles are ordered from the lowest
+var roles = []Role{RolePlayer, RoleModerator, RoleAdmin}
+
+var (
+	ErrUnknownRole  = errors.New("unknown role")
+	ErrNotPermitted = errors.New("not permitted for the role")
+)
+
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if !slices.Contains(roles, role) {
+		return "", ErrUnknownRole
+	}
+	return role, nil
+}
+
+// AtLeast reports whether the role has the rights of the other. Unknown
+// roles have none.
+func (r Role) AtLeast(other Role) bool {
+	return slices.Index(roles, r) >= max(slices.Index(roles, other), 0)
+}
+
+// Outranks reports whether the role is above the other, only the players
+// of a lower role can be managed.
+func (r Role) Outranks(other Role) bool {
+	return slices.Index(roles, r) > slices.Index(roles, other)
+}
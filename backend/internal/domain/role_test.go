@@ -0,0 +1,50 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+func TestParseRole(t *testing.T) {
+	for _, s := range []string{"player", "moderator", "admin"} {
+		role, err := domain.ParseRole(s)
+		if err != nil || string(role) != s {
+			t.Errorf("expected role %s, got %q and %v", s, role, err)
+		}
+	}
+
+	for _, s := range []string{"", "root", "Admin"} {
+		if _, err := domain.ParseRole(s); err != domain.ErrUnknownRole {
+			t.Errorf("expected ErrUnknownRole for %q, got %v", s, err)
+		}
+	}
+}
+
+func TestRole_AtLeast(t *testing.T) {
+	for _, tc := range []struct {
+		role, other domain.Role
+		want        bool
+	}{
+		{domain.RoleAdmin, domain.RoleModerator, true},
+		{domain.RoleModerator, domain.RoleModerator, true},
+		{domain.RolePlayer, domain.RoleModerator, false},
+		{domain.Role(""), domain.RolePlayer, false},
+	} {
+		if got := tc.role.AtLeast(tc.other); got != tc.want {
+			t.Errorf("expected %q.AtLeast(%q) to be %v", tc.role, tc.other, tc.want)
+		}
+	}
+}
+
+func TestRole_Outranks(t *testing.T) {
+	if !domain.RoleAdmin.Outranks(domain.RoleModerator) {
+		t.Error("expected admins to outrank moderators")
+	}
+	if domain.RoleAdmin.Outranks(domain.RoleAdmin) {
+		t.Error("expected admins not to outrank each other")
+	}
+	if domain.RolePlayer.Outranks(domain.RolePlayer) {
+		t.Error("expected players not to outrank each other")
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+// zobristKeys holds a random key per side and cell. The keys come from a
+// fixed seed and must never change: clients cache evaluations by hash.
+var zobristKeys [2][MaxBoardSize * MaxBoardSize]uint64
+
+// zobristSizeKeys tells apart equal stone layouts on different boards.
+var zobristSizeKeys [MaxBoardSize + 1]uint64
+
+func init() {
+	state := uint64(0x676f6d6f6b75) // "gomoku"
+	for side := range zobristKeys {
+		for i := range zobristKeys[side] {
+			zobristKeys[side][i] = splitmix64(&state)
+		}
+	}
+	for i := range zobristSizeKeys {
+		zobristSizeKeys[i] = splitmix64(&state)
+	}
+}
+
+// splitmix64 advances state and returns the next pseudo random number.
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func zobristKey(stone Stone, row, col int) uint64 {
+	return zobristKeys[stone-1][row*MaxBoardSize+col]
+}
+
+// Symmetry is one of the 8 rotations and reflections of a square board.
+type Symmetry uint8
+
+const (
+	Identity Symmetry = iota
+	Rotate90
+	Rotate180
+	Rotate270
+	FlipHorizontal
+	FlipVertical
+	Transpose
+	AntiTranspose
+)
+
+// Symmetries lists all board symmetries, Identity first.
+var Symmetries = [...]Symmetry{
+	Identity, Rotate90, Rotate180, Rotate270,
+	FlipHorizontal, FlipVertical, Transpose, AntiTranspose,
+}
+
+// Apply maps a cell of a size x size board through the symmetry.
+func (s Symmetry) Apply(size, row, col int) (int, int) {
+	n := size - 1
+	switch s {
+	case Rotate90:
+		return col, n - row
+	case Rotate180:
+		return n - row, n - col
+	case Rotate270:
+		return n - col, row
+	case FlipHorizontal:
+		return row, n - col
+	case FlipVertical:
+		return n - row, col
+	case Transpose:
+		return col, row
+	case AntiTranspose:
+		return n - col, n - row
+	}
+	return row, col
+}
+
+// Inverse returns the symmetry undoing s.
+func (s Symmetry) Inverse() Symmetry {
+	switch s {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	}
+	return s
+}
+
+// Hash returns the Zobrist hash of the position. It only depends on the
+// board size and stone colors, never on who plays them.
+func (g *Board) Hash() uint64 {
+	return g.hash
+}
+
+// CanonicalHash returns the smallest hash among the 8 symmetric variants of
+// the position, equal for positions that only differ by a rotation or a
+// reflection, and the symmetry transforming this board into that variant.
+func (g *Board) CanonicalHash() (uint64, Symmetry) {
+	var hashes [len(Symmetries)]uint64
+
+	for row := 0; row < g.Size; row++ {
+		for col := 0; col < g.Size; col++ {
+			stone := g.At(row, col)
+			if stone == Empty {
+				continue
+			}
+
+			for i, s := range Symmetries {
+				r, c := s.Apply(g.Size, row, col)
+				hashes[i] ^= zobristKey(stone, r, c)
+			}
+		}
+	}
+
+	best := Identity
+	for i, s := range Symmetries {
+		if hashes[i] < hashes[best] {
+			best = s
+		}
+	}
+
+	return hashes[best] ^ zobristSizeKeys[g.Size], best
+}
@@ -0,0 +1,119 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+func TestBoard_Hash_Incremental(t *testing.T) {
+	board1, _ := domain.NewBoard(15)
+	board2, _ := domain.NewBoard(15)
+
+	_ = board1.Put(7, 7, domain.Black)
+	_ = board1.Put(7, 8, domain.White)
+
+	// Same position reached in a different order
+	_ = board2.Put(7, 8, domain.White)
+	_ = board2.Put(7, 7, domain.Black)
+
+	if board1.Hash() != board2.Hash() {
+		t.Errorf("expected equal hashes for equal positions")
+	}
+
+	empty, _ := domain.NewBoard(15)
+	if board1.Hash() == empty.Hash() {
+		t.Errorf("expected hash to change after moves")
+	}
+}
+
+func TestBoard_Hash_Stable(t *testing.T) {
+	board, _ := domain.NewBoard(15)
+	_ = board.Put(7, 7, domain.Black)
+
+	// Hashes are cached by clients, keys must not change between releases
+	if board.Hash() != 0xf6b2074e8dc01e68 {
+		t.Errorf("unexpected hash %#x", board.Hash())
+	}
+}
+
+func TestBoard_Hash_DependsOnColorsAndSize(t *testing.T) {
+	black, _ := domain.NewBoard(15)
+	_ = black.Put(7, 7, domain.Black)
+
+	white, _ := domain.NewBoard(15)
+	_ = white.Put(7, 7, domain.White)
+
+	other, _ := domain.NewBoard(19)
+	_ = other.Put(7, 7, domain.Black)
+
+	if black.Hash() == white.Hash() {
+		t.Errorf("expected hash to depend on stone color")
+	}
+	if black.Hash() == other.Hash() {
+		t.Errorf("expected hash to depend on board size")
+	}
+}
+
+func TestBoard_Hash_Decoded(t *testing.T) {
+	board, _ := domain.NewBoard(9)
+	_ = board.Put(4, 4, domain.Black)
+	_ = board.Put(3, 5, domain.White)
+
+	text, _ := board.MarshalText()
+
+	var decoded domain.Board
+	if err := decoded.UnmarshalText(text); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Hash() != board.Hash() {
+		t.Errorf("expected decoded board to keep the hash")
+	}
+	if board.Clone().Hash() != board.Hash() {
+		t.Errorf("expected cloned board to keep the hash")
+	}
+}
+
+func TestBoard_CanonicalHash(t *testing.T) {
+	moves := [][2]int{{2, 3}, {4, 4}, {1, 6}}
+
+	var want uint64
+	for i, s := range domain.Symmetries {
+		board, _ := domain.NewBoard(9)
+		for j, m := range moves {
+			r, c := s.Apply(9, m[0], m[1])
+			_ = board.Put(r, c, domain.Stone(j%2+1))
+		}
+
+		hash, sym := board.CanonicalHash()
+		if i == 0 {
+			want = hash
+		} else if hash != want {
+			t.Errorf("expected equal canonical hash for symmetry %d", s)
+		}
+
+		// Transforming the board by sym yields the canonical orientation
+		canonical, _ := domain.NewBoard(9)
+		for row := 0; row < 9; row++ {
+			for col := 0; col < 9; col++ {
+				if stone := board.At(row, col); stone != domain.Empty {
+					r, c := sym.Apply(9, row, col)
+					_ = canonical.Put(r, c, stone)
+				}
+			}
+		}
+		if canonical.Hash() != hash {
+			t.Errorf("expected symmetry %d to map the board onto its canonical form", sym)
+		}
+	}
+}
+
+func TestSymmetry_Inverse(t *testing.T) {
+	for _, s := range domain.Symmetries {
+		r, c := s.Apply(15, 3, 11)
+		r, c = s.Inverse().Apply(15, r, c)
+		if r != 3 || c != 11 {
+			t.Errorf("expected inverse of %d to restore the cell, got (%d, %d)", s, r, c)
+		}
+	}
+}
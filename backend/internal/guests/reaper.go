@@ -0,0 +1,75 @@
+// Package guests removes the guest players who stopped playing.
+package guests
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/moLIart/gomoku-backend/internal/infra"
+	"github.com/moLIart/gomoku-backend/internal/repositories"
+)
+
+const (
+	DefaultTTL          = 7 * 24 * time.Hour
+	DefaultReapInterval = time.Hour
+)
+
+// Reaper periodically removes the guests inactive for longer than the TTL.
+// A guest is active when it logs in or refreshes its token.
+type Reaper struct {
+	db       *infra.Database
+	ttl      time.Duration
+	interval time.Duration
+}
+
+func NewReaper(db *infra.Database, ttl, interval time.Duration) *Reaper {
+	return &Reaper{
+		db:       db,
+		ttl:      ttl,
+		interval: interval,
+	}
+}
+
+// Run reaps every interval until the context is done.
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Reap(ctx); err != nil {
+				log.Errorf("Could not remove inactive guests: %s", err)
+			}
+		}
+	}
+}
+
+// Reap removes the inactive guests once and returns how many were removed.
+func (r *Reaper) Reap(ctx context.Context) (int64, error) {
+	// The unit of work of the handlers isn't safe to share with a goroutine
+	uow := repositories.NewUnitOfWork(r.db)
+	if err := uow.Begin(ctx); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	removed, anonymized, err := uow.GetPlayerRepository().RemoveInactiveGuests(now.Add(-r.ttl), now, ctx)
+	if err == nil && len(anonymized) > 0 {
+		// The refresh tokens outlive the guests, they would log back in
+		err = uow.GetRefreshTokenRepository().RevokePlayers(anonymized, now, ctx)
+	}
+	if err := uow.Complete(err); err != nil {
+		return 0, err
+	}
+
+	if removed > 0 {
+		log.Infof("Removed %d inactive guests", removed)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/repositories"
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+type changePasswordRq struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type changePasswordRs struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type changeNicknameRq struct {
+	Nickname string `json:"nickname"`
+}
+
+type deleteAccountRq struct {
+	Password string `json:"password"`
+}
+
+type accountDto struct {
+	ID       int    `json:"id"`
+	Nickname string `json:"nickname"`
+	Score    int    `json:"score"`
+}
+
+func mapToAccount(player *domain.Player) *accountDto {
+	return &accountDto{
+		ID:       int(player.ID),
+		Nickname: player.Nickname,
+		Score:    player.Score,
+	}
+}
+
+// HandleChangePassword godoc
+//
+// @Summary      Change password
+// @Description  Replaces the password of the player, the current one is required. Every session of the player is logged out, the response carries the tokens of a new one.
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        changePasswordRq  body      changePasswordRq  true  "Current and new password"
+// @Success      200               {object}  changePasswordRs
+// @Failure      400               {object}  errorRs
+// @Failure      401               {object}  errorRs
+// @Failure      403               {object}  errorRs
+// @Failure      500               {object}  errorRs
+// @Router       /api/v1/account/password [put]
+func HandleChangePassword(uow *repositories.UnitOfWork, jwtSvc *services.JWTService, revocations *services.RevocationService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq changePasswordRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		player, err := players.GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := player.ChangePassword(rq.OldPassword, rq.NewPassword); err != nil {
+			uow.Complete(nil)
+
+			// Not 401, the token is valid and clients refresh it on 401
+			if errors.Is(err, domain.ErrInvalidCredentials) || errors.Is(err, domain.ErrGuestAccount) {
+				writeErrorRs(w, http.StatusForbidden, err)
+				return
+			}
+
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := players.UpdatePassword(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := revokeSessions(uow, player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		refreshToken, err := issueRefreshToken(uow, jwtSvc, player.ID, "", r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		revocations.SetGeneration(player.ID, player.TokenGeneration)
+
+		tokenString, err := jwtSvc.Sign(player)
+		if err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(&changePasswordRs{Token: tokenString, RefreshToken: refreshToken}); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleChangeNickname godoc
+//
+// @Summary      Change nickname
+// @Description  Renames the player, the nickname follows the registration rules and must be free. The nicknames of the admins are reserved. The games of the player keep it as their participant under the new nickname.
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        changeNicknameRq  body      changeNicknameRq  true  "New nickname"
+// @Success      200               {object}  accountDto
+// @Failure      400               {object}  errorRs
+// @Failure      401               {object}  errorRs
+// @Failure      403               {object}  errorRs
+// @Failure      409               {object}  errorRs
+// @Failure      500               {object}  errorRs
+// @Router       /api/v1/account/nickname [put]
+func HandleChangeNickname(uow *repositories.UnitOfWork, reserved []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq changeNicknameRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		player, err := players.GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := player.Rename(rq.Nickname); err != nil {
+			uow.Complete(nil)
+
+			if errors.Is(err, domain.ErrGuestAccount) {
+				writeErrorRs(w, http.StatusForbidden, err)
+				return
+			}
+
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// Taking the free nickname of an admin would grant its rights
+		if slices.Contains(reserved, player.Nickname) {
+			uow.Complete(nil)
+			writeErrorRs(w, http.StatusConflict, domain.ErrNicknameReserved)
+			return
+		}
+
+		if err := players.UpdateNickname(player, r.Context()); err != nil {
+			if errors.Is(err, domain.ErrPlayerAlreadyExists) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusConflict, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToAccount(player)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleDeleteAccount godoc
+//
+// @Summary      Delete account
+// @Description  Deletes the account of the player, the password is required. The player is anonymized rather than removed: the games keep it as their participant under a placeholder nickname, the nickname is freed, every session is logged out and the API keys of the bots of the player are revoked.
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        deleteAccountRq  body  deleteAccountRq  true  "Password"
+// @Success      204
+// @Failure      400  {object}  errorRs
+// @Failure      401  {object}  errorRs
+// @Failure      403  {object}  errorRs
+// @Failure      500  {object}  errorRs
+// @Router       /api/v1/account [delete]
+func HandleDeleteAccount(uow *repositories.UnitOfWork, revocations *services.RevocationService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq deleteAccountRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		player, err := players.GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := player.CheckPassword(rq.Password); err != nil {
+			uow.Complete(nil)
+			writeErrorRs(w, http.StatusForbidden, err)
+			return
+		}
+
+		player.Delete(time.Now())
+
+		if err := players.Delete(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// The bots stop playing with their owner gone
+		if err := uow.GetAPIKeyRepository().RevokeOwnedBy(player.ID, *player.DeletedAt, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := revokeSessions(uow, player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		revocations.SetGeneration(player.ID, player.TokenGeneration)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
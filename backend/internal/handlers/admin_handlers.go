@@ -0,0 +1,945 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/moLIart/gomoku-backend/internal/ai/book"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/middleware"
+	"github.com/moLIart/gomoku-backend/internal/repositories"
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+type bookReloadRs struct {
+	Positions int `json:"positions"`
+}
+
+type adminPlayerDto struct {
+	ID        int        `json:"id"`
+	Nickname  string     `json:"nickname"`
+	Score     int        `json:"score"`
+	Role      string     `json:"role"`
+	Guest     bool       `json:"guest"`
+	Bot       bool       `json:"bot"`
+	OwnerID   null.Int   `json:"owner_id,omitempty"`
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	BanReason string     `json:"ban_reason,omitempty"`
+}
+
+type setRoleRq struct {
+	// Role is player or moderator, admins are named by the -admins flag
+	Role   string `json:"role"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type banPlayerRq struct {
+	Reason string `json:"reason"`
+}
+
+type adjustScoreRq struct {
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type finishGameRq struct {
+	WinnerID int    `json:"winner_id"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type annulGameRq struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type auditEntryDto struct {
+	ID         int            `json:"id"`
+	ActorID    int            `json:"actor_id"`
+	Action     string         `json:"action"`
+	TargetType string         `json:"target_type"`
+	TargetID   null.Int       `json:"target_id,omitempty"`
+	Details    map[string]any `json:"details"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func mapToAdminPlayer(player *domain.Player) adminPlayerDto {
+	dto := adminPlayerDto{
+		ID:        int(player.ID),
+		Nickname:  player.Nickname,
+		Score:     player.Score,
+		Role:      string(player.Role),
+		Guest:     player.Guest,
+		Bot:       player.Bot,
+		BannedAt:  player.BannedAt,
+		BanReason: player.BanReason,
+	}
+	if player.OwnerID != 0 {
+		dto.OwnerID = null.IntFrom(int64(player.OwnerID))
+	}
+	return dto
+}
+
+func mapToAuditEntry(entry *domain.AuditEntry) auditEntryDto {
+	dto := auditEntryDto{
+		ID:         int(entry.ID),
+		ActorID:    int(entry.ActorID),
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		Details:    entry.Details,
+		CreatedAt:  entry.CreatedAt,
+	}
+	if entry.TargetID != 0 {
+		dto.TargetID = null.IntFrom(int64(entry.TargetID))
+	}
+	return dto
+}
+
+// The admin lists are paged, 50 entries by default and 200 at most.
+const (
+	adminPageLimit    = 50
+	adminMaxPageLimit = 200
+)
+
+// pageParams returns the limit and offset query parameters of a list.
+func pageParams(r *http.Request) (int, int, error) {
+	limit, offset := adminPageLimit, 0
+
+	var err error
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > adminMaxPageLimit {
+			return 0, 0, errors.New("limit must be between 1 and 200")
+		}
+	}
+	if s := r.URL.Query().Get("offset"); s != "" {
+		if offset, err = strconv.Atoi(s); err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must not be negative")
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// adminErrorCode returns the status of the expected errors of the admin
+// actions, false for the unexpected ones.
+func adminErrorCode(err error) (int, bool) {
+	switch {
+	case errors.Is(err, domain.ErrPlayerNotFound), errors.Is(err, domain.ErrGameNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, domain.ErrNotPermitted):
+		return http.StatusForbidden, true
+	case errors.Is(err, domain.ErrGameFinished), errors.Is(err, domain.ErrGameAnnulled):
+		return http.StatusConflict, true
+	case errors.Is(err, domain.ErrUnknownRole), errors.Is(err, domain.ErrGameNotReady),
+		errors.Is(err, domain.ErrNotParticipant):
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+// getManagedPlayer returns the player of the playerId parameter along with
+// the acting player, domain.ErrNotPermitted unless the actor outranks it.
+// The roles are read from the database, the token may predate a change.
+func getManagedPlayer(uow *repositories.UnitOfWork, r *http.Request, auth *middleware.AuthPlayer) (*domain.Player, *domain.Player, error) {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+	playerId, err := strconv.Atoi(params.ByName("playerId"))
+	if err != nil {
+		return nil, nil, domain.ErrPlayerNotFound
+	}
+
+	players := uow.GetPlayerRepository()
+
+	actor, err := players.GetById(auth.ID, r.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	player, err := players.GetById(int32(playerId), r.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !actor.CanManage(player) {
+		return nil, nil, domain.ErrNotPermitted
+	}
+
+	return player, actor, nil
+}
+
+// getGameParam returns the game of the gameId parameter.
+func getGameParam(uow *repositories.UnitOfWork, r *http.Request) (*domain.Game, error) {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+	gameId, err := strconv.Atoi(params.ByName("gameId"))
+	if err != nil {
+		return nil, domain.ErrGameNotFound
+	}
+
+	return uow.GetGameRepository().GetById(int32(gameId), r.Context())
+}
+
+// checkManagedGame returns domain.ErrNotPermitted unless the acting player
+// manages both players of the game. A moderator can't finish or annul a
+// game of their own, it would score for them without an admin.
+func checkManagedGame(uow *repositories.UnitOfWork, game *domain.Game, auth *middleware.AuthPlayer, ctx context.Context) error {
+	if seatedPlayer(game, auth.ID) != nil {
+		return domain.ErrNotPermitted
+	}
+
+	players := uow.GetPlayerRepository()
+
+	actor, err := players.GetById(auth.ID, ctx)
+	if err != nil {
+		return err
+	}
+
+	// The players of the game are loaded without their roles
+	for _, seated := range game.Players {
+		if seated == nil {
+			continue
+		}
+
+		player, err := players.GetById(seated.ID, ctx)
+		if err != nil {
+			return err
+		}
+		// A deleted player left the game, any moderator may close it
+		if !player.IsDeleted() && !actor.CanManage(player) {
+			return domain.ErrNotPermitted
+		}
+	}
+
+	return nil
+}
+
+// seatedPlayer returns the player of the game with the ID, nil for the
+// outsiders.
+func seatedPlayer(game *domain.Game, id int32) *domain.Player {
+	for _, player := range game.Players {
+		if player != nil && player.ID == id {
+			return player
+		}
+	}
+	return nil
+}
+
+// audit writes the action of the player to the audit log in the current
+// transaction, the action is undone if it can't be recorded.
+func audit(uow *repositories.UnitOfWork, auth *middleware.AuthPlayer, action, targetType string, targetID int32, details map[string]any, ctx context.Context) error {
+	entry := domain.NewAuditEntry(auth.ID, action, targetType, targetID, details)
+	return uow.GetAuditRepository().Save(entry, ctx)
+}
+
+// HandleReloadBook godoc
+//
+// @Summary      Reload the opening book
+// @Description  Reads the opening book file of the AI again. The current book stays in use if the file is invalid.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  bookReloadRs
+// @Failure      401   {object}  errorRs
+// @Failure      403   {object}  errorRs
+// @Failure      409   {object}  errorRs
+// @Failure      422   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/admin/book/reload [post]
+func HandleReloadBook(uow *repositories.UnitOfWork, loader *book.Loader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		if loader == nil {
+			writeErrorRs(w, http.StatusConflict, errors.New("no opening book is configured"))
+			return
+		}
+
+		b, err := loader.Reload()
+		if err != nil {
+			writeErrorRs(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		log.Infof("Opening book reloaded with %d positions", b.Positions())
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := map[string]any{"positions": b.Positions()}
+		if err := audit(uow, auth, domain.AuditReloadBook, domain.AuditTargetBook, 0, details, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(bookReloadRs{Positions: b.Positions()})
+	})
+}
+
+// HandleSearchPlayers godoc
+//
+// @Summary      Search players
+// @Description  Returns a page of the players whose nickname contains the query, ordered by the nickname. Without a query the registered players are listed, the guests are left out.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q       query     string  false  "Part of the nickname"
+// @Param        limit   query     int     false  "Page size, 50 by default and 200 at most"
+// @Param        offset  query     int     false  "Players to skip"
+// @Success      200     {array}   adminPlayerDto
+// @Failure      400     {object}  errorRs
+// @Failure      401     {object}  errorRs
+// @Failure      403     {object}  errorRs
+// @Failure      500     {object}  errorRs
+// @Router       /api/v1/admin/players [get]
+func HandleSearchPlayers(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := pageParams(r)
+		if err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players, err := uow.GetPlayerRepository().Search(r.URL.Query().Get("q"), limit, offset, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dtos := make([]adminPlayerDto, len(players))
+		for i, player := range players {
+			dtos[i] = mapToAdminPlayer(player)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dtos); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleSetRole godoc
+//
+// @Summary      Set the role of a player
+// @Description  Makes the player a moderator or a player again. Admins are named at startup, the role can't be granted here. The access tokens of the player are revoked, a refresh gets one with the new role.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        playerId   path      int        true  "Player ID"
+// @Param        setRoleRq  body      setRoleRq  true  "New role"
+// @Success      200        {object}  adminPlayerDto
+// @Failure      400        {object}  errorRs
+// @Failure      401        {object}  errorRs
+// @Failure      403        {object}  errorRs
+// @Failure      404        {object}  errorRs
+// @Failure      500        {object}  errorRs
+// @Router       /api/v1/admin/players/{playerId}/role [put]
+func HandleSetRole(uow *repositories.UnitOfWork, revocations *services.RevocationService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq setRoleRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		role, err := domain.ParseRole(rq.Role)
+		if err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player, actor, err := getManagedPlayer(uow, r, auth)
+		if err == nil && !actor.Role.Outranks(role) {
+			err = domain.ErrNotPermitted
+		}
+		if err == nil {
+			err = player.SetRole(role)
+		}
+		if err != nil {
+			if code, ok := adminErrorCode(err); ok {
+				uow.Complete(nil)
+				writeErrorRs(w, code, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		if err := players.UpdateRole(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// The refresh tokens stay, the next refresh signs the new role
+		if err := players.BumpTokenGeneration(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := map[string]any{"role": player.Role, "reason": rq.Reason}
+		if err := audit(uow, auth, domain.AuditSetRole, domain.AuditTargetPlayer, player.ID, details, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		revocations.SetGeneration(player.ID, player.TokenGeneration)
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToAdminPlayer(player)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleBanPlayer godoc
+//
+// @Summary      Ban a player
+// @Description  Bans the player: every session is logged out, logging in is refused and the API keys of a banned bot stop working. The API keys of the bots of the player are revoked, unbanning doesn't restore them. Banning a banned player replaces the reason.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        playerId     path      int          true  "Player ID"
+// @Param        banPlayerRq  body      banPlayerRq  true  "Reason of the ban"
+// @Success      200          {object}  adminPlayerDto
+// @Failure      400          {object}  errorRs
+// @Failure      401          {object}  errorRs
+// @Failure      403          {object}  errorRs
+// @Failure      404          {object}  errorRs
+// @Failure      500          {object}  errorRs
+// @Router       /api/v1/admin/players/{playerId}/ban [post]
+func HandleBanPlayer(uow *repositories.UnitOfWork, revocations *services.RevocationService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq banPlayerRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player, _, err := getManagedPlayer(uow, r, auth)
+		if err != nil {
+			if code, ok := adminErrorCode(err); ok {
+				uow.Complete(nil)
+				writeErrorRs(w, code, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		now := time.Now()
+		if err := player.Ban(rq.Reason, now); err != nil {
+			uow.Complete(nil)
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.GetPlayerRepository().UpdateBan(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := revokeSessions(uow, player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// The bots would keep playing for a banned owner
+		if err := uow.GetAPIKeyRepository().RevokeOwnedBy(player.ID, now, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := map[string]any{"reason": player.BanReason}
+		if err := audit(uow, auth, domain.AuditBanPlayer, domain.AuditTargetPlayer, player.ID, details, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		revocations.SetGeneration(player.ID, player.TokenGeneration)
+
+		log.Infof("Player %d banned by %d", player.ID, auth.ID)
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToAdminPlayer(player)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleUnbanPlayer godoc
+//
+// @Summary      Unban a player
+// @Description  Lifts the ban of the player, the player logs in again.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        playerId  path      int  true  "Player ID"
+// @Success      200       {object}  adminPlayerDto
+// @Failure      401       {object}  errorRs
+// @Failure      403       {object}  errorRs
+// @Failure      404       {object}  errorRs
+// @Failure      500       {object}  errorRs
+// @Router       /api/v1/admin/players/{playerId}/ban [delete]
+func HandleUnbanPlayer(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player, _, err := getManagedPlayer(uow, r, auth)
+		if err != nil {
+			if code, ok := adminErrorCode(err); ok {
+				uow.Complete(nil)
+				writeErrorRs(w, code, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player.Unban()
+
+		if err := uow.GetPlayerRepository().UpdateBan(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := audit(uow, auth, domain.AuditUnbanPlayer, domain.AuditTargetPlayer, player.ID, nil, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToAdminPlayer(player)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleAdjustScore godoc
+//
+// @Summary      Adjust the rating of a player
+// @Description  Corrects the score of the player by the delta, e.g. to revert the gains of a cheater.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        playerId       path      int            true  "Player ID"
+// @Param        adjustScoreRq  body      adjustScoreRq  true  "Score correction"
+// @Success      200            {object}  adminPlayerDto
+// @Failure      400            {object}  errorRs
+// @Failure      401            {object}  errorRs
+// @Failure      403            {object}  errorRs
+// @Failure      404            {object}  errorRs
+// @Failure      500            {object}  errorRs
+// @Router       /api/v1/admin/players/{playerId}/score [put]
+func HandleAdjustScore(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq adjustScoreRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if rq.Delta == 0 {
+			writeErrorRs(w, http.StatusBadRequest, errors.New("delta must not be zero"))
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player, _, err := getManagedPlayer(uow, r, auth)
+		if err != nil {
+			if code, ok := adminErrorCode(err); ok {
+				uow.Complete(nil)
+				writeErrorRs(w, code, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		before := player.Score
+		player.AdjustScore(rq.Delta)
+
+		if err := uow.GetPlayerRepository().Save(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := map[string]any{"from": before, "to": player.Score, "reason": rq.Reason}
+		if err := audit(uow, auth, domain.AuditAdjustScore, domain.AuditTargetPlayer, player.ID, details, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToAdminPlayer(player)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleFinishGame godoc
+//
+// @Summary      Force-finish a game
+// @Description  Ends the game with the winner without playing it out, e.g. when a player abandoned it. The winner of a rated game scores as for a win on the board. The caller must manage both players and can't be one of them.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gameId        path      int           true  "Game ID"
+// @Param        finishGameRq  body      finishGameRq  true  "Winner of the game"
+// @Success      200           {object}  gameStateDto
+// @Failure      400           {object}  errorRs
+// @Failure      401           {object}  errorRs
+// @Failure      403           {object}  errorRs
+// @Failure      404           {object}  errorRs
+// @Failure      409           {object}  errorRs
+// @Failure      500           {object}  errorRs
+// @Router       /api/v1/admin/games/{gameId}/finish [post]
+func HandleFinishGame(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq finishGameRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		game, err := getGameParam(uow, r)
+		if err == nil {
+			err = checkManagedGame(uow, game, auth, r.Context())
+		}
+		if err == nil {
+			err = game.Finish(seatedPlayer(game, int32(rq.WinnerID)))
+		}
+		if err != nil {
+			if code, ok := adminErrorCode(err); ok {
+				uow.Complete(nil)
+				writeErrorRs(w, code, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if game.Rated {
+			game.WinnerPlayer.AddScore()
+
+			if err := uow.GetPlayerRepository().Save(game.WinnerPlayer, r.Context()); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		if err := uow.GetGameRepository().Save(game, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := map[string]any{"winner_id": game.WinnerPlayer.ID, "reason": rq.Reason}
+		if err := audit(uow, auth, domain.AuditFinishGame, domain.AuditTargetGame, game.ID, details, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToGameState(game)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleAnnulGame godoc
+//
+// @Summary      Annul a game
+// @Description  Ends the game without a result. The point the winner of a rated game scored is taken back, the winner stays in the record. The caller must manage both players and can't be one of them.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        gameId       path      int          true   "Game ID"
+// @Param        annulGameRq  body      annulGameRq  false  "Reason of the annulment"
+// @Success      200          {object}  gameStateDto
+// @Failure      400          {object}  errorRs
+// @Failure      401          {object}  errorRs
+// @Failure      403          {object}  errorRs
+// @Failure      404          {object}  errorRs
+// @Failure      409          {object}  errorRs
+// @Failure      500          {object}  errorRs
+// @Router       /api/v1/admin/games/{gameId}/annul [post]
+func HandleAnnulGame(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		// The reason is optional, so is the body
+		var rq annulGameRq
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+				writeErrorRs(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		game, err := getGameParam(uow, r)
+		if err == nil {
+			err = checkManagedGame(uow, game, auth, r.Context())
+		}
+		if err == nil {
+			err = game.Annul(time.Now())
+		}
+		if err != nil {
+			if code, ok := adminErrorCode(err); ok {
+				uow.Complete(nil)
+				writeErrorRs(w, code, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if ok, winner := game.HasWinner(); ok && game.Rated {
+			winner.DecScore()
+
+			if err := uow.GetPlayerRepository().Save(winner, r.Context()); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		if err := uow.GetGameRepository().Save(game, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := map[string]any{"reason": rq.Reason}
+		if game.WinnerPlayer != nil {
+			details["winner_id"] = game.WinnerPlayer.ID
+		}
+		if err := audit(uow, auth, domain.AuditAnnulGame, domain.AuditTargetGame, game.ID, details, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToGameState(game)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleListAudit godoc
+//
+// @Summary      List the audit log
+// @Description  Returns a page of the actions of the moderators and the admins, newest first. The target type alone lists every target of the type.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        target_type  query     string  false  "player, game, book or puzzle"
+// @Param        target_id    query     int     false  "ID of the target"
+// @Param        limit        query     int     false  "Page size, 50 by default and 200 at most"
+// @Param        offset       query     int     false  "Entries to skip"
+// @Success      200          {array}   auditEntryDto
+// @Failure      400          {object}  errorRs
+// @Failure      401          {object}  errorRs
+// @Failure      403          {object}  errorRs
+// @Failure      500          {object}  errorRs
+// @Router       /api/v1/admin/audit [get]
+func HandleListAudit(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, offset, err := pageParams(r)
+		if err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		targetType := r.URL.Query().Get("target_type")
+
+		var targetID int
+		if s := r.URL.Query().Get("target_id"); s != "" {
+			if targetID, err = strconv.Atoi(s); err != nil || targetType == "" {
+				writeErrorRs(w, http.StatusBadRequest, errors.New("target_id must be an ID and come with target_type"))
+				return
+			}
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		entries, err := uow.GetAuditRepository().List(targetType, int32(targetID), limit, offset, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dtos := make([]auditEntryDto, len(entries))
+		for i, entry := range entries {
+			dtos[i] = mapToAuditEntry(entry)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dtos); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
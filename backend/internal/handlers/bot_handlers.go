@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/middleware"
+	"github.com/moLIart/gomoku-backend/internal/repositories"
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+type createBotRq struct {
+	Nickname string `json:"nickname"`
+}
+
+type botDto struct {
+	ID       int    `json:"id"`
+	Nickname string `json:"nickname"`
+	Score    int    `json:"score"`
+}
+
+type createAPIKeyRq struct {
+	Name string `json:"name"`
+	// Scopes are read and play, all of them when empty
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+type apiKeyDto struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type createAPIKeyRs struct {
+	apiKeyDto
+	// Key is shown once, it can't be read again
+	Key string `json:"key"`
+}
+
+func mapToBot(bot *domain.Player) botDto {
+	return botDto{
+		ID:       int(bot.ID),
+		Nickname: bot.Nickname,
+		Score:    bot.Score,
+	}
+}
+
+func mapToAPIKey(key *domain.APIKey) apiKeyDto {
+	return apiKeyDto{
+		ID:         int(key.ID),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+// getOwnedBot returns the bot of the botId parameter, domain.ErrPlayerNotFound
+// unless the player owns it.
+func getOwnedBot(uow *repositories.UnitOfWork, r *http.Request, auth *middleware.AuthPlayer) (*domain.Player, error) {
+	params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+	botId, err := strconv.Atoi(params.ByName("botId"))
+	if err != nil {
+		return nil, domain.ErrPlayerNotFound
+	}
+
+	bot, err := uow.GetPlayerRepository().GetById(int32(botId), r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// The bots of the others look like missing ones
+	if !bot.IsBotOf(auth.ID) {
+		return nil, domain.ErrPlayerNotFound
+	}
+
+	return bot, nil
+}
+
+// HandleCreateBot godoc
+//
+// @Summary      Create a bot
+// @Description  Creates a bot account owned by the player. Bots have no password, they play with the API keys of their owner.
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        createBotRq  body      createBotRq  true  "Nickname of the bot"
+// @Success      200          {object}  botDto
+// @Failure      400          {object}  errorRs
+// @Failure      401          {object}  errorRs
+// @Failure      403          {object}  errorRs
+// @Failure      409          {object}  errorRs
+// @Failure      500          {object}  errorRs
+// @Router       /api/v1/bots [post]
+func HandleCreateBot(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq createBotRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		owner, err := players.GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		bot, err := domain.NewBot(rq.Nickname, owner)
+		if err != nil {
+			uow.Complete(nil)
+
+			if errors.Is(err, domain.ErrGuestAccount) || errors.Is(err, domain.ErrPlayerNotFound) {
+				writeErrorRs(w, http.StatusForbidden, err)
+				return
+			}
+
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := players.Save(bot, r.Context()); err != nil {
+			if errors.Is(err, domain.ErrPlayerAlreadyExists) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusConflict, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToBot(bot)); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleListBots godoc
+//
+// @Summary      List bots
+// @Description  Returns the bots owned by the player.
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   botDto
+// @Failure      401  {object}  errorRs
+// @Failure      500  {object}  errorRs
+// @Router       /api/v1/bots [get]
+func HandleListBots(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		bots, err := uow.GetPlayerRepository().ListBots(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dtos := make([]botDto, len(bots))
+		for i, bot := range bots {
+			dtos[i] = mapToBot(bot)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dtos); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleCreateAPIKey godoc
+//
+// @Summary      Create an API key
+// @Description  Creates an API key of the bot, sent in the X-API-Key header instead of a token. The read scope lists and shows the games, the play scope starts, joins and plays them. The key is returned once, only its prefix can be listed later.
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        botId           path      int             true  "Bot ID"
+// @Param        createAPIKeyRq  body      createAPIKeyRq  true  "Name and scopes of the key"
+// @Success      200             {object}  createAPIKeyRs
+// @Failure      400             {object}  errorRs
+// @Failure      401             {object}  errorRs
+// @Failure      404             {object}  errorRs
+// @Failure      500             {object}  errorRs
+// @Router       /api/v1/bots/{botId}/keys [post]
+func HandleCreateAPIKey(uow *repositories.UnitOfWork, apiKeys *services.APIKeyService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq createAPIKeyRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		bot, err := getOwnedBot(uow, r, auth)
+		if err != nil {
+			if errors.Is(err, domain.ErrPlayerNotFound) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusNotFound, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		key, record, err := apiKeys.NewAPIKey(bot, rq.Name, rq.Scopes)
+		if err != nil {
+			uow.Complete(nil)
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.GetAPIKeyRepository().Save(record, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(&createAPIKeyRs{apiKeyDto: mapToAPIKey(record), Key: key}); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleListAPIKeys godoc
+//
+// @Summary      List API keys
+// @Description  Returns the API keys of the bot, the revoked ones included.
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        botId  path      int  true  "Bot ID"
+// @Success      200    {array}   apiKeyDto
+// @Failure      401    {object}  errorRs
+// @Failure      404    {object}  errorRs
+// @Failure      500    {object}  errorRs
+// @Router       /api/v1/bots/{botId}/keys [get]
+func HandleListAPIKeys(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		bot, err := getOwnedBot(uow, r, auth)
+		if err != nil {
+			if errors.Is(err, domain.ErrPlayerNotFound) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusNotFound, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		keys, err := uow.GetAPIKeyRepository().ListByPlayer(bot.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dtos := make([]apiKeyDto, len(keys))
+		for i, key := range keys {
+			dtos[i] = mapToAPIKey(key)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dtos); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleRevokeAPIKey godoc
+//
+// @Summary      Revoke an API key
+// @Description  Revokes the API key of the bot, the requests with it are refused right away.
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        botId  path  int  true  "Bot ID"
+// @Param        keyId  path  int  true  "API key ID"
+// @Success      204
+// @Failure      401  {object}  errorRs
+// @Failure      404  {object}  errorRs
+// @Failure      500  {object}  errorRs
+// @Router       /api/v1/bots/{botId}/keys/{keyId} [delete]
+func HandleRevokeAPIKey(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+		keyId, err := strconv.Atoi(params.ByName("keyId"))
+		if err != nil {
+			writeErrorRs(w, http.StatusNotFound, domain.ErrAPIKeyNotFound)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		bot, err := getOwnedBot(uow, r, auth)
+		if err != nil {
+			if errors.Is(err, domain.ErrPlayerNotFound) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusNotFound, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		keys := uow.GetAPIKeyRepository()
+
+		key, err := keys.GetById(int32(keyId), r.Context())
+		if err == nil && key.PlayerID != bot.ID {
+			err = domain.ErrAPIKeyNotFound
+		}
+		if err != nil {
+			if errors.Is(err, domain.ErrAPIKeyNotFound) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusNotFound, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		key.Revoke(time.Now())
+
+		if err := keys.Save(key, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -2,10 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/moLIart/gomoku-backend/internal/analysis"
 	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/middleware"
+	"github.com/moLIart/gomoku-backend/internal/solver"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/guregu/null.v3"
 )
@@ -25,9 +30,31 @@ func writeErrorRs(w http.ResponseWriter, code int, err error) {
 	}
 }
 
+var errNotAuthenticated = errors.New("not authenticated")
+
+// authPlayer returns the player authenticated by the request token and
+// writes 401 if there's none, the route lacks JWTAuth.
+func authPlayer(w http.ResponseWriter, r *http.Request) (*middleware.AuthPlayer, bool) {
+	player, ok := middleware.AuthPlayerFrom(r.Context())
+	if !ok {
+		writeErrorRs(w, http.StatusUnauthorized, errNotAuthenticated)
+		return nil, false
+	}
+
+	return player, true
+}
+
 type startGameRq struct {
-	Type string `json:"game_type"`
-	Size int    `json:"board_size"`
+	Type  string `json:"game_type"`
+	Size  int    `json:"board_size"`
+	Rated *bool  `json:"rated,omitempty"`
+}
+
+type importGameRq struct {
+	Format string `json:"format"`
+	Record string `json:"record"`
+	Size   int    `json:"board_size,omitempty"`
+	Mode   string `json:"mode"`
 }
 
 type moveGameRq struct {
@@ -35,19 +62,44 @@ type moveGameRq struct {
 	Col int `json:"col"`
 }
 
+type undoRespondRq struct {
+	Accept bool `json:"accept"`
+}
+
 type gameStateDto struct {
-	ID            int          `json:"id"`
-	Type          string       `json:"type"`
-	CurrentPlayer int          `json:"current_player"`
-	Winner        null.Int     `json:"winner,omitempty"`
-	Size          int          `json:"size"`
-	Board         [][]null.Int `json:"board"`
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	Rated bool   `json:"rated"`
+	// Bot is set when a bot plays in the game
+	Bot bool `json:"bot"`
+	// Annulled games are over, their result doesn't count
+	Annulled      bool            `json:"annulled"`
+	CurrentPlayer int             `json:"current_player"`
+	Winner        null.Int        `json:"winner,omitempty"`
+	Size          int             `json:"size"`
+	Players       []gamePlayerDto `json:"players"`
+	Board         [][]null.Int    `json:"board"`
+	LastMove      *pointDto       `json:"last_move,omitempty"`
+	Hash          string          `json:"hash"`
+	CanonicalHash string          `json:"canonical_hash"`
+	UndoRequested null.Int        `json:"undo_requested_by,omitempty"`
+	WinningLine   []pointDto      `json:"winning_line,omitempty"`
+}
+
+type gamePlayerDto struct {
+	ID       int    `json:"id"`
+	Nickname string `json:"nickname"`
+	Stone    string `json:"stone"`
+	Bot      bool   `json:"bot"`
 }
 
 func mapToGameState(game *domain.Game) *gameStateDto {
 	dto := &gameStateDto{
 		ID:            int(game.ID),
 		Type:          string(game.Type),
+		Rated:         game.Rated,
+		Bot:           game.HasBot(),
+		Annulled:      game.IsAnnulled(),
 		Size:          game.Board.Size,
 		CurrentPlayer: int(game.CurrentPlayer.ID),
 	}
@@ -56,18 +108,177 @@ func mapToGameState(game *domain.Game) *gameStateDto {
 		dto.Winner = null.IntFrom(int64(game.WinnerPlayer.ID))
 	}
 
+	if game.WinningLine != nil {
+		dto.WinningLine = mapToPoints(game.WinningLine)
+	}
+
+	if game.UndoRequestedBy != nil {
+		dto.UndoRequested = null.IntFrom(int64(game.UndoRequestedBy.ID))
+	}
+
+	for _, stone := range []domain.Stone{domain.Black, domain.White} {
+		if player := game.PlayerOf(stone); player != nil {
+			dto.Players = append(dto.Players, gamePlayerDto{ID: int(player.ID), Nickname: player.Nickname, Stone: stone.String(), Bot: player.Bot})
+		}
+	}
+
+	if len(game.Moves) > 0 {
+		last := game.Moves[len(game.Moves)-1]
+		dto.LastMove = &pointDto{Row: last.Row, Col: last.Col}
+	}
+
 	dto.Size = game.Board.Size
 	dto.Board = make([][]null.Int, dto.Size)
 	for i := 0; i < dto.Size; i++ {
 		dto.Board[i] = make([]null.Int, dto.Size)
 		for j := 0; j < dto.Size; j++ {
-			if game.Board.Data[i][j] != 0 {
-				dto.Board[i][j] = null.IntFrom(int64(game.Board.Data[i][j]))
+			if player := game.PlayerOf(game.Board.At(i, j)); player != nil {
+				dto.Board[i][j] = null.IntFrom(int64(player.ID))
 			} else {
 				dto.Board[i][j] = null.Int{}
 			}
 		}
 	}
 
+	canonicalHash, _ := game.Board.CanonicalHash()
+	dto.Hash = fmt.Sprintf("%016x", game.Board.Hash())
+	dto.CanonicalHash = fmt.Sprintf("%016x", canonicalHash)
+
+	return dto
+}
+
+type gameSummaryDto struct {
+	ID            int         `json:"id"`
+	Type          string      `json:"type"`
+	Rated         bool        `json:"rated"`
+	Bot           bool        `json:"bot"`
+	Size          int         `json:"size"`
+	Black         null.String `json:"black"`
+	White         null.String `json:"white"`
+	CurrentPlayer int         `json:"current_player"`
+	Moves         int         `json:"moves"`
+}
+
+func mapToGameSummary(game *domain.Game) gameSummaryDto {
+	dto := gameSummaryDto{
+		ID:            int(game.ID),
+		Type:          string(game.Type),
+		Rated:         game.Rated,
+		Bot:           game.HasBot(),
+		Size:          game.Board.Size,
+		CurrentPlayer: int(game.CurrentPlayer.ID),
+		Moves:         game.Board.Count(domain.Black) + game.Board.Count(domain.White),
+	}
+
+	if player := game.PlayerOf(domain.Black); player != nil {
+		dto.Black = null.StringFrom(player.Nickname)
+	}
+	if player := game.PlayerOf(domain.White); player != nil {
+		dto.White = null.StringFrom(player.Nickname)
+	}
+
+	return dto
+}
+
+type pointDto struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+type threatDto struct {
+	Kind     string     `json:"kind"`
+	Side     string     `json:"side"`
+	Player   null.Int   `json:"player,omitempty"`
+	Stones   []pointDto `json:"stones"`
+	Gains    []pointDto `json:"gains"`
+	Defenses []pointDto `json:"defenses"`
+}
+
+type forkDto struct {
+	Kind   string   `json:"kind"`
+	Side   string   `json:"side"`
+	Player null.Int `json:"player,omitempty"`
+	Cell   pointDto `json:"cell"`
+}
+
+type forcedWinDto struct {
+	// Outcome is win, no_win when there is none within the solver depth
+	// or unknown when the node budget ran out
+	Outcome string      `json:"outcome"`
+	Kind    null.String `json:"kind,omitempty"`
+	Side    string      `json:"side"`
+	Player  null.Int    `json:"player,omitempty"`
+	// Line alternates moves of the side and the opponent, the last one
+	// completes five
+	Line []pointDto `json:"line"`
+}
+
+type analysisDto struct {
+	GameID  int         `json:"game_id"`
+	Threats []threatDto `json:"threats"`
+	Forks   []forkDto   `json:"forks"`
+	// BestMove is the suggestion of the AI for the side to move
+	BestMove *pointDto `json:"best_move,omitempty"`
+	// ForcedWin is the solver result for the side to move
+	ForcedWin *forcedWinDto `json:"forced_win,omitempty"`
+}
+
+func mapToPoints(points []domain.Point) []pointDto {
+	dto := make([]pointDto, len(points))
+	for i, p := range points {
+		dto[i] = pointDto{Row: p.Row, Col: p.Col}
+	}
+	return dto
+}
+
+func mapToSidePlayer(game *domain.Game, stone domain.Stone) null.Int {
+	if player := game.PlayerOf(stone); player != nil {
+		return null.IntFrom(int64(player.ID))
+	}
+	return null.Int{}
+}
+
+func mapToForcedWin(game *domain.Game, stone domain.Stone, result solver.Result) *forcedWinDto {
+	dto := &forcedWinDto{
+		Outcome: string(result.Outcome),
+		Side:    stone.String(),
+		Player:  mapToSidePlayer(game, stone),
+		Line:    mapToPoints(result.Line),
+	}
+	if result.Outcome == solver.Win {
+		dto.Kind = null.StringFrom(string(result.Kind))
+	}
+	return dto
+}
+
+func mapToAnalysis(game *domain.Game) *analysisDto {
+	dto := &analysisDto{
+		GameID:  int(game.ID),
+		Threats: []threatDto{},
+		Forks:   []forkDto{},
+	}
+
+	for _, stone := range []domain.Stone{domain.Black, domain.White} {
+		for _, threat := range analysis.Threats(game.Board, stone) {
+			dto.Threats = append(dto.Threats, threatDto{
+				Kind:     string(threat.Kind),
+				Side:     stone.String(),
+				Player:   mapToSidePlayer(game, stone),
+				Stones:   mapToPoints(threat.Stones),
+				Gains:    mapToPoints(threat.Gains),
+				Defenses: mapToPoints(threat.Defenses),
+			})
+		}
+
+		for _, fork := range analysis.Forks(game.Board, stone) {
+			dto.Forks = append(dto.Forks, forkDto{
+				Kind:   string(fork.Kind),
+				Side:   stone.String(),
+				Player: mapToSidePlayer(game, stone),
+				Cell:   pointDto{Row: fork.Cell.Row, Col: fork.Cell.Col},
+			})
+		}
+	}
+
 	return dto
 }
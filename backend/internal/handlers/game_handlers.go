@@ -1,31 +1,48 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/moLIart/gomoku-backend/internal/ai"
 	"github.com/moLIart/gomoku-backend/internal/domain"
-	"github.com/moLIart/gomoku-backend/internal/middleware"
+	"github.com/moLIart/gomoku-backend/internal/records"
+	"github.com/moLIart/gomoku-backend/internal/render"
 	"github.com/moLIart/gomoku-backend/internal/repositories"
+	"github.com/moLIart/gomoku-backend/internal/solver"
+	log "github.com/sirupsen/logrus"
 )
 
 // HandleStartGame godoc
 // @Summary      Start a new game
-// @Description  Starts a new Gomoku game with the given board size and type.
+// @Description  Starts a new Gomoku game with the given board size and type. PvA games are played against the AI, which takes white, and are never rated.
 // @Tags         games
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        body  body  startGameRq  true  "Game start request"
 // @Success      200   {object}  gameStateDto
 // @Failure      400   {object}  errorRs
 // @Failure      401   {object}  errorRs
 // @Failure      500   {object}  errorRs
+// @Failure      503   {object}  errorRs
 // @Router       /api/v1/games/ [post]
-func HandleStartGame(uow *repositories.UnitOfWork) http.Handler {
+func HandleStartGame(uow *repositories.UnitOfWork, engine ai.Engine) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
 		var rq startGameRq
 		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
 			http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -37,27 +54,528 @@ func HandleStartGame(uow *repositories.UnitOfWork) http.Handler {
 			return
 		}
 
-		playerName := r.Context().Value(middleware.AuthPlayerNameKey).(string)
-
 		players := uow.GetPlayerRepository()
-		player, err := players.GetByNickname(playerName, r.Context())
+		player, err := players.GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		board, err := domain.NewBoard(rq.Size)
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		game, err := domain.NewGame(domain.GameType(rq.Type), board, player)
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if game.Type == domain.PvA && engine == nil {
+			uow.Complete(nil)
+			writeErrorRs(w, http.StatusServiceUnavailable, errAIUnavailable)
+			return
+		}
+
+		if err := seatAI(game, players, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if rq.Rated != nil {
+			if err := game.SetRated(*rq.Rated); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, gameErrorCode(err), err)
+				return
+			}
+		}
+
+		games := uow.GetGameRepository()
+		if err := games.Save(game, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToGameState(game)); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// listGamesLimit caps the number of games returned by HandleListGames.
+const listGamesLimit = 50
+
+// HandleListGames godoc
+// @Summary      List active games
+// @Description  Returns unfinished games of the player and PvP games waiting for a second player, most recently active first.
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Success      200   {array}   gameSummaryDto
+// @Failure      401   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/games/ [get]
+func HandleListGames(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+		player, err := players.GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		games := uow.GetGameRepository()
+		list, err := games.ListActive(player.ID, listGamesLimit, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dtos := make([]gameSummaryDto, len(list))
+		for i, game := range list {
+			dtos[i] = mapToGameSummary(game)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dtos); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// HandleGetGameState godoc
+// @Summary      Get game state
+// @Description  Returns the current state of the game by its ID.
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        gameId  path  int  true  "Game ID"
+// @Success      200   {object}  gameStateDto
+// @Failure      404   {object}  errorRs
+// @Failure      401   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/games/{gameId} [get]
+func HandleGetGameState(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+		gameId, err := strconv.Atoi(params.ByName("gameId"))
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusNotFound)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		games := uow.GetGameRepository()
+		game, err := games.GetById(int32(gameId), r.Context())
+		if err != nil {
+			if err == domain.ErrGameNotFound {
+				uow.Complete(nil)
+
+				http.Error(w, "Game not found", http.StatusNotFound)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToGameState(game)); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// HandleGetGameAnalysis godoc
+// @Summary      Get game threats
+// @Description  Returns fours, threes and forks of both sides with the cells completing and blocking them, the forced win (VCF or VCT) of the side to move found by the solver, and the move suggested by the AI for the side to move when it is available. Neither the forced win nor the AI move is given while a rated game is in progress. The requests are rate limited per player, the AI search is expensive.
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        gameId  path  int  true  "Game ID"
+// @Success      200   {object}  analysisDto
+// @Failure      404   {object}  errorRs
+// @Failure      401   {object}  errorRs
+// @Failure      429   {object}  errorRs
+// @Header       429   {integer} Retry-After "Seconds to wait before retrying"
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/games/{gameId}/analysis [get]
+func HandleGetGameAnalysis(uow *repositories.UnitOfWork, engine ai.Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+		gameId, err := strconv.Atoi(params.ByName("gameId"))
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusNotFound)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		games := uow.GetGameRepository()
+		game, err := games.GetById(int32(gameId), r.Context())
+		if err != nil {
+			if err == domain.ErrGameNotFound {
+				uow.Complete(nil)
+
+				http.Error(w, "Game not found", http.StatusNotFound)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dto := mapToAnalysis(game)
+		if game.WinnerPlayer == nil && hintsAllowed(game) {
+			side := game.Turn()
+			result := solver.SolveContext(r.Context(), game.Board, side, solver.DefaultBudget)
+			dto.ForcedWin = mapToForcedWin(game, side, result)
+
+			if engine != nil {
+				// The threats are useful on their own, a failing engine
+				// only leaves the suggestion out. The engine solves the
+				// position too, it gets the result.
+				ctx := solver.WithResult(r.Context(), game.Board, side, result)
+				if move, err := engine.BestMove(ctx, game.Board, side); err != nil {
+					log.Warnf("analysis of game %d: %v", game.ID, err)
+				} else {
+					dto.BestMove = &pointDto{Row: move.Row, Col: move.Col}
+				}
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dto); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// hintsAllowed reports whether the analysis may suggest moves, a rated
+// game in progress must be played without help.
+func hintsAllowed(game *domain.Game) bool {
+	return !game.Rated || game.IsFinished()
+}
+
+// HandleExportGame godoc
+// @Summary      Export game record
+// @Description  Returns the game as gomoku SGF (GM[4]), Gomocup PSQ or a plain list of moves in board notation.
+// @Tags         games
+// @Produce      plain
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        gameId  path   int     true   "Game ID"
+// @Param        format  query  string  false  "Record format" Enums(sgf, psq, pos) default(sgf)
+// @Success      200   {string}  string
+// @Failure      400   {object}  errorRs
+// @Failure      404   {object}  errorRs
+// @Failure      401   {object}  errorRs
+// @Failure      409   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/games/{gameId}/export [get]
+func HandleExportGame(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+		gameId, err := strconv.Atoi(params.ByName("gameId"))
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusNotFound)
+			return
+		}
+
+		format := records.Format(r.URL.Query().Get("format"))
+		if format == "" {
+			format = records.SGF
+		}
+
+		if format != records.SGF && format != records.PSQ && format != records.Pos {
+			writeErrorRs(w, http.StatusBadRequest, records.ErrUnknownFormat)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		games := uow.GetGameRepository()
+		game, err := games.GetById(int32(gameId), r.Context())
+		if err != nil {
+			if err == domain.ErrGameNotFound {
+				uow.Complete(nil)
+
+				http.Error(w, "Game not found", http.StatusNotFound)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		rec, err := records.FromGame(game)
+		if err != nil {
+			writeErrorRs(w, http.StatusConflict, err)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := records.Write(&buf, rec, format); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", records.ContentType(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="game-%d.%s"`, game.ID, format))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	})
+}
+
+// HandleGameImageSVG godoc
+// @Summary      Get board image as SVG
+// @Description  Draws the board with coordinates, stones, the last move and the winning line. The image is public so it can be shared by link.
+// @Tags         games
+// @Produce      image/svg+xml
+// @Param        gameId  path   int  true   "Game ID"
+// @Param        ply     query  int  false  "Number of moves of the drawn position, the current position by default"
+// @Success      200   {string}  string
+// @Failure      400   {object}  errorRs
+// @Failure      404   {object}  errorRs
+// @Failure      409   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/games/{gameId}/image.svg [get]
+func HandleGameImageSVG(uow *repositories.UnitOfWork) http.Handler {
+	return handleGameImage(uow, render.WriteSVG, render.SVGContentType)
+}
+
+// HandleGameImagePNG godoc
+// @Summary      Get board image as PNG
+// @Description  Draws the board with coordinates, stones, the last move and the winning line. The image is public so it can be shared by link.
+// @Tags         games
+// @Produce      png
+// @Param        gameId  path   int  true   "Game ID"
+// @Param        ply     query  int  false  "Number of moves of the drawn position, the current position by default"
+// @Success      200   {file}  file
+// @Failure      400   {object}  errorRs
+// @Failure      404   {object}  errorRs
+// @Failure      409   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/games/{gameId}/image.png [get]
+func HandleGameImagePNG(uow *repositories.UnitOfWork) http.Handler {
+	return handleGameImage(uow, render.WritePNG, render.PNGContentType)
+}
+
+func handleGameImage(uow *repositories.UnitOfWork, write func(io.Writer, *render.Scene) error, contentType string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+
+		gameId, err := strconv.Atoi(params.ByName("gameId"))
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusNotFound)
+			return
+		}
+
+		ply := -1
+		if value := r.URL.Query().Get("ply"); value != "" {
+			if ply, err = strconv.Atoi(value); err != nil || ply < 0 {
+				writeErrorRs(w, http.StatusBadRequest, render.ErrPlyOutOfRange)
+				return
+			}
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		games := uow.GetGameRepository()
+		game, err := games.GetById(int32(gameId), r.Context())
+		if err != nil {
+			if err == domain.ErrGameNotFound {
+				uow.Complete(nil)
+
+				http.Error(w, "Game not found", http.StatusNotFound)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		scene, err := render.SceneOf(game, ply)
+		if err != nil {
+			switch err {
+			case render.ErrPlyOutOfRange:
+				writeErrorRs(w, http.StatusBadRequest, err)
+			case render.ErrNoHistory:
+				writeErrorRs(w, http.StatusConflict, err)
+			default:
+				writeErrorRs(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := write(&buf, scene); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	})
+}
+
+// HandleImportGame godoc
+// @Summary      Import a game record
+// @Description  Creates a game from a gomoku SGF, Gomocup PSQ or board notation record. Every move is replayed through the game rules, SGF setup stones (AB, AW, AE) are refused. The game is an unrated analysis board where the caller plays both sides, or a PvA game continuing from the final position with black to move.
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        body  body  importGameRq  true  "Game import request, format is sgf, psq or pos and mode is analysis or pva"
+// @Success      200   {object}  gameStateDto
+// @Failure      400   {object}  errorRs
+// @Failure      401   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Failure      503   {object}  errorRs
+// @Router       /api/v1/games/import [post]
+func HandleImportGame(uow *repositories.UnitOfWork, engine ai.Engine) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq importGameRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		mode := domain.GameType(rq.Mode)
+		if mode == "" {
+			mode = domain.Analysis
+		}
+
+		if mode != domain.Analysis && mode != domain.PvA {
+			writeErrorRs(w, http.StatusBadRequest, domain.ErrInvalidGameType)
+			return
+		}
+
+		if mode == domain.PvA && engine == nil {
+			writeErrorRs(w, http.StatusServiceUnavailable, errAIUnavailable)
+			return
+		}
+
+		if rq.Size == 0 {
+			rq.Size = 15
+		}
+
+		rec, err := records.Read(strings.NewReader(rq.Record), records.Format(rq.Format), rq.Size)
+		if err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+		player, err := players.GetById(auth.ID, r.Context())
 		if err != nil {
 			err = uow.Complete(err)
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		board, err := domain.NewBoard(rq.Size)
+		game, err := rec.NewGame(mode, player)
 		if err != nil {
 			err = uow.Complete(err)
 			writeErrorRs(w, http.StatusBadRequest, err)
 			return
 		}
 
-		game, err := domain.NewGame(domain.GameType(rq.Type), board, player)
-		if err != nil {
+		if err := seatAI(game, players, r.Context()); err != nil {
 			err = uow.Complete(err)
-			writeErrorRs(w, http.StatusBadRequest, err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
 
@@ -81,22 +599,28 @@ func HandleStartGame(uow *repositories.UnitOfWork) http.Handler {
 	})
 }
 
-// HandleGetGameState godoc
-// @Summary      Get game state
-// @Description  Returns the current state of the game by its ID.
+// HandleGameJoin godoc
+// @Summary      Join a game
+// @Description  Join an existing Gomoku game by its ID. A player and their own bots can't play each other.
 // @Tags         games
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        gameId  path  int  true  "Game ID"
 // @Success      200   {object}  gameStateDto
+// @Failure      400   {object}  errorRs
 // @Failure      404   {object}  errorRs
 // @Failure      401   {object}  errorRs
 // @Failure      500   {object}  errorRs
-// @Router       /api/v1/games/{gameId} [get]
-func HandleGetGameState(uow *repositories.UnitOfWork) http.Handler {
+// @Router       /api/v1/games/{gameId}/join [put]
+func HandleGameJoin(uow *repositories.UnitOfWork) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
 
 		gameId, err := strconv.Atoi(params.ByName("gameId"))
 		if err != nil {
@@ -109,6 +633,14 @@ func HandleGetGameState(uow *repositories.UnitOfWork) http.Handler {
 			return
 		}
 
+		player, err := uow.GetPlayerRepository().
+			GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
 		games := uow.GetGameRepository()
 		game, err := games.GetById(int32(gameId), r.Context())
 		if err != nil {
@@ -124,6 +656,18 @@ func HandleGetGameState(uow *repositories.UnitOfWork) http.Handler {
 			return
 		}
 
+		if err := game.Join(player); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, gameErrorCode(err), err)
+			return
+		}
+
+		if err := games.Save(game, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
 		if err := uow.Complete(nil); err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
@@ -137,24 +681,31 @@ func HandleGetGameState(uow *repositories.UnitOfWork) http.Handler {
 	})
 }
 
-// HandleGameJoin godoc
-// @Summary      Join a game
-// @Description  Join an existing Gomoku game by its ID.
+// HandleGameMove godoc
+// @Summary      Make a move
+// @Description  Make a move in the game by its ID. In PvA games the AI answers in the same request, if it fails the move is not played.
 // @Tags         games
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        gameId  path  int  true  "Game ID"
+// @Param        body    body  moveGameRq  true  "Move request"
 // @Success      200   {object}  gameStateDto
 // @Failure      400   {object}  errorRs
 // @Failure      404   {object}  errorRs
 // @Failure      401   {object}  errorRs
+// @Failure      409   {object}  errorRs
 // @Failure      500   {object}  errorRs
-// @Router       /api/v1/games/{gameId}/join [put]
-func HandleGameJoin(uow *repositories.UnitOfWork) http.Handler {
+// @Failure      503   {object}  errorRs
+// @Router       /api/v1/games/{gameId}/move [put]
+func HandleGameMove(uow *repositories.UnitOfWork, engine ai.Engine) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
-		playerName := r.Context().Value(middleware.AuthPlayerNameKey).(string)
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
 
 		gameId, err := strconv.Atoi(params.ByName("gameId"))
 		if err != nil {
@@ -167,8 +718,9 @@ func HandleGameJoin(uow *repositories.UnitOfWork) http.Handler {
 			return
 		}
 
-		player, err := uow.GetPlayerRepository().
-			GetByNickname(playerName, r.Context())
+		players := uow.GetPlayerRepository()
+
+		player, err := players.GetById(auth.ID, r.Context())
 		if err != nil {
 			err = uow.Complete(err)
 			writeErrorRs(w, http.StatusInternalServerError, err)
@@ -190,12 +742,84 @@ func HandleGameJoin(uow *repositories.UnitOfWork) http.Handler {
 			return
 		}
 
-		if err := game.Join(player); err != nil {
+		var move moveGameRq
+		if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// PvA games started before the AI had an account wait for it
+		if err := seatAI(game, players, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := game.Move(move.Row, move.Col, player); err != nil {
 			err = uow.Complete(err)
 			writeErrorRs(w, http.StatusBadRequest, err)
 			return
 		}
 
+		if aiToMove(game) {
+			// The move is only checked here, it is played again with the
+			// answer of the AI once the AI has chosen it
+			if err := uow.Complete(nil); err != nil {
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			answer, err := chooseAIMove(game, engine, r.Context())
+			if err != nil {
+				writeErrorRs(w, http.StatusServiceUnavailable, err)
+				return
+			}
+
+			position := game.Board.Hash()
+
+			if err := uow.Begin(r.Context()); err != nil {
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			players = uow.GetPlayerRepository()
+			games = uow.GetGameRepository()
+
+			game, err = games.GetById(int32(gameId), r.Context())
+			if err == nil {
+				err = seatAI(game, players, r.Context())
+			}
+			if err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			// Another request may have moved while the AI was thinking
+			if err := game.Move(move.Row, move.Col, player); err != nil || game.Board.Hash() != position {
+				uow.Complete(nil)
+
+				writeErrorRs(w, http.StatusConflict, errGameChanged)
+				return
+			}
+
+			if err := game.Move(answer.Row, answer.Col, game.Players[1]); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		if ok, winner := game.HasWinner(); ok && game.Rated {
+			winner.AddScore()
+
+			if err := players.Save(winner, r.Context()); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
 		if err := games.Save(game, r.Context()); err != nil {
 			err = uow.Complete(err)
 			writeErrorRs(w, http.StatusInternalServerError, err)
@@ -215,25 +839,28 @@ func HandleGameJoin(uow *repositories.UnitOfWork) http.Handler {
 	})
 }
 
-// HandleGameMove godoc
-// @Summary      Make a move
-// @Description  Make a move in the game by its ID.
+// HandleGameUndoRequest godoc
+// @Summary      Request a takeback
+// @Description  Asks the opponent to take back the last move of the player. Allowed only in unrated games, the AI always accepts.
 // @Tags         games
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Param        gameId  path  int  true  "Game ID"
-// @Param        body    body  moveGameRq  true  "Move request"
 // @Success      200   {object}  gameStateDto
 // @Failure      400   {object}  errorRs
 // @Failure      404   {object}  errorRs
 // @Failure      401   {object}  errorRs
 // @Failure      500   {object}  errorRs
-// @Router       /api/v1/games/{gameId}/move [put]
-func HandleGameMove(uow *repositories.UnitOfWork) http.Handler {
+// @Router       /api/v1/games/{gameId}/undo [put]
+func HandleGameUndoRequest(uow *repositories.UnitOfWork) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
-		playerName := r.Context().Value(middleware.AuthPlayerNameKey).(string)
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
 
 		gameId, err := strconv.Atoi(params.ByName("gameId"))
 		if err != nil {
@@ -246,9 +873,8 @@ func HandleGameMove(uow *repositories.UnitOfWork) http.Handler {
 			return
 		}
 
-		players := uow.GetPlayerRepository()
-
-		player, err := players.GetByNickname(playerName, r.Context())
+		player, err := uow.GetPlayerRepository().
+			GetById(auth.ID, r.Context())
 		if err != nil {
 			err = uow.Complete(err)
 			writeErrorRs(w, http.StatusInternalServerError, err)
@@ -270,26 +896,107 @@ func HandleGameMove(uow *repositories.UnitOfWork) http.Handler {
 			return
 		}
 
-		var move moveGameRq
-		if err := json.NewDecoder(r.Body).Decode(&move); err != nil {
+		if err := game.RequestUndo(player); err != nil {
+			err = uow.Complete(err)
 			writeErrorRs(w, http.StatusBadRequest, err)
 			return
 		}
 
-		if err := game.Move(move.Row, move.Col, player); err != nil {
+		if game.Type == domain.PvA {
+			if err := game.RespondUndo(game.Players[1], true); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		if err := games.Save(game, r.Context()); err != nil {
 			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToGameState(game)); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// HandleGameUndoRespond godoc
+// @Summary      Respond to a takeback request
+// @Description  Accepts or declines the takeback requested by the opponent.
+// @Tags         games
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Param        gameId  path  int  true  "Game ID"
+// @Param        body    body  undoRespondRq  true  "Takeback response"
+// @Success      200   {object}  gameStateDto
+// @Failure      400   {object}  errorRs
+// @Failure      404   {object}  errorRs
+// @Failure      401   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/games/{gameId}/undo/respond [put]
+func HandleGameUndoRespond(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		gameId, err := strconv.Atoi(params.ByName("gameId"))
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusNotFound)
+			return
+		}
+
+		var rq undoRespondRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
 			writeErrorRs(w, http.StatusBadRequest, err)
 			return
 		}
 
-		if ok, winner := game.HasWinner(); ok {
-			winner.AddScore()
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
 
-			if err := players.Save(winner, r.Context()); err != nil {
-				err = uow.Complete(err)
-				writeErrorRs(w, http.StatusInternalServerError, err)
+		player, err := uow.GetPlayerRepository().
+			GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		games := uow.GetGameRepository()
+		game, err := games.GetById(int32(gameId), r.Context())
+		if err != nil {
+			if err == domain.ErrGameNotFound {
+				uow.Complete(nil)
+
+				http.Error(w, "Game not found", http.StatusNotFound)
 				return
 			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := game.RespondUndo(player, rq.Accept); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
 		}
 
 		if err := games.Save(game, r.Context()); err != nil {
@@ -310,3 +1017,50 @@ func HandleGameMove(uow *repositories.UnitOfWork) http.Handler {
 		}
 	})
 }
+
+var (
+	errAIUnavailable = errors.New("AI opponent is unavailable")
+	errGameChanged   = errors.New("game changed during the move, try again")
+)
+
+// seatAI joins the AI to a PvA game as the second player.
+func seatAI(game *domain.Game, players *repositories.PlayerRepository, ctx context.Context) error {
+	if game.Type != domain.PvA || game.Players[1] != nil {
+		return nil
+	}
+
+	player, err := players.GetByNickname(ai.PlayerNickname, ctx)
+	if err != nil {
+		return err
+	}
+
+	return game.Join(player)
+}
+
+// gameErrorCode returns the status of a rule violation, guests are
+// forbidden the rated games.
+func gameErrorCode(err error) int {
+	if errors.Is(err, domain.ErrGuestRatedGame) {
+		return http.StatusForbidden
+	}
+	return http.StatusBadRequest
+}
+
+// aiToMove reports whether the AI is to move in a PvA game.
+func aiToMove(game *domain.Game) bool {
+	return game.Type == domain.PvA && !game.IsFinished() && game.CurrentPlayer.Equal(game.Players[1])
+}
+
+// chooseAIMove asks the engine for the move of the AI. The search may take
+// the whole turn time, it runs outside of the transaction.
+func chooseAIMove(game *domain.Game, engine ai.Engine, ctx context.Context) (domain.Point, error) {
+	if engine == nil {
+		return domain.Point{}, errAIUnavailable
+	}
+
+	move, err := engine.BestMove(ctx, game.Board, game.Turn())
+	if err != nil {
+		return domain.Point{}, fmt.Errorf("%w: %v", errAIUnavailable, err)
+	}
+	return move, nil
+}
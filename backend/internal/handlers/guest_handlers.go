@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/repositories"
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+type guestRs struct {
+	Nickname     string `json:"nickname"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type upgradeGuestRq struct {
+	Nickname string `json:"nickname"`
+	Password string `json:"password"`
+}
+
+type upgradeGuestRs struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// HandleCreateGuest godoc
+//
+// @Summary      Play as a guest
+// @Description  Creates a guest player with a generated nickname and returns its tokens. Guests play PvA and unrated PvP games; a guest inactive for long is removed unless upgraded to a full account.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  guestRs
+// @Failure      429  {object}  errorRs
+// @Header       429  {integer} Retry-After "Seconds to wait before retrying"
+// @Failure      500  {object}  errorRs
+// @Router       /api/v1/guest [post]
+func HandleCreateGuest(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 48 random bits, a clash is not worth a retry
+		tag := make([]byte, 6)
+		if _, err := rand.Read(tag); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player := domain.NewGuest(hex.EncodeToString(tag))
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.GetPlayerRepository().Save(player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		refreshToken, err := issueRefreshToken(uow, jwtSvc, player.ID, "", r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		tokenString, err := jwtSvc.Sign(player)
+		if err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(&guestRs{Nickname: player.Nickname, Token: tokenString, RefreshToken: refreshToken}); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleUpgradeGuest godoc
+//
+// @Summary      Upgrade a guest to a full account
+// @Description  Gives the guest a nickname and a password, following the registration rules, the nicknames of the admins are reserved. The games of the guest are kept. The guest tokens are revoked, the response carries the tokens of the full account.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        upgradeGuestRq  body      upgradeGuestRq  true  "Nickname and password"
+// @Success      200             {object}  upgradeGuestRs
+// @Failure      400             {object}  errorRs
+// @Failure      401             {object}  errorRs
+// @Failure      403             {object}  errorRs
+// @Failure      409             {object}  errorRs
+// @Failure      500             {object}  errorRs
+// @Router       /api/v1/guest/upgrade [post]
+func HandleUpgradeGuest(uow *repositories.UnitOfWork, jwtSvc *services.JWTService, revocations *services.RevocationService, reserved []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq upgradeGuestRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		player, err := players.GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := player.Upgrade(rq.Nickname, rq.Password); err != nil {
+			uow.Complete(nil)
+
+			if errors.Is(err, domain.ErrNotGuest) {
+				writeErrorRs(w, http.StatusForbidden, err)
+				return
+			}
+
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// Taking the free nickname of an admin would grant its rights
+		if slices.Contains(reserved, player.Nickname) {
+			uow.Complete(nil)
+			writeErrorRs(w, http.StatusConflict, domain.ErrNicknameReserved)
+			return
+		}
+
+		if err := players.Upgrade(player, r.Context()); err != nil {
+			if errors.Is(err, domain.ErrPlayerAlreadyExists) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusConflict, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := revokeSessions(uow, player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		refreshToken, err := issueRefreshToken(uow, jwtSvc, player.ID, "", r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		revocations.SetGeneration(player.ID, player.TokenGeneration)
+
+		tokenString, err := jwtSvc.Sign(player)
+		if err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(&upgradeGuestRs{Token: tokenString, RefreshToken: refreshToken}); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
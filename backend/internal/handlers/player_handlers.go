@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/moLIart/gomoku-backend/internal/domain"
 	"github.com/moLIart/gomoku-backend/internal/repositories"
 	"github.com/moLIart/gomoku-backend/internal/services"
+	log "github.com/sirupsen/logrus"
 )
 
 type registerRq struct {
@@ -16,7 +21,8 @@ type registerRq struct {
 }
 
 type registerRs struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type loginRq struct {
@@ -25,13 +31,28 @@ type loginRq struct {
 }
 
 type loginRs struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type refreshTokenRq struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type refreshTokenRs struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type logoutRq struct {
+	// RefreshToken of the session, revoked with its family when given
+	RefreshToken string `json:"refresh_token"`
 }
 
 // HandleRegister регистрирует нового пользователя.
 //
 // @Summary      Register new player
-// @Description  Creates a new player and returns a JWT token
+// @Description  Creates a new player and returns a JWT access token with a refresh token. The nicknames of the admins are reserved.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -39,9 +60,11 @@ type loginRs struct {
 // @Success      200         {object}  registerRs
 // @Failure      400         {object}  errorRs
 // @Failure      409         {object}  errorRs
+// @Failure      429         {object}  errorRs
+// @Header       429         {integer} Retry-After "Seconds to wait before retrying"
 // @Failure      500         {object}  errorRs
 // @Router       /api/v1/register [post]
-func HandleRegister(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) http.Handler {
+func HandleRegister(uow *repositories.UnitOfWork, jwtSvc *services.JWTService, reserved []string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var rq registerRq
 		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
@@ -55,6 +78,12 @@ func HandleRegister(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) h
 			return
 		}
 
+		// Taking the free nickname of an admin would grant its rights
+		if slices.Contains(reserved, player.Nickname) {
+			writeErrorRs(w, http.StatusConflict, domain.ErrNicknameReserved)
+			return
+		}
+
 		if err := uow.Begin(r.Context()); err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
@@ -73,19 +102,26 @@ func HandleRegister(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) h
 			return
 		}
 
+		refreshToken, err := issueRefreshToken(uow, jwtSvc, player.ID, "", r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
 		if err := uow.Complete(nil); err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		tokenString, err := jwtSvc.Sign(player.Nickname)
+		tokenString, err := jwtSvc.Sign(player)
 		if err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(&registerRs{Token: tokenString}); err != nil {
+		if err := json.NewEncoder(w).Encode(&registerRs{Token: tokenString, RefreshToken: refreshToken}); err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
@@ -95,7 +131,7 @@ func HandleRegister(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) h
 // HandleLogin аутентифицирует пользователя.
 //
 // @Summary      Login player
-// @Description  Authenticates player and returns a JWT token
+// @Description  Authenticates player and returns a JWT access token with a refresh token. Logins are rate limited per address and nickname, repeated failures lock the client out for a growing while. Banned players get 403.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -103,6 +139,9 @@ func HandleRegister(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) h
 // @Success      200      {object}  loginRs
 // @Failure      400      {object}  errorRs
 // @Failure      401      {object}  errorRs
+// @Failure      403      {object}  errorRs
+// @Failure      429      {object}  errorRs
+// @Header       429      {integer} Retry-After "Seconds to wait before retrying"
 // @Failure      500      {object}  errorRs
 // @Router       /api/v1/login [post]
 func HandleLogin(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) http.Handler {
@@ -133,24 +172,344 @@ func HandleLogin(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) http
 			return
 		}
 
+		if err := player.CheckPassword(rq.Password); err != nil {
+			uow.Complete(nil)
+
+			if errors.Is(err, domain.ErrPlayerBanned) {
+				writeErrorRs(w, http.StatusForbidden, err)
+				return
+			}
+
+			writeErrorRs(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if err := repository.Touch(player.ID, time.Now(), r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		refreshToken, err := issueRefreshToken(uow, jwtSvc, player.ID, "", r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		tokenString, err := jwtSvc.Sign(player)
+		if err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(&loginRs{Token: tokenString, RefreshToken: refreshToken}); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+	})
+}
+
+// HandleRefreshToken godoc
+//
+// @Summary      Refresh access token
+// @Description  Exchanges a refresh token for a new access token and a new refresh token. Every refresh token works once: presenting a token which was already exchanged revokes every refresh token issued since the login it comes from. Deleted and banned players can't refresh.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        refreshTokenRq  body      refreshTokenRq  true  "Refresh token"
+// @Success      200             {object}  refreshTokenRs
+// @Failure      400             {object}  errorRs
+// @Failure      401             {object}  errorRs
+// @Failure      500             {object}  errorRs
+// @Router       /api/v1/token/refresh [post]
+func HandleRefreshToken(uow *repositories.UnitOfWork, jwtSvc *services.JWTService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rq refreshTokenRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		tokens := uow.GetRefreshTokenRepository()
+
+		token, err := tokens.GetByHash(services.HashRefreshToken(rq.RefreshToken), r.Context())
+		if err != nil {
+			if errors.Is(err, domain.ErrRefreshTokenInvalid) {
+				uow.Complete(nil)
+				writeErrorRs(w, http.StatusUnauthorized, err)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		now := time.Now()
+		if err := token.Use(now); err != nil {
+			if errors.Is(err, domain.ErrRefreshTokenReused) {
+				log.Warnf("Refresh token of player %d reused, revoking its family", token.PlayerID)
+
+				if err := tokens.RevokeFamily(token.Family, now, r.Context()); err != nil {
+					err = uow.Complete(err)
+					writeErrorRs(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+
+			if err := uow.Complete(nil); err != nil {
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			writeErrorRs(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if err := tokens.Save(token, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		player, err := players.GetById(token.PlayerID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Deleted and banned players keep no session, e.g. a removed guest
+		if player.IsDeleted() || player.IsBanned() {
+			if err := tokens.RevokeFamily(token.Family, now, r.Context()); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			if err := uow.Complete(nil); err != nil {
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			if player.IsBanned() {
+				writeErrorRs(w, http.StatusUnauthorized, domain.ErrPlayerBanned)
+				return
+			}
+			writeErrorRs(w, http.StatusUnauthorized, domain.ErrRefreshTokenInvalid)
+			return
+		}
+
+		// Inactive guests are removed, refreshing the token is activity
+		if err := players.Touch(player.ID, now, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		refreshToken, err := issueRefreshToken(uow, jwtSvc, player.ID, token.Family, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
 		if err := uow.Complete(nil); err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
 
-		if player.Password != rq.Password {
-			writeErrorRs(w, http.StatusUnauthorized, errors.New("invalid credentials"))
+		tokenString, err := jwtSvc.Sign(player)
+		if err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(&refreshTokenRs{Token: tokenString, RefreshToken: refreshToken}); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
+	})
+}
+
+// HandleLogout godoc
+//
+// @Summary      Log out
+// @Description  Revokes the access token of the request. The refresh token of the session, when given, is revoked along with every token rotated from the same login.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        logoutRq  body  logoutRq  false  "Refresh token of the session"
+// @Success      204
+// @Failure      400  {object}  errorRs
+// @Failure      401  {object}  errorRs
+// @Failure      500  {object}  errorRs
+// @Router       /api/v1/logout [post]
+func HandleLogout(uow *repositories.UnitOfWork, revocations *services.RevocationService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq logoutRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil && !errors.Is(err, io.EOF) {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if rq.RefreshToken != "" {
+			if err := revokeRefreshTokenFamily(uow, auth.ID, rq.RefreshToken, r.Context()); err != nil {
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		if err := revocations.Revoke(auth.Token, r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
 
-		tokenString, err := jwtSvc.Sign(player.Nickname)
+// HandleLogoutAll godoc
+//
+// @Summary      Log out of all sessions
+// @Description  Revokes every access token and refresh token of the player, on all devices.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      204
+// @Failure      401  {object}  errorRs
+// @Failure      500  {object}  errorRs
+// @Router       /api/v1/logout/all [post]
+func HandleLogoutAll(uow *repositories.UnitOfWork, revocations *services.RevocationService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		players := uow.GetPlayerRepository()
+
+		player, err := players.GetById(auth.ID, r.Context())
 		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := revokeSessions(uow, player, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
 
+		revocations.SetGeneration(player.ID, player.TokenGeneration)
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// revokeSessions bumps the token generation of the player and revokes its
+// refresh tokens in the current transaction. The caller tells the
+// revocation service about the new generation after the commit.
+func revokeSessions(uow *repositories.UnitOfWork, player *domain.Player, ctx context.Context) error {
+	if err := uow.GetPlayerRepository().BumpTokenGeneration(player, ctx); err != nil {
+		return err
+	}
+
+	return uow.GetRefreshTokenRepository().RevokePlayer(player.ID, time.Now(), ctx)
+}
+
+// revokeRefreshTokenFamily revokes the family of the refresh token if it
+// belongs to the player. Unknown tokens are ignored, there's nothing left
+// to revoke.
+func revokeRefreshTokenFamily(uow *repositories.UnitOfWork, playerID int32, refreshToken string, ctx context.Context) error {
+	if err := uow.Begin(ctx); err != nil {
+		return err
+	}
+
+	tokens := uow.GetRefreshTokenRepository()
+
+	token, err := tokens.GetByHash(services.HashRefreshToken(refreshToken), ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenInvalid) {
+			return uow.Complete(nil)
+		}
+
+		return uow.Complete(err)
+	}
+
+	if token.PlayerID == playerID {
+		if err := tokens.RevokeFamily(token.Family, time.Now(), ctx); err != nil {
+			return uow.Complete(err)
+		}
+	}
+
+	return uow.Complete(nil)
+}
+
+// issueRefreshToken stores a new refresh token of the player in the
+// current transaction, an empty family starts a new one.
+func issueRefreshToken(uow *repositories.UnitOfWork, jwtSvc *services.JWTService, playerID int32, family string, ctx context.Context) (string, error) {
+	token, rt, err := jwtSvc.NewRefreshToken(playerID, family)
+	if err != nil {
+		return "", err
+	}
+
+	if err := uow.GetRefreshTokenRepository().Save(rt, ctx); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// HandleJWKS godoc
+//
+// @Summary      Token verification keys
+// @Description  Returns the public keys the access tokens are signed with, selected by the kid header of a token. Empty when the server signs with a shared HMAC secret.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  services.JWKSet
+// @Router       /.well-known/jwks.json [get]
+func HandleJWKS(jwtSvc *services.JWTService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Other services cache the keys, a rotation adds the new key
+		// before it signs
+		w.Header().Set("Cache-Control", "public, max-age=300")
+
 		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(&loginRs{Token: tokenString}); err != nil {
+		if err := json.NewEncoder(w).Encode(jwtSvc.JWKS()); err != nil {
 			writeErrorRs(w, http.StatusInternalServerError, err)
 			return
 		}
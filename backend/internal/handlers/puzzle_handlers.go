@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/puzzles"
+	"github.com/moLIart/gomoku-backend/internal/records"
+	"github.com/moLIart/gomoku-backend/internal/repositories"
+	"github.com/moLIart/gomoku-backend/internal/solver"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+)
+
+type puzzleDto struct {
+	ID   int    `json:"id"`
+	Size int    `json:"size"`
+	Side string `json:"side"`
+	// Board holds the stone of every cell, black, white or null
+	Board      [][]null.String `json:"board"`
+	Difficulty int             `json:"difficulty"`
+	Rating     int             `json:"rating"`
+	// Solutions are only shown to admins and after an attempt
+	Solutions [][]pointDto `json:"solutions,omitempty"`
+}
+
+type dailyPuzzleDto struct {
+	Date string `json:"date"`
+	puzzleDto
+}
+
+type puzzleAttemptRq struct {
+	// Moves alternate the side to move and the opponent, the last one
+	// completes five
+	Moves []pointDto `json:"moves"`
+}
+
+type puzzleAttemptRs struct {
+	Solved bool `json:"solved"`
+	// Rated is false for repeated attempts, which leave the rating as is
+	Rated     bool         `json:"rated"`
+	Rating    int          `json:"rating"`
+	Solutions [][]pointDto `json:"solutions"`
+}
+
+type puzzleRatingDto struct {
+	Rating   int `json:"rating"`
+	Attempts int `json:"attempts"`
+	Solved   int `json:"solved"`
+}
+
+type importPuzzleRq struct {
+	Format string `json:"format"`
+	Record string `json:"record"`
+	Size   int    `json:"board_size,omitempty"`
+}
+
+func mapToPuzzle(puzzle *domain.Puzzle, withSolutions bool) puzzleDto {
+	dto := puzzleDto{
+		ID:         int(puzzle.ID),
+		Size:       puzzle.Board.Size,
+		Side:       puzzle.Side.String(),
+		Difficulty: puzzle.Difficulty,
+		Rating:     puzzle.Rating,
+	}
+
+	dto.Board = make([][]null.String, dto.Size)
+	for i := 0; i < dto.Size; i++ {
+		dto.Board[i] = make([]null.String, dto.Size)
+		for j := 0; j < dto.Size; j++ {
+			if stone := puzzle.Board.At(i, j); stone != domain.Empty {
+				dto.Board[i][j] = null.StringFrom(stone.String())
+			}
+		}
+	}
+
+	if withSolutions {
+		dto.Solutions = mapToSolutions(puzzle)
+	}
+
+	return dto
+}
+
+func mapToSolutions(puzzle *domain.Puzzle) [][]pointDto {
+	solutions := make([][]pointDto, len(puzzle.Solutions))
+	for i, line := range puzzle.Solutions {
+		solutions[i] = mapToPoints(line)
+	}
+	return solutions
+}
+
+// HandleGetDailyPuzzle godoc
+// @Summary      Get the daily puzzle
+// @Description  Returns the puzzle of the current UTC day: a position where the side to move has a forced win. The first request of a day picks a puzzle which wasn't the daily puzzle before.
+// @Tags         puzzles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  dailyPuzzleDto
+// @Failure      401   {object}  errorRs
+// @Failure      404   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/puzzles/daily [get]
+func HandleGetDailyPuzzle(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		day := time.Now().UTC()
+
+		puzzle, err := uow.GetPuzzleRepository().GetDaily(day, r.Context())
+		if err != nil {
+			if err == domain.ErrPuzzleNotFound {
+				uow.Complete(nil)
+
+				http.Error(w, "No puzzles yet", http.StatusNotFound)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dto := dailyPuzzleDto{Date: day.Format(time.DateOnly), puzzleDto: mapToPuzzle(puzzle, false)}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dto); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// HandleAttemptPuzzle godoc
+// @Summary      Attempt a puzzle
+// @Description  Checks a move sequence solving the puzzle: moves of the side to move and of the opponent alternating, up to the move completing five. Any sequence where every move of the side to move keeps a forced win against the replies played, each a defense to the move before it, solves the puzzle, not only the stored solutions. An attempt has at most 41 moves. The first attempt of the player at the puzzle changes the puzzle rating of the player, later ones are only checked. The solutions are returned either way.
+// @Tags         puzzles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        puzzleId  path  int              true  "Puzzle ID"
+// @Param        body      body  puzzleAttemptRq  true  "Move sequence"
+// @Success      200   {object}  puzzleAttemptRs
+// @Failure      400   {object}  errorRs
+// @Failure      401   {object}  errorRs
+// @Failure      404   {object}  errorRs
+// @Failure      429   {object}  errorRs
+// @Header       429   {integer} Retry-After "Seconds to wait before retrying"
+// @Failure      500   {object}  errorRs
+// @Failure      503   {object}  errorRs
+// @Router       /api/v1/puzzles/{puzzleId}/attempt [post]
+func HandleAttemptPuzzle(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(httprouter.ParamsKey).(httprouter.Params)
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		puzzleId, err := strconv.Atoi(params.ByName("puzzleId"))
+		if err != nil {
+			http.Error(w, "Invalid puzzle ID", http.StatusNotFound)
+			return
+		}
+
+		var rq puzzleAttemptRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if len(rq.Moves) > puzzles.MaxAttemptMoves {
+			writeErrorRs(w, http.StatusBadRequest, puzzles.ErrAttemptTooLong)
+			return
+		}
+
+		moves := make([]domain.Point, len(rq.Moves))
+		for i, m := range rq.Moves {
+			moves[i] = domain.Point{Row: m.Row, Col: m.Col}
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player, err := uow.GetPlayerRepository().GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		puzzleRepo := uow.GetPuzzleRepository()
+		puzzle, err := puzzleRepo.GetById(int32(puzzleId), r.Context())
+		if err != nil {
+			if err == domain.ErrPuzzleNotFound {
+				uow.Complete(nil)
+
+				http.Error(w, "Puzzle not found", http.StatusNotFound)
+				return
+			}
+
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// The solver checks the attempt outside of the transaction
+		solved, err := puzzles.Check(r.Context(), puzzle, moves, solver.DefaultBudget)
+		if err != nil {
+			writeErrorRs(w, http.StatusServiceUnavailable, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// The repository is bound to the transaction it was got in
+		puzzleRepo = uow.GetPuzzleRepository()
+
+		first, err := puzzleRepo.AddAttempt(player.ID, puzzle.ID, solved, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		rating, err := puzzleRepo.GetRating(player.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if first {
+			rating.Record(puzzle, solved)
+
+			if err := puzzleRepo.SaveRating(rating, r.Context()); err != nil {
+				err = uow.Complete(err)
+				writeErrorRs(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		rs := puzzleAttemptRs{
+			Solved:    solved,
+			Rated:     first,
+			Rating:    rating.Rating,
+			Solutions: mapToSolutions(puzzle),
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(rs); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// HandleGetPuzzleRating godoc
+// @Summary      Get puzzle rating
+// @Description  Returns the puzzle rating of the player with the number of rated attempts and solved puzzles.
+// @Tags         puzzles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200   {object}  puzzleRatingDto
+// @Failure      401   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/puzzles/rating [get]
+func HandleGetPuzzleRating(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		player, err := uow.GetPlayerRepository().GetById(auth.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		rating, err := uow.GetPuzzleRepository().GetRating(player.ID, r.Context())
+		if err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		dto := puzzleRatingDto{Rating: rating.Rating, Attempts: rating.Attempts, Solved: rating.Solved}
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(dto); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+// HandleImportPuzzle godoc
+// @Summary      Import a puzzle
+// @Description  Creates a puzzle from the final position of a gomoku SGF, Gomocup PSQ or board notation record. The solver has to find a forced win of the side to move, which becomes the solution.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body  importPuzzleRq  true  "Puzzle import request, format is sgf, psq or pos"
+// @Success      200   {object}  puzzleDto
+// @Failure      400   {object}  errorRs
+// @Failure      401   {object}  errorRs
+// @Failure      403   {object}  errorRs
+// @Failure      422   {object}  errorRs
+// @Failure      500   {object}  errorRs
+// @Router       /api/v1/admin/puzzles/import [post]
+func HandleImportPuzzle(uow *repositories.UnitOfWork) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, ok := authPlayer(w, r)
+		if !ok {
+			return
+		}
+
+		var rq importPuzzleRq
+		if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if rq.Size == 0 {
+			rq.Size = 15
+		}
+
+		rec, err := records.Read(strings.NewReader(rq.Record), records.Format(rq.Format), rq.Size)
+		if err != nil {
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		puzzle, err := puzzles.FromRecord(rec, puzzles.ImportBudget)
+		if err != nil {
+			if errors.Is(err, puzzles.ErrNoForcedWin) {
+				writeErrorRs(w, http.StatusUnprocessableEntity, err)
+				return
+			}
+
+			writeErrorRs(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := uow.Begin(r.Context()); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.GetPuzzleRepository().Save(puzzle, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		details := map[string]any{"format": rq.Format, "difficulty": puzzle.Difficulty}
+		if err := audit(uow, auth, domain.AuditImportPuzzle, domain.AuditTargetPuzzle, puzzle.ID, details, r.Context()); err != nil {
+			err = uow.Complete(err)
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := uow.Complete(nil); err != nil {
+			writeErrorRs(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		log.Infof("Puzzle %d imported, difficulty %d", puzzle.ID, puzzle.Difficulty)
+
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(mapToPuzzle(puzzle, true)); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	})
+}
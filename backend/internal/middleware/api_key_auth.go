@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+// APIKeyHeader carries the API key of a bot.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuth authenticates the bots by the API key of the request, the key
+// must have the scope. The requests without a key are passed to fallback,
+// usually JWTAuth, so that players and bots share the routes.
+func APIKeyAuth(apiKeys *services.APIKeyService, scope string, fallback func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fallbackHandler := fallback(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				fallbackHandler.ServeHTTP(w, r)
+				return
+			}
+
+			_, bot, err := apiKeys.Authenticate(key, scope, r.Context())
+			if err != nil {
+				switch {
+				case errors.Is(err, domain.ErrAPIKeyInvalid):
+					http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				case errors.Is(err, domain.ErrAPIKeyScope):
+					http.Error(w, "API key lacks the "+scope+" scope", http.StatusForbidden)
+				default:
+					log.Errorf("Could not check API key: %s", err)
+					http.Error(w, "Could not check API key", http.StatusInternalServerError)
+				}
+				return
+			}
+
+			newContext := WithAuthPlayer(r.Context(), &AuthPlayer{
+				ID:       bot.ID,
+				Nickname: bot.Nickname,
+				Bot:      true,
+				Role:     domain.RolePlayer,
+			})
+			next.ServeHTTP(w, r.WithContext(newContext))
+		})
+	}
+}
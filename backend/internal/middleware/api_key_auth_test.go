@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+type apiKeyStore map[string]*domain.APIKey
+
+func (s apiKeyStore) GetAPIKeyByHash(hash string, ctx context.Context) (*domain.APIKey, *domain.Player, error) {
+	key, ok := s[hash]
+	if !ok {
+		return nil, nil, domain.ErrAPIKeyNotFound
+	}
+	return key, &domain.Player{Entity: domain.Entity{ID: key.PlayerID}, Nickname: "AliceBot", Bot: true}, nil
+}
+
+func (s apiKeyStore) TouchAPIKey(id int32, at time.Time, ctx context.Context) error {
+	return nil
+}
+
+func newAPIKey(t *testing.T, scopes ...string) (*services.APIKeyService, string) {
+	t.Helper()
+
+	store := apiKeyStore{}
+	apiKeys := services.NewAPIKeyService(store)
+
+	key, record, err := apiKeys.NewAPIKey(&domain.Player{Entity: domain.Entity{ID: 3}, Bot: true}, "ci", scopes)
+	require.NoError(t, err)
+	store[record.Hash] = record
+
+	return apiKeys, key
+}
+
+// rejectAll stands for JWTAuth, the requests without a key go to it
+func rejectAll(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+	})
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	apiKeys, key := newAPIKey(t, domain.ScopeRead)
+
+	for name, tc := range map[string]struct {
+		key   string
+		scope string
+		code  int
+	}{
+		"valid":    {key, domain.ScopeRead, http.StatusOK},
+		"no scope": {key, domain.ScopePlay, http.StatusForbidden},
+		"invalid":  {services.APIKeyPrefix + "0000", domain.ScopeRead, http.StatusUnauthorized},
+		"no key":   {"", domain.ScopeRead, http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := APIKeyAuth(apiKeys, tc.scope, rejectAll)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				player, ok := AuthPlayerFrom(r.Context())
+				require.True(t, ok)
+				assert.Equal(t, int32(3), player.ID)
+				assert.True(t, player.Bot)
+				assert.Nil(t, player.Token)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.key != "" {
+				req.Header.Set(APIKeyHeader, tc.key)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, tc.code, rr.Code)
+		})
+	}
+}
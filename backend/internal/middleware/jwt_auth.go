@@ -2,19 +2,47 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
+	log "github.com/sirupsen/logrus"
 
+	"github.com/moLIart/gomoku-backend/internal/domain"
 	"github.com/moLIart/gomoku-backend/internal/services"
 )
 
-type authPlayerNameKey struct{}
+type authPlayerKey struct{}
 
-var AuthPlayerNameKey = authPlayerNameKey{}
+// AuthPlayer is the player authenticated by the request token.
+type AuthPlayer struct {
+	ID int32
+	// Nickname at the time the token was signed, it may have changed since
+	Nickname string
+	Guest    bool
+	// Bot players authenticate with an API key
+	Bot bool
+	// Role at the time the token was signed, a role change revokes the
+	// tokens of the player
+	Role domain.Role
+	// Token are the claims of the request token, logout revokes it. Nil
+	// for the bots
+	Token *services.TokenClaims
+}
+
+// WithAuthPlayer returns a copy of the context holding the player.
+func WithAuthPlayer(ctx context.Context, player *AuthPlayer) context.Context {
+	return context.WithValue(ctx, authPlayerKey{}, player)
+}
 
-func JWTAuth(jwtSvc *services.JWTService) func(http.Handler) http.Handler {
+// AuthPlayerFrom returns the player authenticated by JWTAuth, false if the
+// request didn't pass it.
+func AuthPlayerFrom(ctx context.Context) (*AuthPlayer, bool) {
+	player, ok := ctx.Value(authPlayerKey{}).(*AuthPlayer)
+	return player, ok && player != nil
+}
+
+func JWTAuth(jwtSvc *services.JWTService, revocations *services.RevocationService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -31,20 +59,30 @@ func JWTAuth(jwtSvc *services.JWTService) func(http.Handler) http.Handler {
 				return
 			}
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
+			claims, err := jwtSvc.Claims(token)
+			if err != nil {
 				http.Error(w, "Invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			val, ok := claims["name"]
-			if !ok {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+			if err := revocations.Check(claims, r.Context()); err != nil {
+				if errors.Is(err, services.ErrTokenRevoked) {
+					http.Error(w, "Token was revoked", http.StatusUnauthorized)
+					return
+				}
+
+				log.Errorf("Could not check token revocation: %s", err)
+				http.Error(w, "Could not check token", http.StatusInternalServerError)
 				return
 			}
 
-			newContext := context.
-				WithValue(r.Context(), AuthPlayerNameKey, val)
+			newContext := WithAuthPlayer(r.Context(), &AuthPlayer{
+				ID:       claims.PlayerID,
+				Nickname: claims.Name,
+				Guest:    claims.Guest,
+				Role:     claims.Role,
+				Token:    claims,
+			})
 			next.ServeHTTP(w, r.WithContext(newContext))
 		})
 	}
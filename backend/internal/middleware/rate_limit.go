@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+// maxKeyBodySize bounds the body read to find the nickname, the handler
+// still gets the whole body.
+const maxKeyBodySize = 64 << 10
+
+// KeyFunc returns the key the request is limited by, empty to let it
+// through.
+type KeyFunc func(r *http.Request) string
+
+// ByIP keys the requests by the client address. Behind a reverse proxy
+// the address is the last one of X-Forwarded-For, the one the proxy saw;
+// the earlier ones are up to the client.
+func ByIP(trustForwarded bool) KeyFunc {
+	return func(r *http.Request) string {
+		if trustForwarded {
+			if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+				addrs := strings.Split(forwarded, ",")
+				return strings.TrimSpace(addrs[len(addrs)-1])
+			}
+		}
+
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return host
+	}
+}
+
+// ByNickname keys the requests by the nickname of their JSON body, like
+// the login and register requests.
+func ByNickname(r *http.Request) string {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxKeyBodySize))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), r.Body))
+	if err != nil {
+		return ""
+	}
+
+	var rq struct {
+		Nickname string `json:"nickname"`
+	}
+	if err := json.Unmarshal(data, &rq); err != nil {
+		return ""
+	}
+	return rq.Nickname
+}
+
+// ByPlayer keys the requests by the authenticated player, it must follow
+// JWTAuth or APIKeyAuth.
+func ByPlayer(r *http.Request) string {
+	player, ok := AuthPlayerFrom(r.Context())
+	if !ok {
+		return ""
+	}
+	return strconv.FormatInt(int64(player.ID), 10)
+}
+
+// Keys keys the requests by all of the keys, empty if one of them is.
+func Keys(keys ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			if parts[i] = key(r); parts[i] == "" {
+				return ""
+			}
+		}
+		return strings.Join(parts, "|")
+	}
+}
+
+// RateLimit rejects the requests over the limit of their key with 429.
+func RateLimit(limiter *services.RateLimiter, limit services.RateLimit, key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			retryAfter, err := limiter.Allow(limit, k, r.Context())
+			if err != nil {
+				// A broken store must not take the logins down with it
+				log.Errorf("Could not check rate limit: %s", err)
+			} else if retryAfter > 0 {
+				tooManyRequests(w, retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LoginLockout locks the key out after repeated failed logins, the ones
+// answered with 401, and forgets the failures after a successful one.
+func LoginLockout(limiter *services.RateLimiter, key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			locked, err := limiter.Locked(k, r.Context())
+			if err != nil {
+				log.Errorf("Could not check login lockout: %s", err)
+			} else if locked > 0 {
+				tooManyRequests(w, locked)
+				return
+			}
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			switch recorder.status {
+			case http.StatusUnauthorized:
+				err = limiter.Fail(k, r.Context())
+			case http.StatusOK:
+				err = limiter.Succeed(k, r.Context())
+			}
+			if err != nil {
+				log.Errorf("Could not record login attempt: %s", err)
+			}
+		})
+	}
+}
+
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, "Too many requests", http.StatusTooManyRequests)
+}
+
+// statusRecorder remembers the status code the handler answered with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
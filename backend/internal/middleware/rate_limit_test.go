@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/moLIart/gomoku-backend/internal/services"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := services.NewRateLimiter(services.NewMemoryRateLimitStore(), services.DefaultLockout)
+	limit := services.RateLimit{Name: "test", Burst: 2, Every: time.Minute}
+
+	handler := RateLimit(limiter, limit, ByIP(false))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, code := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "1.2.3.4:5678"
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, code, rr.Code)
+
+		if code == http.StatusTooManyRequests {
+			assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestByIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = "10.0.0.1:5678"
+	req.Header.Set("X-Forwarded-For", "6.6.6.6, 1.2.3.4")
+
+	assert.Equal(t, "10.0.0.1", ByIP(false)(req))
+	assert.Equal(t, "1.2.3.4", ByIP(true)(req))
+}
+
+func TestByNickname(t *testing.T) {
+	body := `{"nickname":"alice","password":"secret"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	assert.Equal(t, "alice", ByNickname(req))
+
+	// The handler still reads the whole body
+	data, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("not json"))
+	assert.Equal(t, "", ByNickname(req))
+}
+
+func TestByPlayer(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	assert.Equal(t, "", ByPlayer(req))
+
+	req = req.WithContext(WithAuthPlayer(req.Context(), &AuthPlayer{ID: 7, Nickname: "alice"}))
+	assert.Equal(t, "7", ByPlayer(req))
+}
+
+func TestLoginLockout(t *testing.T) {
+	limiter := services.NewRateLimiter(services.NewMemoryRateLimitStore(), services.DefaultLockout)
+
+	handler := LoginLockout(limiter, ByNickname)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(readBody(t, r), `"password":"right"`) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	login := func(nickname, password string) *httptest.ResponseRecorder {
+		body := `{"nickname":"` + nickname + `","password":"` + password + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	for range services.DefaultLockout.Threshold {
+		assert.Equal(t, http.StatusUnauthorized, login("alice", "guess").Code)
+	}
+
+	rr := login("alice", "right")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, login("bob", "right").Code)
+}
+
+func readBody(t *testing.T, r *http.Request) string {
+	t.Helper()
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return string(data)
+}
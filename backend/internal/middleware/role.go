@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// RequireRole lets through the players with the role or a higher one, it
+// must follow JWTAuth. The role comes from the token, changing a role
+// revokes the tokens of the player.
+func RequireRole(role domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			player, ok := AuthPlayerFrom(r.Context())
+			if !ok || player.Bot || !player.Role.AtLeast(role) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+func TestRequireRole(t *testing.T) {
+	for name, tc := range map[string]struct {
+		player *AuthPlayer
+		code   int
+	}{
+		"admin":     {&AuthPlayer{ID: 1, Nickname: "root", Role: domain.RoleAdmin}, http.StatusOK},
+		"moderator": {&AuthPlayer{ID: 2, Nickname: "mod", Role: domain.RoleModerator}, http.StatusOK},
+		"player":    {&AuthPlayer{ID: 3, Nickname: "alice", Role: domain.RolePlayer}, http.StatusForbidden},
+		"no role":   {&AuthPlayer{ID: 4, Nickname: "bob"}, http.StatusForbidden},
+		"bot":       {&AuthPlayer{ID: 5, Nickname: "botty", Bot: true, Role: domain.RoleAdmin}, http.StatusForbidden},
+		"anonymous": {nil, http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler := RequireRole(domain.RoleModerator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.player != nil {
+				req = req.WithContext(WithAuthPlayer(req.Context(), tc.player))
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+			assert.Equal(t, tc.code, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package puzzles turns game records into puzzles: the final position of
+// a record becomes a puzzle when the side to move has a forced win.
+package puzzles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/records"
+	"github.com/moLIart/gomoku-backend/internal/solver"
+)
+
+// ImportBudget is the solver node budget of an imported position. Imports
+// aren't interactive, the search may take longer than in the analysis.
+const ImportBudget = 20 * solver.DefaultBudget
+
+var ErrNoForcedWin = errors.New("the side to move has no forced win")
+
+// FromRecord replays the record and solves its final position for the
+// side to move, the winning line found becomes the solution.
+func FromRecord(rec *records.Record, budget int) (*domain.Puzzle, error) {
+	// Analysis games check the moves of both sides for a single owner
+	game, err := rec.NewGame(domain.Analysis, &domain.Player{})
+	if err != nil {
+		return nil, err
+	}
+	if game.WinnerPlayer != nil {
+		return nil, domain.ErrGameFinished
+	}
+
+	side := game.Turn()
+	result := solver.Solve(game.Board, side, budget)
+	if result.Outcome != solver.Win {
+		return nil, fmt.Errorf("%w (%s after %d nodes)", ErrNoForcedWin, result.Outcome, result.Nodes)
+	}
+
+	return domain.NewPuzzle(game.Board, side, [][]domain.Point{result.Line})
+}
+
+// MaxAttemptMoves bounds the moves of an attempt, both sides together. The
+// solver finds no longer wins.
+const MaxAttemptMoves = 41
+
+var ErrAttemptTooLong = fmt.Errorf("an attempt has at most %d moves", MaxAttemptMoves)
+
+// Check reports whether the attempt solves the puzzle. Following a solution
+// solves it, other attempts are replayed: every move of the puzzle side has
+// to keep a forced win against the replies played, and every reply has to
+// be one of the defenses the solver considers to the move before it.
+// It fails with the error of the context when the context is done first.
+func Check(ctx context.Context, puzzle *domain.Puzzle, moves []domain.Point, budget int) (bool, error) {
+	if len(moves) > MaxAttemptMoves {
+		return false, ErrAttemptTooLong
+	}
+	if puzzle.Check(moves) {
+		return true, nil
+	}
+	if len(moves)%2 == 0 {
+		return false, nil
+	}
+
+	board := puzzle.Board.Clone()
+	stone := puzzle.Side
+	var defenses []domain.Point
+	for i, m := range moves {
+		if stone == puzzle.Side {
+			result := solver.SolveMove(ctx, board, stone, m, budget)
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			if result.Outcome != solver.Win {
+				return false, nil
+			}
+		} else if !slices.Contains(defenses, m) {
+			return false, nil
+		}
+
+		if err := board.Put(m.Row, m.Col, stone); err != nil {
+			return false, nil
+		}
+		if board.CheckWin(m.Row, m.Col, stone, domain.WinLength) != (i == len(moves)-1) {
+			return false, nil
+		}
+		if stone == puzzle.Side {
+			defenses = solver.Defenses(board, stone, m)
+		}
+		stone = stone.Opponent()
+	}
+	return true, nil
+}
@@ -0,0 +1,104 @@
+package puzzles_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/puzzles"
+	"github.com/moLIart/gomoku-backend/internal/records"
+	"github.com/moLIart/gomoku-backend/internal/solver"
+)
+
+func record(moves ...domain.Point) *records.Record {
+	return &records.Record{Size: 15, Moves: moves}
+}
+
+func TestFromRecord(t *testing.T) {
+	// Black has an open three on row 7 and is to move
+	rec := record(
+		domain.Point{Row: 7, Col: 5}, domain.Point{Row: 0, Col: 0},
+		domain.Point{Row: 7, Col: 6}, domain.Point{Row: 0, Col: 14},
+		domain.Point{Row: 7, Col: 7}, domain.Point{Row: 14, Col: 14},
+	)
+
+	puzzle, err := puzzles.FromRecord(rec, solver.DefaultBudget)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Black, puzzle.Side)
+	assert.Equal(t, 2, puzzle.Difficulty)
+	require.Len(t, puzzle.Solutions, 1)
+	assert.True(t, puzzle.Check(puzzle.Solutions[0]))
+	assert.Equal(t, 6, puzzle.Board.Count(domain.Black)+puzzle.Board.Count(domain.White))
+}
+
+func TestFromRecord_NoForcedWin(t *testing.T) {
+	rec := record(domain.Point{Row: 7, Col: 7}, domain.Point{Row: 7, Col: 8})
+
+	_, err := puzzles.FromRecord(rec, solver.DefaultBudget)
+	assert.ErrorIs(t, err, puzzles.ErrNoForcedWin)
+}
+
+func TestFromRecord_FinishedGame(t *testing.T) {
+	var moves []domain.Point
+	for col := 0; col < 5; col++ {
+		moves = append(moves, domain.Point{Row: 7, Col: col}, domain.Point{Row: 8, Col: col})
+	}
+
+	_, err := puzzles.FromRecord(record(moves[:9]...), solver.DefaultBudget)
+	assert.ErrorIs(t, err, domain.ErrGameFinished)
+}
+
+func TestCheck(t *testing.T) {
+	// Black has an open three on row 7 and is to move
+	rec := record(
+		domain.Point{Row: 7, Col: 5}, domain.Point{Row: 0, Col: 0},
+		domain.Point{Row: 7, Col: 6}, domain.Point{Row: 0, Col: 14},
+		domain.Point{Row: 7, Col: 7}, domain.Point{Row: 14, Col: 14},
+	)
+	puzzle, err := puzzles.FromRecord(rec, solver.DefaultBudget)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		moves  []domain.Point
+		solved bool
+	}{
+		{"solution", puzzle.Solutions[0], true},
+		{"other side of the three", []domain.Point{{Row: 7, Col: 4}, {Row: 7, Col: 3}, {Row: 7, Col: 8}}, true},
+		{"other block of the four", []domain.Point{{Row: 7, Col: 8}, {Row: 7, Col: 9}, {Row: 7, Col: 4}}, true},
+		{"reply not blocking", []domain.Point{{Row: 7, Col: 8}, {Row: 1, Col: 1}, {Row: 7, Col: 9}}, false},
+		{"no forced win", []domain.Point{{Row: 8, Col: 8}, {Row: 1, Col: 1}, {Row: 7, Col: 8}, {Row: 1, Col: 2}, {Row: 7, Col: 9}}, false},
+		{"five not reached", []domain.Point{{Row: 7, Col: 8}}, false},
+		{"ends with a reply", []domain.Point{{Row: 7, Col: 8}, {Row: 7, Col: 9}}, false},
+		{"occupied cell", []domain.Point{{Row: 7, Col: 7}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			solved, err := puzzles.Check(context.Background(), puzzle, tt.moves, solver.DefaultBudget)
+			require.NoError(t, err)
+			assert.Equal(t, tt.solved, solved)
+		})
+	}
+}
+
+func TestCheck_Limits(t *testing.T) {
+	rec := record(
+		domain.Point{Row: 7, Col: 5}, domain.Point{Row: 0, Col: 0},
+		domain.Point{Row: 7, Col: 6}, domain.Point{Row: 0, Col: 14},
+		domain.Point{Row: 7, Col: 7}, domain.Point{Row: 14, Col: 14},
+	)
+	puzzle, err := puzzles.FromRecord(rec, solver.DefaultBudget)
+	require.NoError(t, err)
+
+	_, err = puzzles.Check(context.Background(), puzzle, make([]domain.Point, puzzles.MaxAttemptMoves+1), solver.DefaultBudget)
+	assert.ErrorIs(t, err, puzzles.ErrAttemptTooLong)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = puzzles.Check(ctx, puzzle, []domain.Point{{Row: 7, Col: 8}, {Row: 7, Col: 9}, {Row: 7, Col: 4}}, solver.DefaultBudget)
+	assert.ErrorIs(t, err, context.Canceled)
+}
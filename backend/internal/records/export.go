@@ -0,0 +1,96 @@
+package records
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// Write encodes the record in the given format.
+func Write(w io.Writer, rec *Record, format Format) error {
+	switch format {
+	case SGF:
+		return WriteSGF(w, rec)
+	case PSQ:
+		return WritePSQ(w, rec)
+	case Pos:
+		return WritePos(w, rec)
+	}
+	return ErrUnknownFormat
+}
+
+// ContentType returns the MIME type of the format.
+func ContentType(format Format) string {
+	if format == SGF {
+		return "application/x-go-sgf"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// sgfLetters are SGF coordinates, top left cell is "aa".
+const sgfLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+var sgfEscaper = strings.NewReplacer(`\`, `\\`, `]`, `\]`)
+
+// WriteSGF writes the record as a gomoku (GM[4]) SGF game tree.
+func WriteSGF(w io.Writer, rec *Record) error {
+	if rec.Size > len(sgfLetters) {
+		return fmt.Errorf("board size %d is too large for SGF", rec.Size)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "(;GM[4]FF[4]CA[UTF-8]AP[gomoku-api]SZ[%d]", rec.Size)
+	fmt.Fprintf(bw, "PB[%s]PW[%s]", sgfEscaper.Replace(rec.Black), sgfEscaper.Replace(rec.White))
+
+	switch rec.Winner {
+	case domain.Black:
+		bw.WriteString("RE[B+]")
+	case domain.White:
+		bw.WriteString("RE[W+]")
+	}
+
+	for i, m := range rec.Moves {
+		color := "B"
+		if i%2 == 1 {
+			color = "W"
+		}
+		fmt.Fprintf(bw, "\n;%s[%c%c]", color, sgfLetters[m.Col], sgfLetters[m.Row])
+	}
+
+	bw.WriteString(")\n")
+	return bw.Flush()
+}
+
+// WritePSQ writes the record in the Piskvork format: a header, one
+// "x,y,time" line per move with 1-based coordinates, then player names.
+func WritePSQ(w io.Writer, rec *Record) error {
+	bw := bufio.NewWriter(w)
+	center := rec.Size/2 + 1
+	fmt.Fprintf(bw, "Piskvorky %dx%d, %d:%d, 0\n", rec.Size, rec.Size, center, center)
+
+	for _, m := range rec.Moves {
+		fmt.Fprintf(bw, "%d,%d,0\n", m.Col+1, m.Row+1)
+	}
+
+	fmt.Fprintf(bw, "-1\n%s\n%s\n-1\n", rec.Black, rec.White)
+	return bw.Flush()
+}
+
+// WritePos writes one move per line in board notation, e.g. "h8".
+func WritePos(w io.Writer, rec *Record) error {
+	bw := bufio.NewWriter(w)
+
+	for _, m := range rec.Moves {
+		coord, err := FormatCoord(rec.Size, m)
+		if err != nil {
+			return err
+		}
+		bw.WriteString(coord)
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
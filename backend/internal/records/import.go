@@ -0,0 +1,252 @@
+package records
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+var (
+	ErrInvalidRecord  = errors.New("invalid game record")
+	ErrPvABlackToMove = errors.New("black must be to move to play the position against the AI")
+)
+
+// Read decodes a record in the given format. Plain coordinate lists carry
+// no board size, size is used for them.
+func Read(r io.Reader, format Format, size int) (*Record, error) {
+	switch format {
+	case SGF:
+		return ReadSGF(r)
+	case PSQ:
+		return ReadPSQ(r)
+	case Pos:
+		return ReadPos(r, size)
+	}
+	return nil, ErrUnknownFormat
+}
+
+func invalidRecord(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRecord, fmt.Sprintf(format, args...))
+}
+
+// ReadSGF decodes the main line of a gomoku SGF game tree, variations
+// are skipped. Setup stones are refused.
+func ReadSGF(r io.Reader) (*Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &Record{Size: 15}
+	src := string(data)
+
+	start := strings.IndexByte(src, '(')
+	if start < 0 {
+		return nil, invalidRecord("missing game tree")
+	}
+
+	var ident string
+	for i := start + 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == ')':
+			// The first closed variation ends the main line
+			return rec, nil
+		case c >= 'A' && c <= 'Z':
+			if src[i-1] < 'A' || src[i-1] > 'Z' {
+				ident = ""
+			}
+			ident += string(c)
+		case c == '[':
+			value, end, err := sgfValue(src, i+1)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+			if err := rec.sgfProperty(ident, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, invalidRecord("unterminated game tree")
+}
+
+// sgfValue reads an escaped property value up to the closing bracket.
+func sgfValue(src string, i int) (string, int, error) {
+	var sb strings.Builder
+	for ; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+			if i < len(src) {
+				sb.WriteByte(src[i])
+			}
+		case ']':
+			return sb.String(), i, nil
+		default:
+			sb.WriteByte(src[i])
+		}
+	}
+	return "", i, invalidRecord("unterminated property value")
+}
+
+func (rec *Record) sgfProperty(ident, value string) error {
+	switch ident {
+	case "GM":
+		if value != "4" {
+			return invalidRecord("game type GM[%s] is not gomoku", value)
+		}
+	case "SZ":
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 3 || size > len(sgfLetters) {
+			return invalidRecord("invalid board size SZ[%s]", value)
+		}
+		if len(rec.Moves) > 0 {
+			return invalidRecord("board size after moves")
+		}
+		rec.Size = size
+	case "AB", "AW", "AE":
+		// A record is a move list, stones set up on the board don't fit it
+		return invalidRecord("setup stones are not supported")
+	case "PB":
+		rec.Black = value
+	case "PW":
+		rec.White = value
+	case "B", "W":
+		if want := "BW"[len(rec.Moves)%2]; ident[0] != want {
+			return invalidRecord("move %d is played by %s, expected %c", len(rec.Moves)+1, ident, want)
+		}
+
+		if len(value) != 2 {
+			return invalidRecord("invalid move %s[%s]", ident, value)
+		}
+
+		col, row := strings.IndexByte(sgfLetters, value[0]), strings.IndexByte(sgfLetters, value[1])
+		if col < 0 || row < 0 || col >= rec.Size || row >= rec.Size {
+			return invalidRecord("invalid move %s[%s]", ident, value)
+		}
+
+		rec.Moves = append(rec.Moves, domain.Point{Row: row, Col: col})
+	}
+	return nil
+}
+
+// ReadPSQ decodes a Piskvork record, moves end at the first line which
+// isn't a move.
+func ReadPSQ(r io.Reader) (*Record, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, invalidRecord("missing header")
+	}
+
+	// Piskvorky 15x15, 8:8, 0
+	var width, height int
+	header := strings.TrimSpace(scanner.Text())
+	if _, err := fmt.Sscanf(header, "Piskvorky %dx%d,", &width, &height); err != nil {
+		return nil, invalidRecord("invalid header %q", header)
+	}
+
+	if width != height || width < 3 || width > domain.MaxBoardSize {
+		return nil, invalidRecord("unsupported board %dx%d", width, height)
+	}
+
+	rec := &Record{Size: width}
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if len(fields) < 2 {
+			break
+		}
+
+		x, errX := strconv.Atoi(fields[0])
+		y, errY := strconv.Atoi(fields[1])
+		if errX != nil || errY != nil || x < 1 || y < 1 {
+			break
+		}
+
+		if x > rec.Size || y > rec.Size {
+			return nil, invalidRecord("move %d,%d is out of the board", x, y)
+		}
+
+		rec.Moves = append(rec.Moves, domain.Point{Row: y - 1, Col: x - 1})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// ReadPos decodes moves in board notation separated by white space.
+func ReadPos(r io.Reader, size int) (*Record, error) {
+	if size < 3 || size > domain.MaxBoardSize {
+		return nil, invalidRecord("invalid board size %d", size)
+	}
+
+	rec := &Record{Size: size}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		p, err := ParseCoord(size, scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		rec.Moves = append(rec.Moves, p)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return rec, nil
+}
+
+// NewGame replays the record through domain.Game.Move, so every move is
+// checked by the game rules, and returns a game of the given type owned by
+// the player and set up at the final position. Analysis games belong to
+// the owner on both sides, against the AI the owner plays black.
+func (rec *Record) NewGame(gtype domain.GameType, owner *domain.Player) (*domain.Game, error) {
+	board, err := domain.NewBoard(rec.Size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+
+	replay, err := domain.NewGame(domain.Analysis, board, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range rec.Moves {
+		if err := replay.Move(m.Row, m.Col, owner); err != nil {
+			return nil, fmt.Errorf("%w: move %d: %v", ErrInvalidRecord, i+1, err)
+		}
+	}
+
+	if gtype == domain.Analysis {
+		return replay, nil
+	}
+
+	game, err := domain.NewGame(gtype, board, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if replay.WinnerPlayer != nil {
+		return nil, domain.ErrGameFinished
+	}
+
+	if gtype == domain.PvA && game.Turn() != domain.Black {
+		return nil, ErrPvABlackToMove
+	}
+
+	game.Moves = replay.Moves
+	game.Rated = false
+	return game, nil
+}
@@ -0,0 +1,136 @@
+package records_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/records"
+)
+
+func TestRead_RoundTrip(t *testing.T) {
+	for _, format := range []records.Format{records.SGF, records.PSQ, records.Pos} {
+		var buf bytes.Buffer
+		require.NoError(t, records.Write(&buf, testRecord(), format))
+
+		rec, err := records.Read(&buf, format, 15)
+		require.NoError(t, err, format)
+		assert.Equal(t, 15, rec.Size, format)
+		assert.Equal(t, testRecord().Moves, rec.Moves, format)
+	}
+}
+
+func TestReadSGF(t *testing.T) {
+	src := `(;GM[4]FF[4]SZ[9]PB[al\]ice]PW[bob]C[comment with (parens)]
+		;B[ee];W[fe](;B[ff];W[dd])(;B[aa]))`
+
+	rec, err := records.ReadSGF(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, 9, rec.Size)
+	assert.Equal(t, "al]ice", rec.Black)
+	assert.Equal(t, "bob", rec.White)
+	assert.Equal(t, []domain.Point{{Row: 4, Col: 4}, {Row: 4, Col: 5}, {Row: 5, Col: 5}, {Row: 3, Col: 3}}, rec.Moves)
+}
+
+func TestReadSGF_Invalid(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"(;GM[1]SZ[19];B[aa])",
+		"(;GM[4]SZ[15];W[aa])",
+		"(;GM[4]SZ[15];B[aa];B[bb])",
+		"(;GM[4]SZ[9];B[jj])",
+		"(;GM[4]SZ[15];B[aa]",
+		"(;GM[4]SZ[15];B[aa)",
+		"(;GM[4]SZ[15]AB[hh];W[aa])",
+		"(;GM[4]SZ[15];B[aa];W[bb]AE[aa])",
+	} {
+		_, err := records.ReadSGF(strings.NewReader(src))
+		assert.ErrorIs(t, err, records.ErrInvalidRecord, src)
+	}
+}
+
+func TestReadPSQ(t *testing.T) {
+	src := "Piskvorky 20x20, 11:11, 0\r\n10,10,1200\r\n11,10,800\r\n-1\r\npbrain-a.exe\r\npbrain-b.exe\r\n-1\r\n"
+
+	rec, err := records.ReadPSQ(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, 20, rec.Size)
+	assert.Equal(t, []domain.Point{{Row: 9, Col: 9}, {Row: 9, Col: 10}}, rec.Moves)
+}
+
+func TestReadPSQ_Invalid(t *testing.T) {
+	for _, src := range []string{"", "Gomoku 15x15\n", "Piskvorky 15x20, 1:1, 0\n", "Piskvorky 15x15, 8:8, 0\n16,1,0\n"} {
+		_, err := records.ReadPSQ(strings.NewReader(src))
+		assert.ErrorIs(t, err, records.ErrInvalidRecord, src)
+	}
+}
+
+func TestReadPos_Invalid(t *testing.T) {
+	_, err := records.ReadPos(strings.NewReader("h8 z1"), 15)
+	assert.ErrorIs(t, err, records.ErrInvalidRecord)
+
+	_, err = records.ReadPos(strings.NewReader("h8"), 2)
+	assert.ErrorIs(t, err, records.ErrInvalidRecord)
+}
+
+func TestRecord_NewGame_Analysis(t *testing.T) {
+	owner := &domain.Player{Entity: domain.Entity{ID: 7}, Nickname: "coach"}
+
+	game, err := testRecord().NewGame(domain.Analysis, owner)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Analysis, game.Type)
+	assert.False(t, game.Rated)
+	assert.Equal(t, testRecord().Moves, game.Moves)
+	assert.Equal(t, domain.White, game.Turn())
+	assert.Equal(t, owner, game.Players[0])
+	assert.Equal(t, owner, game.Players[1])
+	assert.Equal(t, owner, game.CurrentPlayer)
+}
+
+func TestRecord_NewGame_PvA(t *testing.T) {
+	owner := &domain.Player{Entity: domain.Entity{ID: 7}, Nickname: "coach"}
+
+	rec := testRecord()
+	rec.Moves = rec.Moves[:2]
+
+	game, err := rec.NewGame(domain.PvA, owner)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PvA, game.Type)
+	assert.Equal(t, owner, game.CurrentPlayer)
+	assert.Len(t, game.Moves, 2)
+
+	_, err = testRecord().NewGame(domain.PvA, owner)
+	assert.ErrorIs(t, err, records.ErrPvABlackToMove)
+}
+
+func TestRecord_NewGame_IllegalMove(t *testing.T) {
+	owner := &domain.Player{Entity: domain.Entity{ID: 7}}
+
+	rec := testRecord()
+	rec.Moves = append(rec.Moves, rec.Moves[0])
+
+	_, err := rec.NewGame(domain.Analysis, owner)
+	assert.ErrorIs(t, err, records.ErrInvalidRecord)
+}
+
+func TestRecord_NewGame_MoveAfterWin(t *testing.T) {
+	owner := &domain.Player{Entity: domain.Entity{ID: 7}}
+
+	rec, err := records.ReadPos(strings.NewReader("a1 a2 b1 b2 c1 c2 d1 d2 e1 e2"), 15)
+	require.NoError(t, err)
+
+	_, err = rec.NewGame(domain.Analysis, owner)
+	assert.ErrorIs(t, err, records.ErrInvalidRecord)
+
+	rec.Moves = rec.Moves[:9]
+	game, err := rec.NewGame(domain.Analysis, owner)
+	require.NoError(t, err)
+	assert.Equal(t, owner, game.WinnerPlayer)
+
+	_, err = rec.NewGame(domain.PvA, owner)
+	assert.ErrorIs(t, err, domain.ErrGameFinished)
+}
@@ -0,0 +1,92 @@
+// Package records converts games to and from the record formats of
+// desktop gomoku tools: SGF, Gomocup PSQ and plain coordinate lists.
+package records
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+type Format string
+
+const (
+	SGF Format = "sgf"
+	PSQ Format = "psq"
+	Pos Format = "pos"
+)
+
+var (
+	ErrUnknownFormat    = errors.New("unknown record format")
+	ErrIncompleteRecord = errors.New("game was started before moves were recorded")
+)
+
+// Record is a game as a sequence of moves, black moves first.
+type Record struct {
+	Size   int
+	Moves  []domain.Point
+	Black  string
+	White  string
+	Winner domain.Stone
+}
+
+// FromGame builds the record of the game. Games stored before move
+// history existed can't be exported.
+func FromGame(game *domain.Game) (*Record, error) {
+	if game.Board.Count(domain.Black)+game.Board.Count(domain.White) != len(game.Moves) {
+		return nil, ErrIncompleteRecord
+	}
+
+	rec := &Record{
+		Size:  game.Board.Size,
+		Moves: game.Moves,
+	}
+
+	if player := game.PlayerOf(domain.Black); player != nil {
+		rec.Black = player.Nickname
+	}
+	if player := game.PlayerOf(domain.White); player != nil {
+		rec.White = player.Nickname
+	}
+	if game.WinnerPlayer != nil {
+		rec.Winner = game.StoneOf(game.WinnerPlayer)
+		if len(game.WinningLine) > 0 {
+			// Both sides of an analysis board belong to the same player
+			w := game.WinningLine[0]
+			rec.Winner = game.Board.At(w.Row, w.Col)
+		}
+	}
+
+	return rec, nil
+}
+
+// FormatCoord returns the cell in board notation: the column letter
+// followed by the row number counted from the bottom, "h8" is the
+// center of a 15x15 board.
+func FormatCoord(size int, p domain.Point) (string, error) {
+	if p.Col < 0 || p.Col >= 26 || p.Row < 0 || p.Row >= size {
+		return "", fmt.Errorf("cell (%d, %d) has no notation on a %dx%d board", p.Row, p.Col, size, size)
+	}
+	return string(rune('a'+p.Col)) + strconv.Itoa(size-p.Row), nil
+}
+
+// ParseCoord parses board notation produced by FormatCoord.
+func ParseCoord(size int, s string) (domain.Point, error) {
+	if len(s) < 2 {
+		return domain.Point{}, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	letter := s[0] | 0x20 // lower case
+	if letter < 'a' || letter > 'z' {
+		return domain.Point{}, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 1 || n > size || int(letter-'a') >= size {
+		return domain.Point{}, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	return domain.Point{Row: size - n, Col: int(letter - 'a')}, nil
+}
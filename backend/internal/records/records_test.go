@@ -0,0 +1,99 @@
+package records_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/records"
+)
+
+func testRecord() *records.Record {
+	return &records.Record{
+		Size:   15,
+		Moves:  []domain.Point{{Row: 7, Col: 7}, {Row: 7, Col: 8}, {Row: 8, Col: 8}},
+		Black:  "alice",
+		White:  "bob]",
+		Winner: domain.Black,
+	}
+}
+
+func TestFormatCoord(t *testing.T) {
+	coord, err := records.FormatCoord(15, domain.Point{Row: 7, Col: 7})
+	require.NoError(t, err)
+	assert.Equal(t, "h8", coord)
+
+	coord, err = records.FormatCoord(15, domain.Point{Row: 0, Col: 0})
+	require.NoError(t, err)
+	assert.Equal(t, "a15", coord)
+
+	_, err = records.FormatCoord(15, domain.Point{Row: 15, Col: 0})
+	assert.Error(t, err)
+}
+
+func TestParseCoord(t *testing.T) {
+	p, err := records.ParseCoord(15, "H8")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, p)
+
+	p, err = records.ParseCoord(15, "o1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 14, Col: 14}, p)
+
+	for _, s := range []string{"", "h", "p1", "a0", "a16", "8h", "h-1"} {
+		_, err := records.ParseCoord(15, s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestFromGame(t *testing.T) {
+	board, _ := domain.NewBoard(15)
+	alice := &domain.Player{Entity: domain.Entity{ID: 1}, Nickname: "alice"}
+	bob := &domain.Player{Entity: domain.Entity{ID: 2}, Nickname: "bob"}
+	game, _ := domain.NewGame(domain.PvP, board, alice)
+	_ = game.Join(bob)
+	_ = game.Move(7, 7, alice)
+	_ = game.Move(7, 8, bob)
+
+	rec, err := records.FromGame(game)
+	require.NoError(t, err)
+	assert.Equal(t, 15, rec.Size)
+	assert.Equal(t, "alice", rec.Black)
+	assert.Equal(t, "bob", rec.White)
+	assert.Equal(t, game.Moves, rec.Moves)
+	assert.Equal(t, domain.Empty, rec.Winner)
+
+	// Stones without recorded moves
+	_ = board.Put(0, 0, domain.Black)
+	_, err = records.FromGame(game)
+	assert.ErrorIs(t, err, records.ErrIncompleteRecord)
+}
+
+func TestWriteSGF(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, records.Write(&buf, testRecord(), records.SGF))
+
+	assert.Equal(t, "(;GM[4]FF[4]CA[UTF-8]AP[gomoku-api]SZ[15]PB[alice]PW[bob\\]]RE[B+]\n;B[hh]\n;W[ih]\n;B[ii])\n", buf.String())
+}
+
+func TestWritePSQ(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, records.Write(&buf, testRecord(), records.PSQ))
+
+	assert.Equal(t, "Piskvorky 15x15, 8:8, 0\n8,8,0\n9,8,0\n9,9,0\n-1\nalice\nbob]\n-1\n", buf.String())
+}
+
+func TestWritePos(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, records.Write(&buf, testRecord(), records.Pos))
+
+	assert.Equal(t, "h8\ni8\ni7\n", buf.String())
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	assert.ErrorIs(t, records.Write(&buf, testRecord(), "txt"), records.ErrUnknownFormat)
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// glyphs is a 3x5 bitmap font for the board coordinates, one row per
+// byte with the leftmost pixel in the highest of three bits.
+var glyphs = map[rune][5]byte{
+	'0': {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1': {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2': {0b111, 0b001, 0b111, 0b100, 0b111},
+	'3': {0b111, 0b001, 0b111, 0b001, 0b111},
+	'4': {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5': {0b111, 0b100, 0b111, 0b001, 0b111},
+	'6': {0b111, 0b100, 0b111, 0b101, 0b111},
+	'7': {0b111, 0b001, 0b001, 0b010, 0b010},
+	'8': {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9': {0b111, 0b101, 0b111, 0b001, 0b111},
+	'A': {0b010, 0b101, 0b111, 0b101, 0b101},
+	'B': {0b110, 0b101, 0b110, 0b101, 0b110},
+	'C': {0b011, 0b100, 0b100, 0b100, 0b011},
+	'D': {0b110, 0b101, 0b101, 0b101, 0b110},
+	'E': {0b111, 0b100, 0b110, 0b100, 0b111},
+	'F': {0b111, 0b100, 0b110, 0b100, 0b100},
+	'G': {0b011, 0b100, 0b101, 0b101, 0b011},
+	'H': {0b101, 0b101, 0b111, 0b101, 0b101},
+	'I': {0b111, 0b010, 0b010, 0b010, 0b111},
+	'J': {0b001, 0b001, 0b001, 0b101, 0b010},
+	'K': {0b101, 0b101, 0b110, 0b101, 0b101},
+	'L': {0b100, 0b100, 0b100, 0b100, 0b111},
+	'M': {0b101, 0b111, 0b111, 0b101, 0b101},
+	'N': {0b110, 0b101, 0b101, 0b101, 0b101},
+	'O': {0b010, 0b101, 0b101, 0b101, 0b010},
+	'P': {0b110, 0b101, 0b110, 0b100, 0b100},
+	'Q': {0b010, 0b101, 0b101, 0b110, 0b011},
+	'R': {0b110, 0b101, 0b110, 0b101, 0b101},
+	'S': {0b011, 0b100, 0b010, 0b001, 0b110},
+	'T': {0b111, 0b010, 0b010, 0b010, 0b010},
+	'U': {0b101, 0b101, 0b101, 0b101, 0b111},
+	'V': {0b101, 0b101, 0b101, 0b101, 0b010},
+	'W': {0b101, 0b101, 0b111, 0b111, 0b101},
+	'X': {0b101, 0b101, 0b010, 0b101, 0b101},
+	'Y': {0b101, 0b101, 0b010, 0b010, 0b010},
+	'Z': {0b111, 0b001, 0b010, 0b100, 0b111},
+}
+
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphScale   = 2
+	glyphAdvance = (glyphWidth + 1) * glyphScale
+)
+
+// drawText draws the text centered at (x, y), unknown runes are skipped.
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	width := len(text)*glyphAdvance - glyphScale
+	left := x - width/2
+	top := y - glyphHeight*glyphScale/2
+
+	for i, r := range text {
+		glyph, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+
+		for gy, bits := range glyph {
+			for gx := 0; gx < glyphWidth; gx++ {
+				if bits&(1<<(glyphWidth-1-gx)) == 0 {
+					continue
+				}
+
+				px := left + i*glyphAdvance + gx*glyphScale
+				py := top + gy*glyphScale
+				fillRect(img, image.Rect(px, py, px+glyphScale, py+glyphScale), c)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// PNGContentType is the MIME type of WritePNG output.
+const PNGContentType = "image/png"
+
+// WritePNG draws the scene as a PNG image.
+func WritePNG(w io.Writer, scene *Scene) error {
+	board := scene.Board
+	size := imageSize(board.Size)
+	last := margin + (board.Size-1)*cellSize
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	fillRect(img, img.Bounds(), boardColor)
+
+	for i := 0; i < board.Size; i++ {
+		pos := margin + i*cellSize
+		fillRect(img, image.Rect(margin, pos, last+1, pos+1), gridColor)
+		fillRect(img, image.Rect(pos, margin, pos+1, last+1), gridColor)
+
+		drawText(img, pos, margin/2, colLabel(i), gridColor)
+		drawText(img, pos, size-margin/2, colLabel(i), gridColor)
+		drawText(img, margin/2, pos, rowLabel(board.Size, i), gridColor)
+		drawText(img, size-margin/2, pos, rowLabel(board.Size, i), gridColor)
+	}
+
+	for _, p := range starPoints(board.Size) {
+		x, y := center(p)
+		fillDisc(img, float64(x)+0.5, float64(y)+0.5, 3, gridColor)
+	}
+
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			x, y := center(domain.Point{Row: row, Col: col})
+			cx, cy := float64(x)+0.5, float64(y)+0.5
+
+			switch board.At(row, col) {
+			case domain.Black:
+				fillDisc(img, cx, cy, stoneRadius, blackColor)
+			case domain.White:
+				fillDisc(img, cx, cy, stoneRadius, gridColor)
+				fillDisc(img, cx, cy, stoneRadius-1.5, whiteColor)
+			}
+		}
+	}
+
+	if len(scene.WinningLine) > 0 {
+		x1, y1 := center(scene.WinningLine[0])
+		x2, y2 := center(scene.WinningLine[len(scene.WinningLine)-1])
+		fillSegment(img, float64(x1)+0.5, float64(y1)+0.5, float64(x2)+0.5, float64(y2)+0.5, lineWidth/2, markerColor)
+	}
+
+	if scene.LastMove != nil {
+		x, y := center(*scene.LastMove)
+		fillDisc(img, float64(x)+0.5, float64(y)+0.5, markRadius, markerColor)
+	}
+
+	return png.Encode(w, img)
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func fillDisc(img *image.RGBA, cx, cy, r float64, c color.RGBA) {
+	bounds := image.Rect(int(cx-r)-1, int(cy-r)-1, int(cx+r)+2, int(cy+r)+2)
+	fillShape(img, bounds, c, func(x, y float64) bool {
+		return (x-cx)*(x-cx)+(y-cy)*(y-cy) <= r*r
+	})
+}
+
+// fillSegment draws a line with round caps, r is half of its width.
+func fillSegment(img *image.RGBA, x1, y1, x2, y2, r float64, c color.RGBA) {
+	bounds := image.Rect(int(math.Min(x1, x2)-r)-1, int(math.Min(y1, y2)-r)-1,
+		int(math.Max(x1, x2)+r)+2, int(math.Max(y1, y2)+r)+2)
+
+	dx, dy := x2-x1, y2-y1
+	length := dx*dx + dy*dy
+	fillShape(img, bounds, c, func(x, y float64) bool {
+		t := 0.0
+		if length > 0 {
+			t = math.Max(0, math.Min(1, ((x-x1)*dx+(y-y1)*dy)/length))
+		}
+		px, py := x1+t*dx-x, y1+t*dy-y
+		return px*px+py*py <= r*r
+	})
+}
+
+// samples is the number of subpixel samples per axis used to smooth the
+// edges of shapes.
+const samples = 4
+
+// fillShape blends the color into every pixel of bounds in proportion to
+// the part of the pixel inside the shape.
+func fillShape(img *image.RGBA, bounds image.Rectangle, c color.RGBA, inside func(x, y float64) bool) {
+	bounds = bounds.Intersect(img.Bounds())
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			covered := 0
+			for sy := 0; sy < samples; sy++ {
+				for sx := 0; sx < samples; sx++ {
+					if inside(float64(x)+(float64(sx)+0.5)/samples, float64(y)+(float64(sy)+0.5)/samples) {
+						covered++
+					}
+				}
+			}
+
+			if covered == 0 {
+				continue
+			}
+
+			a := uint32(covered) * 255 / (samples * samples)
+			dst := img.RGBAAt(x, y)
+			img.SetRGBA(x, y, color.RGBA{
+				R: blend(dst.R, c.R, a),
+				G: blend(dst.G, c.G, a),
+				B: blend(dst.B, c.B, a),
+				A: 0xff,
+			})
+		}
+	}
+}
+
+func blend(dst, src uint8, a uint32) uint8 {
+	return uint8((uint32(dst)*(255-a) + uint32(src)*a) / 255)
+}
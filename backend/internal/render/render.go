@@ -0,0 +1,133 @@
+// Package render draws board positions as SVG and PNG images using only
+// the standard library, so it runs on headless servers.
+package render
+
+import (
+	"errors"
+	"image/color"
+	"strconv"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+var (
+	ErrPlyOutOfRange = errors.New("ply is out of the game range")
+	ErrNoHistory     = errors.New("game was started before moves were recorded")
+)
+
+// Scene is a position with the markers drawn on top of it.
+type Scene struct {
+	Board       *domain.Board
+	LastMove    *domain.Point
+	WinningLine []domain.Point
+}
+
+// SceneOf returns the position of the game after the given number of
+// moves, a negative ply is the current position.
+func SceneOf(game *domain.Game, ply int) (*Scene, error) {
+	if ply < 0 {
+		return currentScene(game), nil
+	}
+
+	if ply > len(game.Moves) {
+		return nil, ErrPlyOutOfRange
+	}
+
+	// Without the history even the ply of the current position is unknown
+	if game.Board.Count(domain.Black)+game.Board.Count(domain.White) != len(game.Moves) {
+		return nil, ErrNoHistory
+	}
+
+	if ply == len(game.Moves) {
+		return currentScene(game), nil
+	}
+
+	board, err := domain.NewBoard(game.Board.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	// The game stops at five in a row, so earlier positions have no winner
+	stone := domain.Black
+	for _, m := range game.Moves[:ply] {
+		if err := board.Put(m.Row, m.Col, stone); err != nil {
+			return nil, err
+		}
+		stone = stone.Opponent()
+	}
+
+	scene := &Scene{Board: board}
+	if ply > 0 {
+		scene.LastMove = &game.Moves[ply-1]
+	}
+	return scene, nil
+}
+
+// currentScene returns the position the game is in, with its markers.
+func currentScene(game *domain.Game) *Scene {
+	scene := &Scene{Board: game.Board, WinningLine: game.WinningLine}
+	if len(game.Moves) > 0 {
+		scene.LastMove = &game.Moves[len(game.Moves)-1]
+	}
+	return scene
+}
+
+// Layout of both image formats, in pixels.
+const (
+	cellSize    = 32
+	margin      = 36
+	stoneRadius = 14
+	markRadius  = 4
+	lineWidth   = 4
+)
+
+var (
+	boardColor  = color.RGBA{0xdc, 0xb3, 0x5c, 0xff}
+	gridColor   = color.RGBA{0x3a, 0x2a, 0x10, 0xff}
+	blackColor  = color.RGBA{0x14, 0x14, 0x14, 0xff}
+	whiteColor  = color.RGBA{0xf8, 0xf8, 0xf4, 0xff}
+	markerColor = color.RGBA{0xe0, 0x30, 0x30, 0xff}
+)
+
+// imageSize returns the width and height of the image of a board.
+func imageSize(size int) int {
+	return 2*margin + (size-1)*cellSize
+}
+
+// center returns the pixel position of a cell.
+func center(p domain.Point) (x, y int) {
+	return margin + p.Col*cellSize, margin + p.Row*cellSize
+}
+
+// colLabel names columns by letters, "A" to "Z" and then "AA", "AB" and
+// so on for large boards.
+func colLabel(col int) string {
+	if col < 26 {
+		return string(rune('A' + col))
+	}
+	return colLabel(col/26-1) + colLabel(col%26)
+}
+
+// rowLabel numbers rows from the bottom as the board notation does.
+func rowLabel(size, row int) string {
+	return strconv.Itoa(size - row)
+}
+
+// starPoints returns the traditional marked cells of the board.
+func starPoints(size int) []domain.Point {
+	if size < 9 {
+		return nil
+	}
+
+	edge := 3
+	if size < 13 {
+		edge = 2
+	}
+
+	far := size - 1 - edge
+	points := []domain.Point{{Row: edge, Col: edge}, {Row: edge, Col: far}, {Row: far, Col: edge}, {Row: far, Col: far}}
+	if size%2 == 1 {
+		points = append(points, domain.Point{Row: size / 2, Col: size / 2})
+	}
+	return points
+}
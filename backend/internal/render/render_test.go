@@ -0,0 +1,126 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+func newGame(t *testing.T, moves ...domain.Point) *domain.Game {
+	t.Helper()
+
+	board, err := domain.NewBoard(15)
+	require.NoError(t, err)
+
+	player := &domain.Player{Entity: domain.Entity{ID: 1}}
+	game, err := domain.NewGame(domain.Analysis, board, player)
+	require.NoError(t, err)
+
+	for _, m := range moves {
+		require.NoError(t, game.Move(m.Row, m.Col, player))
+	}
+	return game
+}
+
+func TestSceneOf_Current(t *testing.T) {
+	game := newGame(t, domain.Point{Row: 7, Col: 7}, domain.Point{Row: 7, Col: 8})
+
+	scene, err := SceneOf(game, -1)
+	require.NoError(t, err)
+	assert.Same(t, game.Board, scene.Board)
+	assert.Equal(t, &domain.Point{Row: 7, Col: 8}, scene.LastMove)
+}
+
+func TestSceneOf_Ply(t *testing.T) {
+	game := newGame(t, domain.Point{Row: 7, Col: 7}, domain.Point{Row: 7, Col: 8}, domain.Point{Row: 8, Col: 8})
+
+	scene, err := SceneOf(game, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Black, scene.Board.At(7, 7))
+	assert.Equal(t, domain.Empty, scene.Board.At(7, 8))
+	assert.Equal(t, &domain.Point{Row: 7, Col: 7}, scene.LastMove)
+
+	scene, err = SceneOf(game, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, scene.Board.Count(domain.Black))
+	assert.Nil(t, scene.LastMove)
+
+	_, err = SceneOf(game, 4)
+	assert.ErrorIs(t, err, ErrPlyOutOfRange)
+}
+
+func TestSceneOf_NoHistory(t *testing.T) {
+	game := newGame(t, domain.Point{Row: 7, Col: 7})
+	require.NoError(t, game.Board.Put(0, 0, domain.White))
+
+	_, err := SceneOf(game, 0)
+	assert.ErrorIs(t, err, ErrNoHistory)
+
+	_, err = SceneOf(game, -1)
+	assert.NoError(t, err)
+
+	// No move at all was recorded, the current position isn't ply 0
+	game = newGame(t)
+	require.NoError(t, game.Board.Put(7, 7, domain.Black))
+
+	_, err = SceneOf(game, 0)
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
+
+func TestWriteSVG(t *testing.T) {
+	game := newGame(t)
+	for i := 0; i < domain.WinLength; i++ {
+		require.NoError(t, game.Move(0, i, game.Players[0]))
+		if i < domain.WinLength-1 {
+			require.NoError(t, game.Move(1, i, game.Players[0]))
+		}
+	}
+
+	scene, err := SceneOf(game, -1)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSVG(&buf, scene))
+
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Equal(t, 9, strings.Count(svg, `r="14"`))
+	assert.Contains(t, svg, `stroke-linecap="round"`)
+	assert.Contains(t, svg, ">O</text>")
+	assert.Contains(t, svg, ">15</text>")
+}
+
+func TestWritePNG(t *testing.T) {
+	game := newGame(t, domain.Point{Row: 7, Col: 7}, domain.Point{Row: 0, Col: 0})
+
+	scene, err := SceneOf(game, 1)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePNG(&buf, scene))
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, imageSize(15), img.Bounds().Dx())
+
+	// Off the last move marker but inside the stone
+	x, y := center(domain.Point{Row: 7, Col: 7})
+	assert.Equal(t, blackColor, img.At(x+stoneRadius/2+2, y))
+
+	// The second move isn't played yet at ply 1
+	x, y = center(domain.Point{Row: 0, Col: 0})
+	assert.NotEqual(t, whiteColor, img.At(x+stoneRadius/2, y+stoneRadius/2))
+}
+
+func TestColLabel(t *testing.T) {
+	assert.Equal(t, "A", colLabel(0))
+	assert.Equal(t, "Z", colLabel(25))
+	assert.Equal(t, "AA", colLabel(26))
+	assert.Equal(t, "BL", colLabel(63))
+}
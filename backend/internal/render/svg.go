@@ -0,0 +1,81 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// SVGContentType is the MIME type of WriteSVG output.
+const SVGContentType = "image/svg+xml"
+
+// WriteSVG draws the scene as an SVG document.
+func WriteSVG(w io.Writer, scene *Scene) error {
+	board := scene.Board
+	size := imageSize(board.Size)
+	last := margin + (board.Size-1)*cellSize
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", size, size, size, size)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", size, size, hex(boardColor))
+
+	fmt.Fprintf(bw, `<g stroke="%s" stroke-width="1">`+"\n", hex(gridColor))
+	for i := 0; i < board.Size; i++ {
+		pos := margin + i*cellSize
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", margin, pos, last, pos)
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", pos, margin, pos, last)
+	}
+	bw.WriteString("</g>\n")
+
+	fmt.Fprintf(bw, `<g fill="%s">`+"\n", hex(gridColor))
+	for _, p := range starPoints(board.Size) {
+		x, y := center(p)
+		fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="3"/>`+"\n", x, y)
+	}
+	bw.WriteString("</g>\n")
+
+	fmt.Fprintf(bw, `<g fill="%s" font-family="sans-serif" font-size="12" text-anchor="middle" dominant-baseline="central">`+"\n", hex(gridColor))
+	for i := 0; i < board.Size; i++ {
+		pos := margin + i*cellSize
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", pos, margin/2, colLabel(i))
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", pos, size-margin/2, colLabel(i))
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", margin/2, pos, rowLabel(board.Size, i))
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", size-margin/2, pos, rowLabel(board.Size, i))
+	}
+	bw.WriteString("</g>\n")
+
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			x, y := center(domain.Point{Row: row, Col: col})
+			switch board.At(row, col) {
+			case domain.Black:
+				fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="%d" fill="%s"/>`+"\n", x, y, stoneRadius, hex(blackColor))
+			case domain.White:
+				fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="%d" fill="%s" stroke="%s" stroke-width="1.5"/>`+"\n",
+					x, y, stoneRadius, hex(whiteColor), hex(gridColor))
+			}
+		}
+	}
+
+	if len(scene.WinningLine) > 0 {
+		x1, y1 := center(scene.WinningLine[0])
+		x2, y2 := center(scene.WinningLine[len(scene.WinningLine)-1])
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="%d" stroke-linecap="round"/>`+"\n",
+			x1, y1, x2, y2, hex(markerColor), lineWidth)
+	}
+
+	if scene.LastMove != nil {
+		x, y := center(*scene.LastMove)
+		fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="%d" fill="%s"/>`+"\n", x, y, markRadius, hex(markerColor))
+	}
+
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
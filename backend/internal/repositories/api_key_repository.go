@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/pkg/errorx"
+)
+
+type APIKeyRepository struct {
+	tx *sqlx.Tx
+}
+
+func NewAPIKeyRepository(tx *sqlx.Tx) *APIKeyRepository {
+	return &APIKeyRepository{
+		tx: tx,
+	}
+}
+
+var (
+	sqlInsertAPIKey = `
+		INSERT INTO api_keys (player_id, name, prefix, key_hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING api_key_id`
+
+	sqlRevokeAPIKey = `
+		UPDATE api_keys
+		SET revoked_at = $1
+		WHERE api_key_id = $2`
+
+	sqlSelectAPIKeys = `
+		SELECT api_key_id, player_id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys`
+
+	sqlGetAPIKeyById = sqlSelectAPIKeys + `
+		WHERE api_key_id = $1`
+
+	sqlListAPIKeys = sqlSelectAPIKeys + `
+		WHERE player_id = $1
+		ORDER BY api_key_id`
+
+	sqlRevokeOwnedAPIKeys = `
+		UPDATE api_keys
+		SET revoked_at = $1
+		WHERE revoked_at IS NULL
+			AND player_id IN (SELECT player_id FROM players WHERE owner_id = $2)`
+)
+
+// Save inserts a new key or stores the revocation of a stored one, the
+// rest of a key never changes.
+func (r *APIKeyRepository) Save(key *domain.APIKey, ctx context.Context) error {
+	if key.ID != 0 {
+		if _, err := r.tx.ExecContext(ctx, sqlRevokeAPIKey, key.RevokedAt, key.ID); err != nil {
+			return errorx.Wrap(err, "update api key sql")
+		}
+
+		return nil
+	}
+
+	scanner := r.tx.QueryRowxContext(ctx, sqlInsertAPIKey,
+		key.PlayerID, key.Name, key.Prefix, key.Hash, pq.Array(key.Scopes), key.CreatedAt)
+	if err := scanner.Scan(&key.ID); err != nil {
+		return errorx.Wrap(err, "insert api key sql")
+	}
+
+	return nil
+}
+
+// GetById returns domain.ErrAPIKeyNotFound if there's no key with the ID.
+func (r *APIKeyRepository) GetById(id int32, ctx context.Context) (*domain.APIKey, error) {
+	key, err := scanAPIKey(r.tx.QueryRowxContext(ctx, sqlGetAPIKeyById, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+
+		return nil, errorx.Wrap(err, "get api key by id sql")
+	}
+
+	return key, nil
+}
+
+// ListByPlayer returns the keys of the bot, the revoked ones included.
+func (r *APIKeyRepository) ListByPlayer(playerID int32, ctx context.Context) ([]*domain.APIKey, error) {
+	rows, err := r.tx.QueryxContext(ctx, sqlListAPIKeys, playerID)
+	if err != nil {
+		return nil, errorx.Wrap(err, "list api keys sql")
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, errorx.Wrap(err, "scan api key")
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeOwnedBy revokes the keys of every bot of the owner.
+func (r *APIKeyRepository) RevokeOwnedBy(ownerID int32, at time.Time, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlRevokeOwnedAPIKeys, at, ownerID); err != nil {
+		return errorx.Wrap(err, "revoke owned api keys sql")
+	}
+
+	return nil
+}
+
+func scanAPIKey(scanner interface{ Scan(dest ...any) error }) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+
+	var lastUsedAt, revokedAt sql.NullTime
+	err := scanner.Scan(&key.ID, &key.PlayerID, &key.Name, &key.Prefix, &key.Hash, pq.Array(&key.Scopes),
+		&key.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
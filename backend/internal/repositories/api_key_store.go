@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/infra"
+	"github.com/moLIart/gomoku-backend/pkg/errorx"
+)
+
+// APIKeyStore finds the bots by their API keys. Like RevocationStore it is
+// asked by the authentication middleware outside of any unit of work.
+type APIKeyStore struct {
+	db *infra.Database
+}
+
+func NewAPIKeyStore(db *infra.Database) *APIKeyStore {
+	return &APIKeyStore{
+		db: db,
+	}
+}
+
+var (
+	sqlGetAPIKeyByHash = sqlSelectAPIKeys + `
+		WHERE key_hash = $1`
+
+	sqlTouchAPIKey = `
+		UPDATE api_keys
+		SET last_used_at = $1
+		WHERE api_key_id = $2`
+)
+
+// GetAPIKeyByHash returns the key with the hash and its bot, or
+// domain.ErrAPIKeyNotFound.
+func (s *APIKeyStore) GetAPIKeyByHash(hash string, ctx context.Context) (*domain.APIKey, *domain.Player, error) {
+	conn, err := s.db.AcquireConn()
+	if err != nil {
+		return nil, nil, errorx.Wrap(err, "acquire db connection")
+	}
+
+	key, err := scanAPIKey(conn.QueryRowxContext(ctx, sqlGetAPIKeyByHash, hash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, domain.ErrAPIKeyNotFound
+		}
+
+		return nil, nil, errorx.Wrap(err, "get api key by hash sql")
+	}
+
+	player, err := scanPlayer(conn.QueryRowxContext(ctx, sqlGetPlayerById, key.PlayerID))
+	if err != nil {
+		return nil, nil, errorx.Wrap(err, "get api key player sql")
+	}
+
+	return key, player, nil
+}
+
+func (s *APIKeyStore) TouchAPIKey(id int32, at time.Time, ctx context.Context) error {
+	conn, err := s.db.AcquireConn()
+	if err != nil {
+		return errorx.Wrap(err, "acquire db connection")
+	}
+
+	if _, err := conn.ExecContext(ctx, sqlTouchAPIKey, at, id); err != nil {
+		return errorx.Wrap(err, "touch api key sql")
+	}
+
+	return nil
+}
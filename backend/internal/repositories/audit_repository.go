@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/pkg/errorx"
+)
+
+type AuditRepository struct {
+	tx *sqlx.Tx
+}
+
+func NewAuditRepository(tx *sqlx.Tx) *AuditRepository {
+	return &AuditRepository{
+		tx: tx,
+	}
+}
+
+var (
+	sqlInsertAuditEntry = `
+		INSERT INTO audit_log (actor_id, action, target_type, target_id, details, created_at)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6)
+		RETURNING audit_id`
+
+	// An empty target type lists every entry
+	sqlListAuditEntries = `
+		SELECT audit_id, actor_id, action, target_type, target_id, details, created_at
+		FROM audit_log
+		WHERE $1 = '' OR (target_type = $1 AND ($2 = 0 OR target_id = $2))
+		ORDER BY audit_id DESC
+		LIMIT $3 OFFSET $4`
+)
+
+// Save inserts the entry, the entries are never changed.
+func (r *AuditRepository) Save(entry *domain.AuditEntry, ctx context.Context) error {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return errorx.Wrap(err, "encode audit details")
+	}
+
+	scanner := r.tx.QueryRowxContext(ctx, sqlInsertAuditEntry,
+		entry.ActorID, entry.Action, entry.TargetType, sql.NullInt32{Int32: entry.TargetID, Valid: entry.TargetID != 0},
+		details, entry.CreatedAt)
+	if err := scanner.Scan(&entry.ID); err != nil {
+		return errorx.Wrap(err, "insert audit entry sql")
+	}
+
+	return nil
+}
+
+// List returns a page of the entries of the target, newest first. A zero
+// target ID lists every target of the type, an empty type every entry.
+func (r *AuditRepository) List(targetType string, targetID int32, limit, offset int, ctx context.Context) ([]*domain.AuditEntry, error) {
+	rows, err := r.tx.QueryxContext(ctx, sqlListAuditEntries, targetType, targetID, limit, offset)
+	if err != nil {
+		return nil, errorx.Wrap(err, "list audit entries sql")
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, errorx.Wrap(err, "scan audit entry")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func scanAuditEntry(scanner interface{ Scan(dest ...any) error }) (*domain.AuditEntry, error) {
+	entry := &domain.AuditEntry{}
+
+	var targetID sql.NullInt32
+	var details []byte
+	err := scanner.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &targetID,
+		&details, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.TargetID = targetID.Int32
+	if err := json.Unmarshal(details, &entry.Details); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -3,14 +3,14 @@ package repositories
 import (
 	"context"
 	"database/sql"
-	"database/sql/driver"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"slices"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/pkg/errorx"
 
 	_ "github.com/lib/pq"
 )
@@ -26,81 +26,95 @@ func NewGameRepository(tx *sqlx.Tx) *GameRepository {
 }
 
 var (
-	sqlGetGameById = `
+	sqlSelectGames = `
 		SELECT 
-			game_id, type, board, current_player_id, winner_player_id, first_player_id, second_player_id, last_activity,
+			game_id, type, board, rated, moves, current_player_id, winner_player_id, first_player_id, second_player_id,
+			undo_requested_by, winning_line, last_activity, annulled_at,
 			fp.player_id as fp_id, fp.nickname as fp_nickname, fp.password as fp_password, fp.score as fp_score,
-			sp.player_id as sp_id, sp.nickname as sp_nickname, sp.password as sp_password, sp.score as sp_score 
+			fp.guest as fp_guest, fp.bot as fp_bot, fp.owner_id as fp_owner_id,
+			sp.player_id as sp_id, sp.nickname as sp_nickname, sp.password as sp_password, sp.score as sp_score,
+			sp.guest as sp_guest, sp.bot as sp_bot, sp.owner_id as sp_owner_id
 		FROM games
 			LEFT JOIN players AS fp ON fp.player_id = games.first_player_id
-			LEFT JOIN players AS sp ON sp.player_id = games.second_player_id
+			LEFT JOIN players AS sp ON sp.player_id = games.second_player_id`
+
+	sqlGetGameById = sqlSelectGames + `
 		WHERE game_id = $1
 		LIMIT 1`
 
+	sqlListActiveGames = sqlSelectGames + `
+		WHERE winner_player_id IS NULL AND annulled_at IS NULL
+			AND (first_player_id = $1 OR second_player_id = $1 OR (type = 'pvp' AND second_player_id IS NULL))
+		ORDER BY last_activity DESC
+		LIMIT $2`
+
 	sqlInsertGame = `
-		INSERT INTO games (type, board, current_player_id, winner_player_id, first_player_id, second_player_id, last_activity)
-		VALUES ($1, $2::jsonb, $3, $4, $5, $6, $7)
+		INSERT INTO games (type, board, rated, moves, current_player_id, winner_player_id, first_player_id, second_player_id,
+			undo_requested_by, winning_line, last_activity)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, $8, $9, $10::jsonb, $11)
 		RETURNING game_id`
 
 	sqlUpdateGame = `
 		UPDATE games
-		SET type = $1, board = $2::jsonb, current_player_id = $3, winner_player_id = $4, first_player_id = $5, second_player_id = $6, last_activity = $7
-		WHERE game_id = $8`
+		SET type = $1, board = $2, rated = $3, moves = $4::jsonb, current_player_id = $5, winner_player_id = $6,
+			first_player_id = $7, second_player_id = $8, undo_requested_by = $9, winning_line = $10::jsonb, last_activity = $11,
+			annulled_at = $12
+		WHERE game_id = $13`
 )
 
-// This struct matches the SELECT columns in sqlGetGameById
+// This struct matches the SELECT columns in sqlSelectGames
 type gameWithPlayersRow struct {
 	GameID          int32         `db:"game_id"`
 	Type            string        `db:"type"`
-	Board           boardDto      `db:"board"`
+	Board           string        `db:"board"`
+	Rated           bool          `db:"rated"`
+	Moves           []byte        `db:"moves"`
 	CurrentPlayerID int32         `db:"current_player_id"`
 	WinnerPlayerID  sql.NullInt32 `db:"winner_player_id"`
 	FirstPlayerID   int32         `db:"first_player_id"`
 	SecondPlayerID  sql.NullInt32 `db:"second_player_id"`
+	UndoRequestedBy sql.NullInt32 `db:"undo_requested_by"`
+	WinningLine     []byte        `db:"winning_line"`
 	LastActivity    time.Time     `db:"last_activity"`
+	AnnulledAt      sql.NullTime  `db:"annulled_at"`
 
-	FPID       int32  `db:"fp_id"`
-	FPNickname string `db:"fp_nickname"`
-	FPPassword string `db:"fp_password"`
-	FPScore    int32  `db:"fp_score"`
+	FPID       int32         `db:"fp_id"`
+	FPNickname string        `db:"fp_nickname"`
+	FPPassword string        `db:"fp_password"`
+	FPScore    int32         `db:"fp_score"`
+	FPGuest    bool          `db:"fp_guest"`
+	FPBot      bool          `db:"fp_bot"`
+	FPOwnerID  sql.NullInt32 `db:"fp_owner_id"`
 
 	SPID       sql.NullInt32  `db:"sp_id"`
 	SPNickname sql.NullString `db:"sp_nickname"`
 	SPPassword sql.NullString `db:"sp_password"`
 	SPScore    sql.NullInt32  `db:"sp_score"`
+	SPGuest    sql.NullBool   `db:"sp_guest"`
+	SPBot      sql.NullBool   `db:"sp_bot"`
+	SPOwnerID  sql.NullInt32  `db:"sp_owner_id"`
 }
 
-type boardDto struct {
-	Size int       `json:"size"`
-	Data [][]int32 `json:"data"`
-}
-
-func DtoFromBoard(b *domain.Board) boardDto {
-	dto := boardDto{
-		Size: b.Size,
-		Data: make([][]int32, b.Size),
+// pointsToJson encodes cells as an array of [row, col] pairs.
+func pointsToJson(points []domain.Point) ([]byte, error) {
+	pairs := make([][2]int, len(points))
+	for i, p := range points {
+		pairs[i] = [2]int{p.Row, p.Col}
 	}
-
-	for i := range b.Data {
-		dto.Data[i] = make([]int32, b.Size)
-		copy(dto.Data[i], b.Data[i])
-	}
-
-	return dto
+	return json.Marshal(pairs)
 }
 
-func (b *boardDto) Value() (driver.Value, error) {
-	j, err := json.Marshal(b)
-	return j, err
-}
-
-func (p *boardDto) Scan(src any) error {
-	source, ok := src.([]byte)
-	if !ok {
-		return errors.New("type assertion .([]byte) failed")
+func pointsFromJson(data []byte) ([]domain.Point, error) {
+	var pairs [][2]int
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, err
 	}
 
-	return json.Unmarshal(source, p)
+	points := make([]domain.Point, len(pairs))
+	for i, p := range pairs {
+		points[i] = domain.Point{Row: p[0], Col: p[1]}
+	}
+	return points, nil
 }
 
 func (r *GameRepository) GetById(id int32, ctx context.Context) (*domain.Game, error) {
@@ -115,14 +129,61 @@ func (r *GameRepository) GetById(id int32, ctx context.Context) (*domain.Game, e
 		return nil, err
 	}
 
+	return mapRowToGame(&row)
+}
+
+// ListActive returns unfinished games of the player and PvP games waiting
+// for a second player, most recently active first.
+func (r *GameRepository) ListActive(playerID int32, limit int, ctx context.Context) ([]*domain.Game, error) {
+	rows, err := r.tx.QueryxContext(ctx, sqlListActiveGames, playerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []*domain.Game
+	for rows.Next() {
+		var row gameWithPlayersRow
+		if err := rows.StructScan(&row); err != nil {
+			return nil, err
+		}
+
+		game, err := mapRowToGame(&row)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+func mapRowToGame(row *gameWithPlayersRow) (*domain.Game, error) {
+	var err error
 	game := &domain.Game{Board: &domain.Board{}, Players: [2]*domain.Player{}}
 
 	game.ID = row.GameID
 	game.Type = domain.GameType(row.Type)
 	game.LastActivity = row.LastActivity
+	if row.AnnulledAt.Valid {
+		game.AnnulledAt = &row.AnnulledAt.Time
+	}
+
+	game.Rated = row.Rated
 
-	game.Board.Size = row.Board.Size
-	game.Board.Data = row.Board.Data
+	if err := game.Board.UnmarshalText([]byte(row.Board)); err != nil {
+		return nil, errorx.Wrap(err, "decode game board")
+	}
+
+	if game.Moves, err = pointsFromJson(row.Moves); err != nil {
+		return nil, errorx.Wrap(err, "decode game moves")
+	}
+
+	if row.WinningLine != nil {
+		if game.WinningLine, err = pointsFromJson(row.WinningLine); err != nil {
+			return nil, errorx.Wrap(err, "decode game winning line")
+		}
+	}
 
 	game.Players[0] = &domain.Player{
 		Entity: domain.Entity{
@@ -131,6 +192,9 @@ func (r *GameRepository) GetById(id int32, ctx context.Context) (*domain.Game, e
 		Nickname: row.FPNickname,
 		Password: row.FPPassword,
 		Score:    int(row.FPScore),
+		Guest:    row.FPGuest,
+		Bot:      row.FPBot,
+		OwnerID:  row.FPOwnerID.Int32,
 	}
 
 	if row.SPID.Valid {
@@ -141,6 +205,9 @@ func (r *GameRepository) GetById(id int32, ctx context.Context) (*domain.Game, e
 			Nickname: row.SPNickname.String,
 			Password: row.SPPassword.String,
 			Score:    int(row.SPScore.Int32),
+			Guest:    row.SPGuest.Bool,
+			Bot:      row.SPBot.Bool,
+			OwnerID:  row.SPOwnerID.Int32,
 		}
 	} else {
 		game.Players[1] = nil
@@ -161,11 +228,28 @@ func (r *GameRepository) GetById(id int32, ctx context.Context) (*domain.Game, e
 		game.WinnerPlayer = nil
 	}
 
+	if row.UndoRequestedBy.Valid {
+		requesterIdx := slices.IndexFunc(game.Players[:], func(p *domain.Player) bool {
+			return p != nil && p.ID == row.UndoRequestedBy.Int32
+		})
+		if requesterIdx < 0 {
+			return nil, fmt.Errorf("undo requester %d doesn't play game %d", row.UndoRequestedBy.Int32, row.GameID)
+		}
+
+		game.UndoRequestedBy = game.Players[requesterIdx]
+	} else {
+		game.UndoRequestedBy = nil
+	}
+
 	return game, nil
 }
 
 func (r *GameRepository) Save(game *domain.Game, ctx context.Context) error {
-	boardDto := DtoFromBoard(game.Board)
+	boardText, err := game.Board.MarshalText()
+	if err != nil {
+		return err
+	}
+
 	winnerPlayerID := sql.NullInt32{}
 	if game.WinnerPlayer != nil {
 		winnerPlayerID = sql.NullInt32{Int32: game.WinnerPlayer.ID, Valid: true}
@@ -176,21 +260,40 @@ func (r *GameRepository) Save(game *domain.Game, ctx context.Context) error {
 		secondPlayerID = sql.NullInt32{Int32: game.Players[1].ID, Valid: true}
 	}
 
-	boardJson, err := json.Marshal(boardDto)
+	undoRequestedBy := sql.NullInt32{}
+	if game.UndoRequestedBy != nil {
+		undoRequestedBy = sql.NullInt32{Int32: game.UndoRequestedBy.ID, Valid: true}
+	}
+
+	movesJson, err := pointsToJson(game.Moves)
 	if err != nil {
 		return err
 	}
 
+	winningLineJson := sql.NullString{}
+	if game.WinningLine != nil {
+		lineJson, err := pointsToJson(game.WinningLine)
+		if err != nil {
+			return err
+		}
+		winningLineJson = sql.NullString{String: string(lineJson), Valid: true}
+	}
+
 	if game.ID != 0 {
 		// Update existing game
 		_, err := r.tx.ExecContext(ctx, sqlUpdateGame,
 			game.Type,
-			boardJson,
+			string(boardText),
+			game.Rated,
+			movesJson,
 			game.CurrentPlayer.ID,
 			winnerPlayerID,
 			game.Players[0].ID,
 			secondPlayerID,
+			undoRequestedBy,
+			winningLineJson,
 			game.LastActivity,
+			game.AnnulledAt,
 			game.ID)
 		if err != nil {
 			return err
@@ -199,11 +302,15 @@ func (r *GameRepository) Save(game *domain.Game, ctx context.Context) error {
 		// Insert new game
 		err := r.tx.QueryRowContext(ctx, sqlInsertGame,
 			game.Type,
-			boardJson,
+			string(boardText),
+			game.Rated,
+			movesJson,
 			game.CurrentPlayer.ID,
 			winnerPlayerID,
 			game.Players[0].ID,
 			secondPlayerID,
+			undoRequestedBy,
+			winningLineJson,
 			game.LastActivity).Scan(&game.ID)
 		if err != nil {
 			return err
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
@@ -17,20 +19,106 @@ type PlayerRepository struct {
 
 var (
 	sqlInsertPlayer = `
-		INSERT INTO players (nickname, password, score) 
-		VALUES ($1, $2, $3) 
+		INSERT INTO players (nickname, password, score, guest, bot, owner_id) 
+		VALUES ($1, $2, $3, $4, $5, $6) 
 		RETURNING player_id`
 
-	sqlUpdatePlayer = `
+	sqlUpdatePlayerScore = `
 		UPDATE players 
-		SET nickname = $1, password = $2, score = $3
+		SET score = $1
+		WHERE player_id = $2`
+
+	sqlUpdatePlayerNickname = `
+		UPDATE players
+		SET nickname = $1
+		WHERE player_id = $2`
+
+	sqlUpdatePlayerPassword = `
+		UPDATE players
+		SET password = $1
+		WHERE player_id = $2`
+
+	sqlUpgradePlayer = `
+		UPDATE players
+		SET nickname = $1, password = $2, guest = false
+		WHERE player_id = $3`
+
+	sqlTouchPlayer = `
+		UPDATE players
+		SET last_seen_at = $1
+		WHERE player_id = $2`
+
+	// The guests who played keep their row for the games, anonymized like
+	// a deleted account
+	sqlRemoveInactiveGuests = `
+		DELETE FROM players AS p
+		WHERE p.guest AND p.last_seen_at < $1
+			AND NOT EXISTS (
+				SELECT 1 FROM games AS g
+				WHERE g.first_player_id = p.player_id OR g.second_player_id = p.player_id
+			)`
+
+	sqlAnonymizeInactiveGuests = `
+		UPDATE players
+		SET nickname = $1 || player_id, password = '', deleted_at = $2,
+			token_generation = token_generation + 1
+		WHERE guest AND deleted_at IS NULL AND last_seen_at < $3
+		RETURNING player_id`
+
+	sqlDeletePlayer = `
+		UPDATE players
+		SET nickname = $1, password = $2, deleted_at = $3
 		WHERE player_id = $4`
 
-	sqlGetPlayerByNickname = `
-		SELECT player_id, nickname, password, score 
-		FROM players 
+	sqlSelectPlayers = `
+		SELECT player_id, nickname, password, score, token_generation, deleted_at, guest, bot, owner_id,
+			role, banned_at, ban_reason
+		FROM players`
+
+	sqlGetPlayerByNickname = sqlSelectPlayers + `
 		WHERE nickname = $1
 		LIMIT 1`
+
+	sqlGetPlayerById = sqlSelectPlayers + `
+		WHERE player_id = $1`
+
+	sqlListBots = sqlSelectPlayers + `
+		WHERE owner_id = $1 AND deleted_at IS NULL
+		ORDER BY player_id`
+
+	// The deleted players are left out, the guests too unless searched by
+	// their generated nickname
+	sqlSearchPlayers = sqlSelectPlayers + `
+		WHERE deleted_at IS NULL AND ($1 = '' OR nickname ILIKE '%' || $1 || '%')
+			AND ($1 <> '' OR NOT guest)
+		ORDER BY nickname
+		LIMIT $2 OFFSET $3`
+
+	sqlUpdatePlayerRole = `
+		UPDATE players
+		SET role = $1
+		WHERE player_id = $2`
+
+	sqlUpdatePlayerBan = `
+		UPDATE players
+		SET banned_at = $1, ban_reason = $2
+		WHERE player_id = $3`
+
+	// The tokens of a promoted player are revoked, a refresh signs the role.
+	// Once there is an admin the nicknames are ignored, whoever holds one of
+	// them later isn't promoted.
+	sqlPromoteAdmins = `
+		UPDATE players
+		SET role = 'admin', token_generation = token_generation + 1
+		WHERE nickname = ANY($1) AND deleted_at IS NULL AND NOT guest AND NOT bot
+			AND NOT EXISTS (SELECT 1 FROM players WHERE role = 'admin')
+		RETURNING nickname`
+
+	sqlBumpTokenGeneration = `
+		UPDATE players
+		SET token_generation = token_generation + 1
+		WHERE player_id = $1
+		RETURNING token_generation`
 )
 
 func NewPlayerRepository(tx *sqlx.Tx) *PlayerRepository {
@@ -39,14 +127,13 @@ func NewPlayerRepository(tx *sqlx.Tx) *PlayerRepository {
 	}
 }
 
-// Insert inserts a new player into the database.
-// It returns an error if the player already has an existing ID,
-// if acquiring a database connection fails, or if the SQL insert operation fails.
+// Save inserts a new player into the database, or updates the score of a
+// stored one. The account changes have their own methods so that saving a
+// player loaded with a game can't revert them.
 // On successful insertion, the player's ID is set to the newly generated value.
 func (r *PlayerRepository) Save(player *domain.Player, ctx context.Context) error {
 	if player.ID != 0 {
-		_, err := r.tx.ExecContext(ctx, sqlUpdatePlayer,
-			player.Nickname, player.Password, player.Score, player.ID)
+		_, err := r.tx.ExecContext(ctx, sqlUpdatePlayerScore, player.Score, player.ID)
 		if err != nil {
 			return errorx.Wrap(err, "update player sql")
 		}
@@ -55,7 +142,7 @@ func (r *PlayerRepository) Save(player *domain.Player, ctx context.Context) erro
 	}
 
 	scanner := r.tx.QueryRowxContext(ctx, sqlInsertPlayer,
-		player.Nickname, player.Password, player.Score)
+		player.Nickname, player.Password, player.Score, player.Guest, player.Bot, sql.NullInt32{Int32: player.OwnerID, Valid: player.OwnerID != 0})
 	if err := scanner.Scan(&player.ID); err != nil {
 		// Check if the error is a PostgreSQL unique violation error (duplicate key)
 		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
@@ -73,10 +160,8 @@ func (r *PlayerRepository) Save(player *domain.Player, ctx context.Context) erro
 // If an error occurs during the database operation, it wraps and returns the error.
 // The context parameter is used to control the lifetime of the database query.
 func (r *PlayerRepository) GetByNickname(nickname string, ctx context.Context) (*domain.Player, error) {
-	player := &domain.Player{}
-
-	scanner := r.tx.QueryRowxContext(ctx, sqlGetPlayerByNickname, nickname)
-	if err := scanner.Scan(&player.ID, &player.Nickname, &player.Password, &player.Score); err != nil {
+	player, err := scanPlayer(r.tx.QueryRowxContext(ctx, sqlGetPlayerByNickname, nickname))
+	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrPlayerNotFound // Player not found
 		}
@@ -86,3 +171,209 @@ func (r *PlayerRepository) GetByNickname(nickname string, ctx context.Context) (
 
 	return player, nil
 }
+
+// GetById retrieves a player from the database by their ID.
+// It returns domain.ErrPlayerNotFound if no player exists with the given ID.
+func (r *PlayerRepository) GetById(id int32, ctx context.Context) (*domain.Player, error) {
+	player, err := scanPlayer(r.tx.QueryRowxContext(ctx, sqlGetPlayerById, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPlayerNotFound
+		}
+
+		return nil, errorx.Wrap(err, "get player by id sql")
+	}
+
+	return player, nil
+}
+
+// ListBots returns the bots of the owner.
+func (r *PlayerRepository) ListBots(ownerID int32, ctx context.Context) ([]*domain.Player, error) {
+	rows, err := r.tx.QueryxContext(ctx, sqlListBots, ownerID)
+	if err != nil {
+		return nil, errorx.Wrap(err, "list bots sql")
+	}
+	defer rows.Close()
+
+	var bots []*domain.Player
+	for rows.Next() {
+		bot, err := scanPlayer(rows)
+		if err != nil {
+			return nil, errorx.Wrap(err, "scan bot")
+		}
+		bots = append(bots, bot)
+	}
+
+	return bots, rows.Err()
+}
+
+// Search returns a page of the players whose nickname contains the query,
+// ordered by the nickname. An empty query lists the registered players.
+func (r *PlayerRepository) Search(query string, limit, offset int, ctx context.Context) ([]*domain.Player, error) {
+	rows, err := r.tx.QueryxContext(ctx, sqlSearchPlayers, escapeLike(query), limit, offset)
+	if err != nil {
+		return nil, errorx.Wrap(err, "search players sql")
+	}
+	defer rows.Close()
+
+	var players []*domain.Player
+	for rows.Next() {
+		player, err := scanPlayer(rows)
+		if err != nil {
+			return nil, errorx.Wrap(err, "scan player")
+		}
+		players = append(players, player)
+	}
+
+	return players, rows.Err()
+}
+
+// UpdateRole stores the role of the player.
+func (r *PlayerRepository) UpdateRole(player *domain.Player, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlUpdatePlayerRole, player.Role, player.ID); err != nil {
+		return errorx.Wrap(err, "update player role sql")
+	}
+
+	return nil
+}
+
+// UpdateBan stores the ban of the player, or its lifting.
+func (r *PlayerRepository) UpdateBan(player *domain.Player, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlUpdatePlayerBan, player.BannedAt, player.BanReason, player.ID); err != nil {
+		return errorx.Wrap(err, "update player ban sql")
+	}
+
+	return nil
+}
+
+// PromoteAdmins makes admins of the registered players with the
+// nicknames unless there is an admin already, and returns the nicknames
+// promoted.
+func (r *PlayerRepository) PromoteAdmins(nicknames []string, ctx context.Context) ([]string, error) {
+	var promoted []string
+	if err := r.tx.SelectContext(ctx, &promoted, sqlPromoteAdmins, pq.Array(nicknames)); err != nil {
+		return nil, errorx.Wrap(err, "promote admins sql")
+	}
+
+	return promoted, nil
+}
+
+// BumpTokenGeneration increments the token generation of the player, which
+// denies every access token signed before. It isn't a part of Save so
+// that saving a player loaded elsewhere can't roll it back.
+func (r *PlayerRepository) BumpTokenGeneration(player *domain.Player, ctx context.Context) error {
+	scanner := r.tx.QueryRowxContext(ctx, sqlBumpTokenGeneration, player.ID)
+	if err := scanner.Scan(&player.TokenGeneration); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrPlayerNotFound
+		}
+
+		return errorx.Wrap(err, "bump token generation sql")
+	}
+
+	return nil
+}
+
+// UpdateNickname stores the nickname of the player, it returns
+// domain.ErrPlayerAlreadyExists if another player has it.
+func (r *PlayerRepository) UpdateNickname(player *domain.Player, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlUpdatePlayerNickname, player.Nickname, player.ID); err != nil {
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+			return domain.ErrPlayerAlreadyExists
+		}
+
+		return errorx.Wrap(err, "update player nickname sql")
+	}
+
+	return nil
+}
+
+func (r *PlayerRepository) UpdatePassword(player *domain.Player, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlUpdatePlayerPassword, player.Password, player.ID); err != nil {
+		return errorx.Wrap(err, "update player password sql")
+	}
+
+	return nil
+}
+
+// Delete stores the anonymized player, the row stays for the games
+// referring to it.
+func (r *PlayerRepository) Delete(player *domain.Player, ctx context.Context) error {
+	_, err := r.tx.ExecContext(ctx, sqlDeletePlayer, player.Nickname, player.Password, player.DeletedAt, player.ID)
+	if err != nil {
+		return errorx.Wrap(err, "delete player sql")
+	}
+
+	return nil
+}
+
+// Upgrade stores the full account of a former guest, it returns
+// domain.ErrPlayerAlreadyExists if another player has the nickname.
+func (r *PlayerRepository) Upgrade(player *domain.Player, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlUpgradePlayer, player.Nickname, player.Password, player.ID); err != nil {
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code == "23505" {
+			return domain.ErrPlayerAlreadyExists
+		}
+
+		return errorx.Wrap(err, "upgrade player sql")
+	}
+
+	return nil
+}
+
+// Touch records that the player was active.
+func (r *PlayerRepository) Touch(playerID int32, at time.Time, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlTouchPlayer, at, playerID); err != nil {
+		return errorx.Wrap(err, "touch player sql")
+	}
+
+	return nil
+}
+
+// RemoveInactiveGuests removes the guests not seen since the time. The
+// guests without games are deleted, the others anonymized. It returns the
+// number of guests removed and the IDs of the anonymized ones, whose
+// sessions are left to revoke.
+func (r *PlayerRepository) RemoveInactiveGuests(since time.Time, now time.Time, ctx context.Context) (int64, []int32, error) {
+	deleted, err := r.tx.ExecContext(ctx, sqlRemoveInactiveGuests, since)
+	if err != nil {
+		return 0, nil, errorx.Wrap(err, "remove inactive guests sql")
+	}
+
+	var anonymized []int32
+	if err := r.tx.SelectContext(ctx, &anonymized, sqlAnonymizeInactiveGuests, domain.DeletedNicknamePrefix, now, since); err != nil {
+		return 0, nil, errorx.Wrap(err, "anonymize inactive guests sql")
+	}
+
+	n, _ := deleted.RowsAffected()
+	return n + int64(len(anonymized)), anonymized, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, the query is
+// matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func scanPlayer(scanner interface{ Scan(dest ...any) error }) (*domain.Player, error) {
+	player := &domain.Player{}
+
+	var deletedAt, bannedAt sql.NullTime
+	var ownerID sql.NullInt32
+	err := scanner.Scan(&player.ID, &player.Nickname, &player.Password, &player.Score, &player.TokenGeneration,
+		&deletedAt, &player.Guest, &player.Bot, &ownerID, &player.Role, &bannedAt, &player.BanReason)
+	if err != nil {
+		return nil, err
+	}
+
+	player.OwnerID = ownerID.Int32
+
+	if deletedAt.Valid {
+		player.DeletedAt = &deletedAt.Time
+	}
+	if bannedAt.Valid {
+		player.BannedAt = &bannedAt.Time
+	}
+
+	return player, nil
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/pkg/errorx"
+)
+
+type PuzzleRepository struct {
+	tx *sqlx.Tx
+}
+
+func NewPuzzleRepository(tx *sqlx.Tx) *PuzzleRepository {
+	return &PuzzleRepository{
+		tx: tx,
+	}
+}
+
+var (
+	sqlSelectPuzzles = `
+		SELECT puzzle_id, board, side, solutions, difficulty, rating
+		FROM puzzles`
+
+	sqlGetPuzzleById = sqlSelectPuzzles + `
+		WHERE puzzle_id = $1
+		LIMIT 1`
+
+	sqlGetDailyPuzzle = sqlSelectPuzzles + `
+		WHERE puzzle_id = (SELECT puzzle_id FROM daily_puzzles WHERE day = $1)`
+
+	// Puzzles which were never the daily puzzle come first, then any
+	sqlPickDailyPuzzle = `
+		INSERT INTO daily_puzzles (day, puzzle_id)
+		SELECT $1, p.puzzle_id
+		FROM puzzles AS p
+		ORDER BY EXISTS (SELECT 1 FROM daily_puzzles AS d WHERE d.puzzle_id = p.puzzle_id), random()
+		LIMIT 1
+		ON CONFLICT (day) DO NOTHING`
+
+	sqlInsertPuzzle = `
+		INSERT INTO puzzles (board, side, solutions, difficulty, rating)
+		VALUES ($1, $2, $3::jsonb, $4, $5)
+		RETURNING puzzle_id`
+
+	sqlUpdatePuzzle = `
+		UPDATE puzzles
+		SET board = $1, side = $2, solutions = $3::jsonb, difficulty = $4, rating = $5
+		WHERE puzzle_id = $6`
+
+	sqlGetPuzzleRating = `
+		SELECT player_id, rating, attempts, solved
+		FROM puzzle_ratings
+		WHERE player_id = $1`
+
+	sqlUpsertPuzzleRating = `
+		INSERT INTO puzzle_ratings (player_id, rating, attempts, solved)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (player_id) DO UPDATE
+		SET rating = EXCLUDED.rating, attempts = EXCLUDED.attempts, solved = EXCLUDED.solved`
+
+	sqlInsertPuzzleAttempt = `
+		INSERT INTO puzzle_attempts (player_id, puzzle_id, solved)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (player_id, puzzle_id) DO NOTHING`
+)
+
+// This struct matches the SELECT columns in sqlSelectPuzzles
+type puzzleRow struct {
+	PuzzleID   int32  `db:"puzzle_id"`
+	Board      string `db:"board"`
+	Side       int    `db:"side"`
+	Solutions  []byte `db:"solutions"`
+	Difficulty int    `db:"difficulty"`
+	Rating     int    `db:"rating"`
+}
+
+func (r *PuzzleRepository) GetById(id int32, ctx context.Context) (*domain.Puzzle, error) {
+	return r.get(ctx, sqlGetPuzzleById, id)
+}
+
+// GetDaily returns the puzzle of the day, the first request of a day picks
+// a puzzle which wasn't the daily puzzle yet, if there is one.
+func (r *PuzzleRepository) GetDaily(day time.Time, ctx context.Context) (*domain.Puzzle, error) {
+	date := day.Format(time.DateOnly)
+
+	if _, err := r.tx.ExecContext(ctx, sqlPickDailyPuzzle, date); err != nil {
+		return nil, errorx.Wrap(err, "pick daily puzzle sql")
+	}
+
+	return r.get(ctx, sqlGetDailyPuzzle, date)
+}
+
+func (r *PuzzleRepository) get(ctx context.Context, query string, args ...any) (*domain.Puzzle, error) {
+	var row puzzleRow
+	if err := r.tx.QueryRowxContext(ctx, query, args...).StructScan(&row); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPuzzleNotFound
+		}
+		return nil, errorx.Wrap(err, "get puzzle sql")
+	}
+
+	return mapRowToPuzzle(&row)
+}
+
+func mapRowToPuzzle(row *puzzleRow) (*domain.Puzzle, error) {
+	puzzle := &domain.Puzzle{
+		Board:      &domain.Board{},
+		Side:       domain.Stone(row.Side),
+		Difficulty: row.Difficulty,
+		Rating:     row.Rating,
+	}
+	puzzle.ID = row.PuzzleID
+
+	if err := puzzle.Board.UnmarshalText([]byte(row.Board)); err != nil {
+		return nil, errorx.Wrap(err, "decode puzzle board")
+	}
+
+	var lines []json.RawMessage
+	if err := json.Unmarshal(row.Solutions, &lines); err != nil {
+		return nil, errorx.Wrap(err, "decode puzzle solutions")
+	}
+	for _, line := range lines {
+		points, err := pointsFromJson(line)
+		if err != nil {
+			return nil, errorx.Wrap(err, "decode puzzle solutions")
+		}
+		puzzle.Solutions = append(puzzle.Solutions, points)
+	}
+
+	return puzzle, nil
+}
+
+func (r *PuzzleRepository) Save(puzzle *domain.Puzzle, ctx context.Context) error {
+	boardText, err := puzzle.Board.MarshalText()
+	if err != nil {
+		return err
+	}
+
+	lines := make([]json.RawMessage, len(puzzle.Solutions))
+	for i, line := range puzzle.Solutions {
+		if lines[i], err = pointsToJson(line); err != nil {
+			return err
+		}
+	}
+	solutionsJson, err := json.Marshal(lines)
+	if err != nil {
+		return err
+	}
+
+	if puzzle.ID != 0 {
+		_, err := r.tx.ExecContext(ctx, sqlUpdatePuzzle,
+			string(boardText), int(puzzle.Side), solutionsJson, puzzle.Difficulty, puzzle.Rating, puzzle.ID)
+		if err != nil {
+			return errorx.Wrap(err, "update puzzle sql")
+		}
+
+		return nil
+	}
+
+	err = r.tx.QueryRowContext(ctx, sqlInsertPuzzle,
+		string(boardText), int(puzzle.Side), solutionsJson, puzzle.Difficulty, puzzle.Rating).Scan(&puzzle.ID)
+	if err != nil {
+		return errorx.Wrap(err, "insert puzzle sql")
+	}
+
+	return nil
+}
+
+// GetRating returns the puzzle rating of the player, the starting rating
+// if the player hasn't attempted a puzzle yet.
+func (r *PuzzleRepository) GetRating(playerID int32, ctx context.Context) (*domain.PuzzleRating, error) {
+	rating := &domain.PuzzleRating{}
+
+	scanner := r.tx.QueryRowxContext(ctx, sqlGetPuzzleRating, playerID)
+	if err := scanner.Scan(&rating.PlayerID, &rating.Rating, &rating.Attempts, &rating.Solved); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.NewPuzzleRating(playerID), nil
+		}
+
+		return nil, errorx.Wrap(err, "get puzzle rating sql")
+	}
+
+	return rating, nil
+}
+
+func (r *PuzzleRepository) SaveRating(rating *domain.PuzzleRating, ctx context.Context) error {
+	_, err := r.tx.ExecContext(ctx, sqlUpsertPuzzleRating,
+		rating.PlayerID, rating.Rating, rating.Attempts, rating.Solved)
+	if err != nil {
+		return errorx.Wrap(err, "save puzzle rating sql")
+	}
+
+	return nil
+}
+
+// AddAttempt records the attempt of the player at the puzzle and reports
+// whether it is the first one.
+func (r *PuzzleRepository) AddAttempt(playerID, puzzleID int32, solved bool, ctx context.Context) (bool, error) {
+	res, err := r.tx.ExecContext(ctx, sqlInsertPuzzleAttempt, playerID, puzzleID, solved)
+	if err != nil {
+		return false, errorx.Wrap(err, "insert puzzle attempt sql")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errorx.Wrap(err, "insert puzzle attempt sql")
+	}
+
+	return n == 1, nil
+}
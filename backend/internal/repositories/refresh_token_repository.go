@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/pkg/errorx"
+)
+
+type RefreshTokenRepository struct {
+	tx *sqlx.Tx
+}
+
+func NewRefreshTokenRepository(tx *sqlx.Tx) *RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		tx: tx,
+	}
+}
+
+var (
+	sqlInsertRefreshToken = `
+		INSERT INTO refresh_tokens (player_id, family, token_hash, expires_at, used_at, revoked_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING token_id`
+
+	sqlUpdateRefreshToken = `
+		UPDATE refresh_tokens
+		SET used_at = $1, revoked_at = $2
+		WHERE token_id = $3`
+
+	// The row is locked so concurrent refreshes with the same token see
+	// it used
+	sqlGetRefreshTokenByHash = `
+		SELECT token_id, player_id, family, token_hash, expires_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`
+
+	sqlRevokeRefreshTokenFamily = `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE family = $2 AND revoked_at IS NULL`
+
+	sqlRevokePlayerRefreshTokens = `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE player_id = $2 AND revoked_at IS NULL`
+
+	sqlRevokePlayersRefreshTokens = `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE player_id = ANY($2) AND revoked_at IS NULL`
+)
+
+// Save inserts a new token or updates the use and revocation of a stored one.
+func (r *RefreshTokenRepository) Save(token *domain.RefreshToken, ctx context.Context) error {
+	if token.ID != 0 {
+		_, err := r.tx.ExecContext(ctx, sqlUpdateRefreshToken, token.UsedAt, token.RevokedAt, token.ID)
+		if err != nil {
+			return errorx.Wrap(err, "update refresh token sql")
+		}
+
+		return nil
+	}
+
+	scanner := r.tx.QueryRowxContext(ctx, sqlInsertRefreshToken,
+		token.PlayerID, token.Family, token.Hash, token.ExpiresAt, token.UsedAt, token.RevokedAt)
+	if err := scanner.Scan(&token.ID); err != nil {
+		return errorx.Wrap(err, "insert refresh token sql")
+	}
+
+	return nil
+}
+
+// GetByHash returns the token with the hash and locks it until the end of
+// the transaction.
+func (r *RefreshTokenRepository) GetByHash(hash string, ctx context.Context) (*domain.RefreshToken, error) {
+	token := &domain.RefreshToken{}
+
+	var usedAt, revokedAt sql.NullTime
+	scanner := r.tx.QueryRowxContext(ctx, sqlGetRefreshTokenByHash, hash)
+	err := scanner.Scan(&token.ID, &token.PlayerID, &token.Family, &token.Hash, &token.ExpiresAt, &usedAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRefreshTokenInvalid
+		}
+
+		return nil, errorx.Wrap(err, "get refresh token by hash sql")
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return token, nil
+}
+
+// RevokeFamily revokes every token of the family which isn't revoked yet.
+func (r *RefreshTokenRepository) RevokeFamily(family string, at time.Time, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlRevokeRefreshTokenFamily, at, family); err != nil {
+		return errorx.Wrap(err, "revoke refresh token family sql")
+	}
+
+	return nil
+}
+
+// RevokePlayer revokes every token of the player which isn't revoked yet.
+func (r *RefreshTokenRepository) RevokePlayer(playerID int32, at time.Time, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlRevokePlayerRefreshTokens, at, playerID); err != nil {
+		return errorx.Wrap(err, "revoke player refresh tokens sql")
+	}
+
+	return nil
+}
+
+// RevokePlayers revokes every refresh token of the players.
+func (r *RefreshTokenRepository) RevokePlayers(playerIDs []int32, at time.Time, ctx context.Context) error {
+	if _, err := r.tx.ExecContext(ctx, sqlRevokePlayersRefreshTokens, at, pq.Array(playerIDs)); err != nil {
+		return errorx.Wrap(err, "revoke players refresh tokens sql")
+	}
+
+	return nil
+}
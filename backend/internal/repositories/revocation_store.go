@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/infra"
+	"github.com/moLIart/gomoku-backend/pkg/errorx"
+)
+
+// RevocationStore is the Postgres side of the access token denylist. It is
+// asked by the authentication middleware outside of any unit of work, so
+// it runs its statements on the connection itself.
+type RevocationStore struct {
+	db *infra.Database
+}
+
+func NewRevocationStore(db *infra.Database) *RevocationStore {
+	return &RevocationStore{
+		db: db,
+	}
+}
+
+var (
+	sqlIsTokenRevoked = `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+
+	sqlRevokeToken = `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING`
+
+	// Expired tokens are refused anyway
+	sqlDeleteExpiredRevokedTokens = `
+		DELETE FROM revoked_tokens
+		WHERE expires_at < now()`
+
+	sqlGetTokenGeneration = `
+		SELECT token_generation
+		FROM players
+		WHERE player_id = $1`
+)
+
+func (s *RevocationStore) IsTokenRevoked(jti string, ctx context.Context) (bool, error) {
+	conn, err := s.db.AcquireConn()
+	if err != nil {
+		return false, errorx.Wrap(err, "acquire db connection")
+	}
+
+	var revoked bool
+	if err := conn.QueryRowxContext(ctx, sqlIsTokenRevoked, jti).Scan(&revoked); err != nil {
+		return false, errorx.Wrap(err, "is token revoked sql")
+	}
+
+	return revoked, nil
+}
+
+// RevokeToken adds the token to the denylist and drops the expired ones.
+func (s *RevocationStore) RevokeToken(jti string, expiresAt time.Time, ctx context.Context) error {
+	conn, err := s.db.AcquireConn()
+	if err != nil {
+		return errorx.Wrap(err, "acquire db connection")
+	}
+
+	if _, err := conn.ExecContext(ctx, sqlRevokeToken, jti, expiresAt); err != nil {
+		return errorx.Wrap(err, "revoke token sql")
+	}
+	if _, err := conn.ExecContext(ctx, sqlDeleteExpiredRevokedTokens); err != nil {
+		return errorx.Wrap(err, "delete expired revoked tokens sql")
+	}
+
+	return nil
+}
+
+// TokenGeneration returns domain.ErrPlayerNotFound if there's no player
+// with the ID.
+func (s *RevocationStore) TokenGeneration(playerID int32, ctx context.Context) (int, error) {
+	conn, err := s.db.AcquireConn()
+	if err != nil {
+		return 0, errorx.Wrap(err, "acquire db connection")
+	}
+
+	var generation int
+	if err := conn.QueryRowxContext(ctx, sqlGetTokenGeneration, playerID).Scan(&generation); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, domain.ErrPlayerNotFound
+		}
+
+		return 0, errorx.Wrap(err, "get token generation sql")
+	}
+
+	return generation, nil
+}
@@ -28,6 +28,22 @@ func (uow *UnitOfWork) GetGameRepository() *GameRepository {
 	return NewGameRepository(uow.tx)
 }
 
+func (uow *UnitOfWork) GetPuzzleRepository() *PuzzleRepository {
+	return NewPuzzleRepository(uow.tx)
+}
+
+func (uow *UnitOfWork) GetRefreshTokenRepository() *RefreshTokenRepository {
+	return NewRefreshTokenRepository(uow.tx)
+}
+
+func (uow *UnitOfWork) GetAPIKeyRepository() *APIKeyRepository {
+	return NewAPIKeyRepository(uow.tx)
+}
+
+func (uow *UnitOfWork) GetAuditRepository() *AuditRepository {
+	return NewAuditRepository(uow.tx)
+}
+
 func (uow *UnitOfWork) Begin(ctx context.Context) error {
 	conn, err := uow.db.AcquireConn()
 	if err != nil {
@@ -0,0 +1,80 @@
+package selfplay
+
+import "math"
+
+// z95 is the normal quantile of a two-sided 95% confidence interval.
+const z95 = 1.959964
+
+// Summary counts the results of a match for the first entrant.
+type Summary struct {
+	Games    int
+	Wins     int
+	Draws    int
+	Losses   int
+	Forfeits int
+}
+
+// Summarize counts the results.
+func Summarize(results []Result) Summary {
+	var s Summary
+	for _, r := range results {
+		s.Games++
+		switch r.Score() {
+		case 1:
+			s.Wins++
+		case 0.5:
+			s.Draws++
+		default:
+			s.Losses++
+		}
+		if r.Forfeit != nil {
+			s.Forfeits++
+		}
+	}
+	return s
+}
+
+// Score returns the share of points of the first entrant.
+func (s Summary) Score() float64 {
+	if s.Games == 0 {
+		return 0.5
+	}
+	return (float64(s.Wins) + float64(s.Draws)/2) / float64(s.Games)
+}
+
+// Elo returns the rating difference of the first entrant over the second
+// and the margin of its 95% confidence interval. The difference is
+// infinite after a match without a point lost or won.
+func (s Summary) Elo() (diff, margin float64) {
+	if s.Games == 0 {
+		return 0, math.Inf(1)
+	}
+
+	score := s.Score()
+	if score <= 0 || score >= 1 {
+		return eloDiff(score), math.Inf(1)
+	}
+	n := float64(s.Games)
+
+	// Standard deviation of the mean game score
+	variance := (float64(s.Wins)*math.Pow(1-score, 2) +
+		float64(s.Draws)*math.Pow(0.5-score, 2) +
+		float64(s.Losses)*math.Pow(score, 2)) / n
+	deviation := math.Sqrt(variance / n)
+
+	low := eloDiff(score - z95*deviation)
+	high := eloDiff(score + z95*deviation)
+
+	return eloDiff(score), (high - low) / 2
+}
+
+// eloDiff converts an expected score to a rating difference.
+func eloDiff(score float64) float64 {
+	switch {
+	case score <= 0:
+		return math.Inf(-1)
+	case score >= 1:
+		return math.Inf(1)
+	}
+	return 400 * math.Log10(score/(1-score))
+}
@@ -0,0 +1,269 @@
+// Package selfplay plays matches between AI engines on the domain rules,
+// without the server or a database, to compare engine configurations.
+package selfplay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/records"
+)
+
+// Entrant is one side of a match. New is called once per worker, so
+// engines are never shared between games played at the same time.
+type Entrant struct {
+	Name string
+	New  func() (ai.Engine, error)
+}
+
+// Match configures games between two entrants: every opening is played
+// twice with colors swapped, until Games games are played.
+type Match struct {
+	Size     int
+	Openings [][]domain.Point
+	Games    int
+	Workers  int
+}
+
+// Result is a finished game. A is the stone of the first entrant, Forfeit
+// tells why a game was lost without five in a row.
+type Result struct {
+	Game    int
+	Opening int
+	A       domain.Stone
+	Record  *records.Record
+	Forfeit error
+}
+
+// Score returns the points of the first entrant: 1 for a win, 0.5 for a
+// draw.
+func (r Result) Score() float64 {
+	switch r.Record.Winner {
+	case domain.Empty:
+		return 0.5
+	case r.A:
+		return 1
+	}
+	return 0
+}
+
+// ReadOpenings decodes one opening per line in board notation, e.g.
+// "h8 i9 g7". Empty lines and lines starting with # are skipped.
+func ReadOpenings(r io.Reader, size int) ([][]domain.Point, error) {
+	var openings [][]domain.Point
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rec, err := records.ReadPos(strings.NewReader(line), size)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		if _, err := setup(size, rec.Moves); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		openings = append(openings, rec.Moves)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return openings, nil
+}
+
+// setup places the opening on an empty board, black first. An opening
+// must leave the game undecided.
+func setup(size int, opening []domain.Point) (*domain.Board, error) {
+	board, err := domain.NewBoard(size)
+	if err != nil {
+		return nil, err
+	}
+
+	side := domain.Black
+	for _, p := range opening {
+		if err := board.Put(p.Row, p.Col, side); err != nil {
+			return nil, err
+		}
+		if board.CheckWin(p.Row, p.Col, side, domain.WinLength) {
+			return nil, fmt.Errorf("opening is already won by %v", side)
+		}
+		side = side.Opponent()
+	}
+
+	return board, nil
+}
+
+// Play plays a game from the opening to the end. An engine failing to
+// return a legal move loses the game, the error is kept in Forfeit. A full
+// board is a draw.
+func Play(ctx context.Context, size int, opening []domain.Point, black, white ai.Engine) (Result, error) {
+	board, err := setup(size, opening)
+	if err != nil {
+		return Result{}, err
+	}
+
+	rec := &records.Record{
+		Size:  size,
+		Moves: append([]domain.Point(nil), opening...),
+	}
+	result := Result{Record: rec}
+
+	side := domain.Black
+	if len(opening)%2 == 1 {
+		side = domain.White
+	}
+
+	for len(rec.Moves) < size*size {
+		if err := ctx.Err(); err != nil {
+			return Result{}, err
+		}
+
+		engine := black
+		if side == domain.White {
+			engine = white
+		}
+
+		move, err := engine.BestMove(ctx, board, side)
+		if err == nil {
+			err = board.Put(move.Row, move.Col, side)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return Result{}, ctx.Err()
+			}
+			rec.Winner = side.Opponent()
+			result.Forfeit = fmt.Errorf("%v: %w", side, err)
+			return result, nil
+		}
+
+		rec.Moves = append(rec.Moves, move)
+		if board.CheckWin(move.Row, move.Col, side, domain.WinLength) {
+			rec.Winner = side
+			return result, nil
+		}
+
+		side = side.Opponent()
+	}
+
+	return result, nil
+}
+
+// Run plays the match with Workers games at a time and returns the
+// results in game order. done, if set, is called after each game from the
+// worker that played it.
+func Run(ctx context.Context, m Match, a, b Entrant, done func(Result)) ([]Result, error) {
+	if len(m.Openings) == 0 {
+		m.Openings = [][]domain.Point{nil}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	games := make(chan int)
+	results := make([]Result, m.Games)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	for w := 0; w < max(m.Workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			engineA, err := a.New()
+			if err != nil {
+				fail(fmt.Errorf("%s: %w", a.Name, err))
+				return
+			}
+			defer closeEngine(engineA)
+
+			engineB, err := b.New()
+			if err != nil {
+				fail(fmt.Errorf("%s: %w", b.Name, err))
+				return
+			}
+			defer closeEngine(engineB)
+
+			for game := range games {
+				// Each opening is played twice, the first entrant
+				// starts with black
+				opening := game / 2 % len(m.Openings)
+
+				var result Result
+				if game%2 == 0 {
+					result, err = Play(ctx, m.Size, m.Openings[opening], engineA, engineB)
+					result.A = domain.Black
+				} else {
+					result, err = Play(ctx, m.Size, m.Openings[opening], engineB, engineA)
+					result.A = domain.White
+				}
+				if err != nil {
+					fail(err)
+					return
+				}
+
+				result.Record.Black, result.Record.White = a.Name, b.Name
+				if result.A == domain.White {
+					result.Record.Black, result.Record.White = b.Name, a.Name
+				}
+
+				result.Game, result.Opening = game, opening
+				results[game] = result
+				if done != nil {
+					done(result)
+				}
+			}
+		}()
+	}
+
+feed:
+	for game := 0; game < m.Games; game++ {
+		select {
+		case games <- game:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(games)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// closeEngine stops engines running a process.
+func closeEngine(engine ai.Engine) {
+	if c, ok := engine.(io.Closer); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,146 @@
+package selfplay_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/selfplay"
+)
+
+// firstEmpty plays the first empty cell in reading order.
+type firstEmpty struct{}
+
+func (firstEmpty) BestMove(_ context.Context, board *domain.Board, _ domain.Stone) (domain.Point, error) {
+	for row := 0; row < board.Size; row++ {
+		for col := 0; col < board.Size; col++ {
+			if !board.IsOccupied(row, col) {
+				return domain.Point{Row: row, Col: col}, nil
+			}
+		}
+	}
+	return domain.Point{}, errors.New("board is full")
+}
+
+// resigning fails every request.
+type resigning struct{}
+
+func (resigning) BestMove(context.Context, *domain.Board, domain.Stone) (domain.Point, error) {
+	return domain.Point{}, errors.New("resigned")
+}
+
+func entrant(name string, engine ai.Engine) selfplay.Entrant {
+	return selfplay.Entrant{Name: name, New: func() (ai.Engine, error) { return engine, nil }}
+}
+
+func TestReadOpenings(t *testing.T) {
+	openings, err := selfplay.ReadOpenings(strings.NewReader("# balanced\nh8 i9\n\nh8 h9 i8\n"), 15)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]domain.Point{
+		{{Row: 7, Col: 7}, {Row: 6, Col: 8}},
+		{{Row: 7, Col: 7}, {Row: 6, Col: 7}, {Row: 7, Col: 8}},
+	}, openings)
+}
+
+func TestReadOpenings_Invalid(t *testing.T) {
+	for _, src := range []string{"h8 z1", "h8 h8", "a1 b1 a2 b2 a3 b3 a4 b4 a5"} {
+		_, err := selfplay.ReadOpenings(strings.NewReader(src), 15)
+		assert.Error(t, err, src)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	// Filling a 9x9 board in reading order puts black on the anti-diagonal
+	result, err := selfplay.Play(context.Background(), 9, nil, firstEmpty{}, firstEmpty{})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.Black, result.Record.Winner)
+	assert.Len(t, result.Record.Moves, 37)
+	assert.Equal(t, domain.Point{Row: 4, Col: 0}, result.Record.Moves[36])
+	assert.NoError(t, result.Forfeit)
+}
+
+func TestPlay_Opening(t *testing.T) {
+	opening := []domain.Point{{Row: 4, Col: 4}}
+
+	result, err := selfplay.Play(context.Background(), 9, opening, resigning{}, firstEmpty{})
+	require.NoError(t, err)
+
+	// White moves first after an opening of one stone
+	assert.Equal(t, domain.White, result.Record.Winner)
+	assert.Equal(t, []domain.Point{opening[0], {Row: 0, Col: 0}}, result.Record.Moves)
+	assert.Error(t, result.Forfeit)
+}
+
+func TestPlay_Forfeit(t *testing.T) {
+	result, err := selfplay.Play(context.Background(), 9, nil, firstEmpty{}, resigning{})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.Black, result.Record.Winner)
+	assert.ErrorContains(t, result.Forfeit, "resigned")
+}
+
+func TestRun_AlternatesColors(t *testing.T) {
+	match := selfplay.Match{
+		Size:     9,
+		Openings: [][]domain.Point{{{Row: 4, Col: 4}, {Row: 3, Col: 3}}, {{Row: 0, Col: 8}, {Row: 8, Col: 0}}},
+		Games:    6,
+		Workers:  3,
+	}
+
+	var played atomic.Int32
+	results, err := selfplay.Run(context.Background(), match, entrant("a", firstEmpty{}), entrant("b", resigning{}),
+		func(selfplay.Result) { played.Add(1) })
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+	assert.EqualValues(t, 6, played.Load())
+
+	for i, r := range results {
+		assert.Equal(t, i, r.Game)
+		assert.Equal(t, i/2%2, r.Opening)
+		assert.Equal(t, match.Openings[r.Opening], r.Record.Moves[:2])
+
+		if i%2 == 0 {
+			assert.Equal(t, domain.Black, r.A)
+			assert.Equal(t, "a", r.Record.Black)
+		} else {
+			assert.Equal(t, domain.White, r.A)
+			assert.Equal(t, "a", r.Record.White)
+		}
+		assert.Equal(t, 1.0, r.Score())
+	}
+
+	summary := selfplay.Summarize(results)
+	assert.Equal(t, selfplay.Summary{Games: 6, Wins: 6, Forfeits: 6}, summary)
+}
+
+func TestRun_EngineFailsToStart(t *testing.T) {
+	broken := selfplay.Entrant{Name: "broken", New: func() (ai.Engine, error) { return nil, errors.New("no engine") }}
+
+	_, err := selfplay.Run(context.Background(), selfplay.Match{Size: 9, Games: 4, Workers: 2}, entrant("a", firstEmpty{}), broken, nil)
+	assert.ErrorContains(t, err, "no engine")
+}
+
+func TestSummary_Elo(t *testing.T) {
+	diff, margin := selfplay.Summary{Games: 100, Wins: 40, Draws: 20, Losses: 40}.Elo()
+	assert.Equal(t, 0.0, diff)
+	assert.InDelta(t, 62, margin, 1)
+
+	// 75% is 191 Elo
+	diff, margin = selfplay.Summary{Games: 400, Wins: 300, Losses: 100}.Elo()
+	assert.InDelta(t, 190.8, diff, 0.1)
+	assert.InDelta(t, 39.6, margin, 0.5)
+
+	diff, margin = selfplay.Summary{Games: 10, Wins: 10}.Elo()
+	assert.True(t, math.IsInf(diff, 1))
+	assert.True(t, math.IsInf(margin, 1))
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// APIKeyPrefix starts every API key, it tells them apart from the access
+// tokens and makes a leaked key easy to search for.
+const APIKeyPrefix = "gmk_"
+
+// apiKeyTouchInterval bounds how often the last use of a key is written,
+// a bot makes many requests.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyStore finds the keys and their bots, in Postgres.
+type APIKeyStore interface {
+	GetAPIKeyByHash(hash string, ctx context.Context) (*domain.APIKey, *domain.Player, error)
+	TouchAPIKey(id int32, at time.Time, ctx context.Context) error
+}
+
+// APIKeyService issues and checks the API keys of the bots.
+type APIKeyService struct {
+	store APIKeyStore
+	now   func() time.Time
+}
+
+func NewAPIKeyService(store APIKeyStore) *APIKeyService {
+	return &APIKeyService{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// NewAPIKey returns a random API key of the bot and its record to store.
+// The key is shown once, only its hash is kept.
+func (s *APIKeyService) NewAPIKey(bot *domain.Player, name string, scopes []string) (string, *domain.APIKey, error) {
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := APIKeyPrefix + secret
+	record, err := domain.NewAPIKey(bot, name, scopes, key[:len(APIKeyPrefix)+8], HashAPIKey(key), s.now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, record, nil
+}
+
+// Authenticate returns the bot of the key, domain.ErrAPIKeyInvalid unless
+// the key is a valid one of a bot in good standing and domain.ErrAPIKeyScope unless it has the
+// scope.
+func (s *APIKeyService) Authenticate(key, scope string, ctx context.Context) (*domain.APIKey, *domain.Player, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, nil, domain.ErrAPIKeyInvalid
+	}
+
+	record, bot, err := s.store.GetAPIKeyByHash(HashAPIKey(key), ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, nil, domain.ErrAPIKeyInvalid
+		}
+		return nil, nil, err
+	}
+
+	if record.IsRevoked() || !bot.Bot || bot.IsDeleted() || bot.IsBanned() {
+		return nil, nil, domain.ErrAPIKeyInvalid
+	}
+	if !record.HasScope(scope) {
+		return nil, nil, domain.ErrAPIKeyScope
+	}
+
+	now := s.now()
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= apiKeyTouchInterval {
+		// The last use is informative, the request goes on without it
+		if err := s.store.TouchAPIKey(record.ID, now, ctx); err != nil {
+			log.Errorf("Could not record API key use: %s", err)
+		}
+	}
+
+	return record, bot, nil
+}
+
+// HashAPIKey returns the stored form of an API key, random like the
+// refresh tokens.
+func HashAPIKey(key string) string {
+	return HashRefreshToken(key)
+}
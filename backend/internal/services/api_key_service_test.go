@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+type memoryAPIKeyStore struct {
+	keys    map[string]*domain.APIKey
+	players map[int32]*domain.Player
+	touches int
+}
+
+func (s *memoryAPIKeyStore) GetAPIKeyByHash(hash string, ctx context.Context) (*domain.APIKey, *domain.Player, error) {
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, nil, domain.ErrAPIKeyNotFound
+	}
+	return key, s.players[key.PlayerID], nil
+}
+
+func (s *memoryAPIKeyStore) TouchAPIKey(id int32, at time.Time, ctx context.Context) error {
+	s.touches++
+	for _, key := range s.keys {
+		if key.ID == id {
+			key.LastUsedAt = &at
+		}
+	}
+	return nil
+}
+
+func newTestAPIKey(t *testing.T, scopes ...string) (*APIKeyService, *memoryAPIKeyStore, string) {
+	t.Helper()
+
+	bot := &domain.Player{Entity: domain.Entity{ID: 3}, Nickname: "AliceBot", Bot: true, OwnerID: 1}
+	store := &memoryAPIKeyStore{
+		keys:    make(map[string]*domain.APIKey),
+		players: map[int32]*domain.Player{bot.ID: bot},
+	}
+	service := NewAPIKeyService(store)
+
+	key, record, err := service.NewAPIKey(bot, "ci", scopes)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	record.ID = 1
+	store.keys[record.Hash] = record
+
+	return service, store, key
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	service, store, key := newTestAPIKey(t)
+
+	record, bot, err := service.Authenticate(key, domain.ScopePlay, context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if bot.ID != 3 || record.Prefix != key[:len(APIKeyPrefix)+8] {
+		t.Errorf("unexpected key %+v of %+v", record, bot)
+	}
+	if record.Hash == key {
+		t.Error("expected the key to be stored hashed")
+	}
+
+	// The last use is written once per interval
+	if _, _, err := service.Authenticate(key, domain.ScopeRead, context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if store.touches != 1 {
+		t.Errorf("expected 1 touch, got %d", store.touches)
+	}
+
+	for name, wrong := range map[string]string{
+		"unknown":   APIKeyPrefix + "0000",
+		"no prefix": key[len(APIKeyPrefix):],
+		"token":     "eyJhbGciOiJIUzI1NiJ9",
+	} {
+		if _, _, err := service.Authenticate(wrong, domain.ScopeRead, context.Background()); err != domain.ErrAPIKeyInvalid {
+			t.Errorf("%s: expected ErrAPIKeyInvalid, got %v", name, err)
+		}
+	}
+}
+
+func TestAPIKeyService_Authenticate_Scope(t *testing.T) {
+	service, _, key := newTestAPIKey(t, domain.ScopeRead)
+
+	if _, _, err := service.Authenticate(key, domain.ScopeRead, context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if _, _, err := service.Authenticate(key, domain.ScopePlay, context.Background()); err != domain.ErrAPIKeyScope {
+		t.Errorf("expected ErrAPIKeyScope, got %v", err)
+	}
+}
+
+func TestAPIKeyService_Authenticate_Revoked(t *testing.T) {
+	service, store, key := newTestAPIKey(t)
+
+	store.keys[HashAPIKey(key)].Revoke(time.Now())
+	if _, _, err := service.Authenticate(key, domain.ScopeRead, context.Background()); err != domain.ErrAPIKeyInvalid {
+		t.Errorf("expected ErrAPIKeyInvalid, got %v", err)
+	}
+}
+
+func TestAPIKeyService_Authenticate_DeletedBot(t *testing.T) {
+	service, store, key := newTestAPIKey(t)
+
+	store.players[3].Delete(time.Now())
+	if _, _, err := service.Authenticate(key, domain.ScopeRead, context.Background()); err != domain.ErrAPIKeyInvalid {
+		t.Errorf("expected ErrAPIKeyInvalid, got %v", err)
+	}
+}
+
+func TestAPIKeyService_Authenticate_BannedBot(t *testing.T) {
+	service, store, key := newTestAPIKey(t)
+
+	_ = store.players[3].Ban("spam", time.Now())
+	if _, _, err := service.Authenticate(key, domain.ScopeRead, context.Background()); err != domain.ErrAPIKeyInvalid {
+		t.Errorf("expected ErrAPIKeyInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey         = errors.New("unknown signing key")
+	ErrUnsupportedKeyType = errors.New("unsupported key type, expected RSA or Ed25519")
+)
+
+// SigningKey is a key the access tokens are signed or verified with. The
+// asymmetric keys are published as a JWK so that other services verify the
+// tokens without the signing secret.
+type SigningKey struct {
+	// ID is the kid header of the tokens, the JWK thumbprint of the public
+	// key, empty for HMAC
+	ID     string
+	Method jwt.SigningMethod
+
+	// sign is nil for keys kept only to verify the tokens they signed
+	sign   any
+	verify any
+}
+
+// NewHMACKey returns a shared secret key, meant for local development.
+func NewHMACKey(secret string) *SigningKey {
+	return &SigningKey{
+		Method: jwt.SigningMethodHS256,
+		sign:   []byte(secret),
+		verify: []byte(secret),
+	}
+}
+
+// NewSigningKey returns the key of an RSA or Ed25519 private key, signing
+// with RS256 or EdDSA.
+func NewSigningKey(private crypto.Signer) (*SigningKey, error) {
+	key, err := NewVerifyingKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	key.sign = private
+	return key, nil
+}
+
+// NewVerifyingKey returns the key of an RSA or Ed25519 public key. It only
+// verifies tokens, like a retired key after a rotation.
+func NewVerifyingKey(public crypto.PublicKey) (*SigningKey, error) {
+	key := &SigningKey{verify: public}
+
+	switch public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+
+	thumbprint, err := key.thumbprint()
+	if err != nil {
+		return nil, err
+	}
+	key.ID = thumbprint
+
+	return key, nil
+}
+
+// CanSign reports whether the key holds the private part.
+func (k *SigningKey) CanSign() bool {
+	return k.sign != nil
+}
+
+// ParseSigningKeys reads the PEM encoded keys: PKCS #8 or PKCS #1 private
+// keys and PKIX public keys.
+func ParseSigningKeys(data []byte) ([]*SigningKey, error) {
+	var keys []*SigningKey
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		key, err := parsePEMKey(block)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded key found")
+	}
+
+	return keys, nil
+}
+
+// LoadSigningKeys reads the keys of the PEM files in order.
+func LoadSigningKeys(paths []string) ([]*SigningKey, error) {
+	var keys []*SigningKey
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := ParseSigningKeys(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, parsed...)
+	}
+
+	return keys, nil
+}
+
+func parsePEMKey(block *pem.Block) (*SigningKey, error) {
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, ErrUnsupportedKeyType
+		}
+		return NewSigningKey(signer)
+
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewSigningKey(private)
+
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return NewVerifyingKey(public)
+
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+}
+
+// JWK is the public part of a signing key in the JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the document of the /.well-known/jwks.json endpoint.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public key, false for HMAC keys which must stay secret.
+func (k *SigningKey) JWK() (JWK, bool) {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+
+	switch public := k.verify.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// thumbprint returns the JWK thumbprint of the key (RFC 7638): the hash of
+// the required members in lexicographic order, without whitespace.
+func (k *SigningKey) thumbprint() (string, error) {
+	jwk, ok := k.JWK()
+	if !ok {
+		return "", ErrUnsupportedKeyType
+	}
+
+	var members any
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
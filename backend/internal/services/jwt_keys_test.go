@@ -0,0 +1,229 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+func newEd25519Key(t *testing.T) *SigningKey {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	key, err := NewSigningKey(private)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return key
+}
+
+func newKeysService(t *testing.T, keys ...*SigningKey) *JWTService {
+	t.Helper()
+
+	service, err := NewJWTServiceWithKeys(keys, time.Hour, DefaultRefreshTokenTTL)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return service
+}
+
+func TestJWTService_SignsWithKeyID(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rsaKey, err := NewSigningKey(private)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, key := range []*SigningKey{rsaKey, newEd25519Key(t)} {
+		service := newKeysService(t, key)
+
+		tokenString, err := service.Sign(&domain.Player{Entity: domain.Entity{ID: 1}, Nickname: "alice"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		token, err := service.Verify(tokenString)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", key.Method.Alg(), err)
+		}
+		if token.Header["kid"] != key.ID {
+			t.Errorf("expected kid %q, got %v", key.ID, token.Header["kid"])
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			t.Errorf("expected %s, got %s", key.Method.Alg(), token.Method.Alg())
+		}
+	}
+}
+
+func TestJWTService_Rotation(t *testing.T) {
+	old, current := newEd25519Key(t), newEd25519Key(t)
+	player := &domain.Player{Entity: domain.Entity{ID: 1}, Nickname: "alice"}
+
+	before := newKeysService(t, old)
+	after := newKeysService(t, current, old)
+
+	oldToken, err := before.Sign(player)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := after.Verify(oldToken); err != nil {
+		t.Errorf("expected the tokens of the old key to stay valid, got %v", err)
+	}
+
+	newToken, err := after.Sign(player)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	token, err := after.Verify(newToken)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if token.Header["kid"] != current.ID {
+		t.Errorf("expected the new key to sign, got kid %v", token.Header["kid"])
+	}
+
+	if _, err := before.Verify(newToken); err == nil {
+		t.Error("expected an unknown key to fail")
+	}
+}
+
+func TestJWTService_RejectsHMACWithPublicKey(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	key, err := NewSigningKey(private)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	service := newKeysService(t, key)
+
+	// The public key is public, an HS256 signature made with it proves
+	// nothing
+	public, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = key.ID
+	tokenString, err := forged.SignedString(public)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	if _, err := service.Verify(tokenString); err == nil {
+		t.Error("expected the forged token to fail")
+	}
+}
+
+func TestNewJWTServiceWithKeys_RequiresPrivateKey(t *testing.T) {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	key, err := NewVerifyingKey(public)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := NewJWTServiceWithKeys([]*SigningKey{key}, time.Hour, time.Hour); err == nil {
+		t.Error("expected an error without a private key first")
+	}
+	if _, err := NewJWTServiceWithKeys(nil, time.Hour, time.Hour); err == nil {
+		t.Error("expected an error without keys")
+	}
+}
+
+func TestParseSigningKeys(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})...)
+
+	keys, err := ParseSigningKeys(data)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	if !keys[0].CanSign() || keys[1].CanSign() {
+		t.Error("expected a private and a public key")
+	}
+	if keys[0].ID != keys[1].ID {
+		t.Errorf("expected both keys to have the same ID, got %q and %q", keys[0].ID, keys[1].ID)
+	}
+
+	if _, err := ParseSigningKeys([]byte("not a key")); err == nil {
+		t.Error("expected an error without a PEM block")
+	}
+}
+
+func TestSigningKey_Thumbprint(t *testing.T) {
+	// The example of RFC 7638, section 3.1
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	if err != nil {
+		t.Fatalf("failed to decode modulus: %v", err)
+	}
+
+	key, err := NewVerifyingKey(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if key.ID != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("expected the thumbprint of the RFC, got %q", key.ID)
+	}
+}
+
+func TestJWTService_JWKS(t *testing.T) {
+	key := newEd25519Key(t)
+	service := newKeysService(t, key)
+
+	set := service.JWKS()
+	if len(set.Keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(set.Keys))
+	}
+
+	jwk := set.Keys[0]
+	if jwk.Kid != key.ID || jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != "EdDSA" || jwk.X == "" {
+		t.Errorf("unexpected JWK %+v", jwk)
+	}
+
+	hmac := NewJWTService("secret", time.Hour, time.Hour)
+	if keys := hmac.JWKS().Keys; len(keys) != 0 {
+		t.Errorf("expected the HMAC key to stay secret, got %+v", keys)
+	}
+}
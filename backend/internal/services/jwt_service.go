@@ -1,40 +1,247 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
 )
 
 type JWTService struct {
-	secretKey string
+	// keys verify the tokens by their kid, the first one signs. Rotating
+	// puts a new key first and keeps the old one until its tokens expire.
+	keys       []*SigningKey
+	accessTTL  time.Duration
+	refreshTTL time.Duration
 }
 
-func NewJWTService(secretKey string) *JWTService {
+// NewJWTService returns the service signing with a shared HMAC secret, for
+// local development.
+func NewJWTService(secretKey string, accessTTL, refreshTTL time.Duration) *JWTService {
 	if secretKey == "" {
 		panic("JWT secret key must be provided")
 	}
 
 	return &JWTService{
-		secretKey: secretKey,
+		keys:       []*SigningKey{NewHMACKey(secretKey)},
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
 	}
 }
 
-func (s *JWTService) Sign(name string) (string, error) {
+// NewJWTServiceWithKeys returns the service signing with the first key and
+// verifying with all of them.
+func NewJWTServiceWithKeys(keys []*SigningKey, accessTTL, refreshTTL time.Duration) (*JWTService, error) {
+	if len(keys) == 0 || !keys[0].CanSign() {
+		return nil, errors.New("the first JWT key must be a private key")
+	}
+
+	ids := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if ids[key.ID] {
+			return nil, fmt.Errorf("JWT key %q is given twice", key.ID)
+		}
+		ids[key.ID] = true
+	}
+
+	return &JWTService{
+		keys:       keys,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}, nil
+}
+
+// TokenClaims are the claims of an access token the server relies on.
+type TokenClaims struct {
+	// PlayerID is the sub claim, it identifies the player
+	PlayerID int32
+	// Name is the nickname of the player at signing
+	Name string
+	// Guest tells the tokens of the guest players
+	Guest bool
+	// Role of the player at signing, the tokens signed before the roles
+	// have the player role
+	Role domain.Role
+	// ID is the jti claim, a revoked token is denied by it
+	ID string
+	// Generation is the token generation of the player at signing, logging
+	// out of all sessions bumps it
+	Generation int
+	ExpiresAt  time.Time
+}
+
+var ErrInvalidClaims = errors.New("invalid token claims")
+
+// Sign returns an access token of the player with a unique ID and the
+// current token generation of the player.
+func (s *JWTService) Sign(player *domain.Player) (string, error) {
+	jti, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
 	claims := jwt.MapClaims{
-		"name": name,
-		"exp":  time.Now().Add(2 * time.Hour).Unix(),
+		"sub":   strconv.FormatInt(int64(player.ID), 10),
+		"name":  player.Nickname,
+		"jti":   jti,
+		"gen":   player.TokenGeneration,
+		"guest": player.Guest,
+		"role":  string(player.Role),
+		"exp":   time.Now().Add(s.accessTTL).Unix(),
+	}
+	if len(s.keys) == 0 {
+		return "", ErrUnknownKey
+	}
+
+	key := s.keys[0]
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.secretKey))
+	return token.SignedString(key.sign)
 }
 
 func (s *JWTService) Verify(tokenString string) (*jwt.Token, error) {
 	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем, что используется ожидаемый метод подписи
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+
+		key := s.key(kid)
+		if key == nil {
+			return nil, ErrUnknownKey
+		}
+
+		// Проверяем, что используется метод подписи ключа: публичный ключ
+		// не должен проверять HMAC подпись
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, jwt.ErrSignatureInvalid
 		}
-		return []byte(s.secretKey), nil
+		return key.verify, nil
 	})
 }
+
+// JWKS returns the public keys verifying the tokens, HMAC keys are not
+// published.
+func (s *JWTService) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range s.keys {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+func (s *JWTService) key(kid string) *SigningKey {
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// Claims returns the claims of a verified token. Tokens without an ID
+// can't be revoked and are refused, as are the tokens without a subject
+// signed when players were identified by the nickname.
+func (s *JWTService) Claims(token *jwt.Token) (*TokenClaims, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidClaims
+	}
+
+	sub, err := claims.GetSubject()
+	if err != nil {
+		return nil, ErrInvalidClaims
+	}
+	playerID, err := strconv.ParseInt(sub, 10, 32)
+	if err != nil {
+		return nil, ErrInvalidClaims
+	}
+
+	name, ok := claims["name"].(string)
+	if !ok || name == "" {
+		return nil, ErrInvalidClaims
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, ErrInvalidClaims
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, ErrInvalidClaims
+	}
+
+	// Numbers are decoded as float64
+	generation, _ := claims["gen"].(float64)
+	guest, _ := claims["guest"].(bool)
+
+	roleName, _ := claims["role"].(string)
+	role, err := domain.ParseRole(roleName)
+	if err != nil {
+		role = domain.RolePlayer
+	}
+
+	return &TokenClaims{
+		PlayerID:   int32(playerID),
+		Name:       name,
+		Guest:      guest,
+		Role:       role,
+		ID:         jti,
+		Generation: int(generation),
+		ExpiresAt:  exp.Time,
+	}, nil
+}
+
+// NewRefreshToken returns a random refresh token of the player and its
+// record to store. An empty family starts a new one, on login.
+func (s *JWTService) NewRefreshToken(playerID int32, family string) (string, *domain.RefreshToken, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if family == "" {
+		if family, err = randomHex(16); err != nil {
+			return "", nil, err
+		}
+	}
+
+	rt := &domain.RefreshToken{
+		PlayerID:  playerID,
+		Family:    family,
+		Hash:      HashRefreshToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+
+	return token, rt, nil
+}
+
+// HashRefreshToken returns the stored form of a refresh token. The tokens
+// are random, a plain hash is enough to keep a database leak from
+// revealing them.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -6,14 +6,16 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
 )
 
 func TestJWTService_Sign_ReturnsValidToken(t *testing.T) {
 	secret := "testsecret"
-	service := NewJWTService(secret)
+	service := NewJWTService(secret, 2*time.Hour, DefaultRefreshTokenTTL)
 	name := "testuser"
 
-	tokenString, err := service.Sign(name)
+	tokenString, err := service.Sign(&domain.Player{Entity: domain.Entity{ID: 1}, Nickname: name})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -31,10 +33,10 @@ func TestJWTService_Sign_ReturnsValidToken(t *testing.T) {
 
 func TestJWTService_Sign_TokenContainsCorrectClaims(t *testing.T) {
 	secret := "anothersecret"
-	service := NewJWTService(secret)
+	service := NewJWTService(secret, 2*time.Hour, DefaultRefreshTokenTTL)
 	name := "alice"
 
-	tokenString, err := service.Sign(name)
+	tokenString, err := service.Sign(&domain.Player{Entity: domain.Entity{ID: 1}, Nickname: name})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -66,10 +68,10 @@ func TestJWTService_Sign_TokenContainsCorrectClaims(t *testing.T) {
 }
 func TestJWTService_VerifyToken_ValidToken(t *testing.T) {
 	secret := "mysecret"
-	service := NewJWTService(secret)
+	service := NewJWTService(secret, 2*time.Hour, DefaultRefreshTokenTTL)
 	name := "bob"
 
-	tokenString, err := service.Sign(name)
+	tokenString, err := service.Sign(&domain.Player{Entity: domain.Entity{ID: 1}, Nickname: name})
 	if err != nil {
 		t.Fatalf("failed to sign token: %v", err)
 	}
@@ -93,7 +95,7 @@ func TestJWTService_VerifyToken_ValidToken(t *testing.T) {
 
 func TestJWTService_VerifyToken_InvalidSignature(t *testing.T) {
 	secret := "secret1"
-	service := NewJWTService(secret)
+	service := NewJWTService(secret, 2*time.Hour, DefaultRefreshTokenTTL)
 	otherSecret := "secret2"
 	name := "eve"
 
@@ -115,7 +117,7 @@ func TestJWTService_VerifyToken_InvalidSignature(t *testing.T) {
 
 func TestJWTService_VerifyToken_InvalidTokenFormat(t *testing.T) {
 	secret := "secret"
-	service := NewJWTService(secret)
+	service := NewJWTService(secret, 2*time.Hour, DefaultRefreshTokenTTL)
 
 	invalidToken := "not.a.valid.token"
 	_, err := service.Verify(invalidToken)
@@ -123,3 +125,226 @@ func TestJWTService_VerifyToken_InvalidTokenFormat(t *testing.T) {
 		t.Error("expected error for invalid token format, got nil")
 	}
 }
+
+func TestJWTService_Sign_UsesAccessTTL(t *testing.T) {
+	secret := "ttlsecret"
+	service := NewJWTService(secret, 10*time.Minute, DefaultRefreshTokenTTL)
+
+	tokenString, err := service.Sign(&domain.Player{Entity: domain.Entity{ID: 1}, Nickname: "carol"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	token, err := service.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	exp, err := token.Claims.GetExpirationTime()
+	if err != nil {
+		t.Fatalf("expected exp claim, got %v", err)
+	}
+	if exp.After(time.Now().Add(11*time.Minute)) || exp.Before(time.Now().Add(9*time.Minute)) {
+		t.Errorf("expected exp to be about 10 minutes from now, got %v", exp.Time)
+	}
+}
+
+func TestJWTService_NewRefreshToken(t *testing.T) {
+	service := NewJWTService("refreshsecret", time.Minute, time.Hour)
+
+	token, rt, err := service.NewRefreshToken(7, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if rt.PlayerID != 7 {
+		t.Errorf("expected player 7, got %d", rt.PlayerID)
+	}
+	if rt.Family == "" {
+		t.Error("expected a new token family")
+	}
+	if rt.Hash != HashRefreshToken(token) || rt.Hash == token {
+		t.Error("expected the hash of the token to be stored")
+	}
+	if rt.ExpiresAt.Before(time.Now().Add(59*time.Minute)) || rt.ExpiresAt.After(time.Now().Add(time.Hour)) {
+		t.Errorf("expected the token to expire in an hour, got %v", rt.ExpiresAt)
+	}
+
+	next, rotated, err := service.NewRefreshToken(7, rt.Family)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rotated.Family != rt.Family {
+		t.Errorf("expected family %q, got %q", rt.Family, rotated.Family)
+	}
+	if next == token {
+		t.Error("expected a different token")
+	}
+}
+
+func TestJWTService_Claims(t *testing.T) {
+	service := NewJWTService("claimssecret", time.Hour, DefaultRefreshTokenTTL)
+	dave := &domain.Player{Entity: domain.Entity{ID: 42}, Nickname: "dave", TokenGeneration: 3}
+
+	first, err := service.Sign(dave)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, err := service.Sign(dave)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	token, err := service.Verify(first)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	claims, err := service.Claims(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if claims.PlayerID != 42 {
+		t.Errorf("expected player 42, got %d", claims.PlayerID)
+	}
+	if claims.Name != "dave" {
+		t.Errorf("expected name %q, got %q", "dave", claims.Name)
+	}
+	if claims.Guest {
+		t.Error("expected a full account")
+	}
+	if claims.Generation != 3 {
+		t.Errorf("expected generation 3, got %d", claims.Generation)
+	}
+	if claims.ID == "" {
+		t.Error("expected a token ID")
+	}
+	if claims.ExpiresAt.Before(time.Now().Add(59 * time.Minute)) {
+		t.Errorf("expected the token to expire in an hour, got %v", claims.ExpiresAt)
+	}
+
+	token, err = service.Verify(second)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	other, err := service.Claims(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if other.ID == claims.ID {
+		t.Error("expected every token to have its own ID")
+	}
+}
+
+func TestJWTService_Claims_MissingID(t *testing.T) {
+	secret := "claimssecret"
+	service := NewJWTService(secret, time.Hour, DefaultRefreshTokenTTL)
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "42",
+		"name": "dave",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	token, err := service.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := service.Claims(token); err != ErrInvalidClaims {
+		t.Errorf("expected ErrInvalidClaims, got %v", err)
+	}
+}
+
+func TestJWTService_Claims_MissingSubject(t *testing.T) {
+	secret := "claimssecret"
+	service := NewJWTService(secret, time.Hour, DefaultRefreshTokenTTL)
+
+	// Signed when the players were identified by the nickname
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"name": "dave",
+		"jti":  "0123456789abcdef",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	token, err := service.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := service.Claims(token); err != ErrInvalidClaims {
+		t.Errorf("expected ErrInvalidClaims, got %v", err)
+	}
+}
+
+func TestJWTService_Claims_Guest(t *testing.T) {
+	service := NewJWTService("claimssecret", time.Hour, DefaultRefreshTokenTTL)
+
+	guest := domain.NewGuest("1a2b3c4d")
+	guest.ID = 5
+
+	tokenString, err := service.Sign(guest)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	token, err := service.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	claims, err := service.Claims(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !claims.Guest {
+		t.Error("expected a guest token")
+	}
+}
+
+func TestJWTService_Claims_Role(t *testing.T) {
+	secret := "claimssecret"
+	service := NewJWTService(secret, time.Hour, DefaultRefreshTokenTTL)
+
+	admin := &domain.Player{Entity: domain.Entity{ID: 1}, Nickname: "root", Role: domain.RoleAdmin}
+	tokenString, err := service.Sign(admin)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	token, err := service.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	claims, err := service.Claims(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claims.Role != domain.RoleAdmin {
+		t.Errorf("expected the admin role, got %q", claims.Role)
+	}
+
+	// Signed before the roles
+	tokenString, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "42",
+		"name": "dave",
+		"jti":  "0123456789abcdef",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	token, err = service.Verify(tokenString)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	claims, err = service.Claims(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claims.Role != domain.RolePlayer {
+		t.Errorf("expected the player role, got %q", claims.Role)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimitPruneInterval is how often the memory store drops the full
+// buckets and the forgotten failures.
+const rateLimitPruneInterval = time.Minute
+
+// MemoryRateLimitStore keeps the rate limits of a single server instance.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	failures  map[string]*failureRecord
+	nextPrune time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	at     time.Time
+	// full is when the bucket is refilled, it can be dropped after
+	full time.Time
+}
+
+type failureRecord struct {
+	count       int
+	lockedUntil time.Time
+	// expires is when the failures are forgotten and the lock is over
+	expires time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:  make(map[string]*tokenBucket),
+		failures: make(map[string]*failureRecord),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, limit RateLimit, now time.Time, ctx context.Context) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), at: now}
+		s.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.at)
+	bucket.tokens = min(bucket.tokens+float64(elapsed)/float64(limit.Every), float64(limit.Burst))
+	bucket.at = now
+
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) * float64(limit.Every)), nil
+	}
+
+	bucket.tokens--
+	bucket.full = now.Add(time.Duration((float64(limit.Burst) - bucket.tokens) * float64(limit.Every)))
+	return 0, nil
+}
+
+func (s *MemoryRateLimitStore) Fail(key string, window time.Duration, now time.Time, ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(now)
+
+	record, ok := s.failures[key]
+	if !ok || !now.Before(record.expires) {
+		record = &failureRecord{}
+		s.failures[key] = record
+	}
+
+	record.count++
+	record.expires = maxTime(now.Add(window), record.lockedUntil)
+	return record.count, nil
+}
+
+func (s *MemoryRateLimitStore) Lock(key string, until time.Time, ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.failures[key]
+	if !ok {
+		record = &failureRecord{}
+		s.failures[key] = record
+	}
+
+	record.lockedUntil = until
+	record.expires = maxTime(record.expires, until)
+	return nil
+}
+
+func (s *MemoryRateLimitStore) LockedUntil(key string, ctx context.Context) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.failures[key]; ok {
+		return record.lockedUntil, nil
+	}
+	return time.Time{}, nil
+}
+
+func (s *MemoryRateLimitStore) Reset(key string, ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	return nil
+}
+
+// prune drops the entries which no longer limit anything. The caller holds
+// the lock.
+func (s *MemoryRateLimitStore) prune(now time.Time) {
+	if now.Before(s.nextPrune) {
+		return
+	}
+	s.nextPrune = now.Add(rateLimitPruneInterval)
+
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.full) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, record := range s.failures {
+		if !now.Before(record.expires) {
+			delete(s.failures, key)
+		}
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultLockout locks a client out of a login after 5 failures in a row,
+// for a minute doubling with every further failure up to an hour.
+var DefaultLockout = Lockout{
+	Threshold: 5,
+	Base:      time.Minute,
+	Max:       time.Hour,
+	Window:    time.Hour,
+}
+
+// RateLimit is a token bucket: it holds up to Burst requests and gets one
+// back every Every.
+type RateLimit struct {
+	// Name keeps the buckets of the limits apart for the same key
+	Name  string
+	Burst int
+	Every time.Duration
+}
+
+// Lockout is the progressive lockout of the failed logins.
+type Lockout struct {
+	// Threshold is the number of failures the lockout starts with
+	Threshold int
+	// Base is the first lockout, every further failure doubles it up to Max
+	Base time.Duration
+	Max  time.Duration
+	// Window is how long the failures are remembered after the last one
+	Window time.Duration
+}
+
+// RateLimitStore keeps the token buckets and the failures, in memory or
+// in a store shared by the server instances.
+type RateLimitStore interface {
+	// Take takes a request from the bucket of the key. It returns how long
+	// to wait for the next one when the bucket is empty, zero otherwise.
+	Take(key string, limit RateLimit, now time.Time, ctx context.Context) (time.Duration, error)
+	// Fail records a failure of the key and returns the number of failures
+	// since the first one within the window.
+	Fail(key string, window time.Duration, now time.Time, ctx context.Context) (int, error)
+	Lock(key string, until time.Time, ctx context.Context) error
+	LockedUntil(key string, ctx context.Context) (time.Time, error)
+	// Reset forgets the failures and the lock of the key
+	Reset(key string, ctx context.Context) error
+}
+
+// RateLimiter throttles the requests and locks out the clients failing to
+// log in.
+type RateLimiter struct {
+	store   RateLimitStore
+	lockout Lockout
+	now     func() time.Time
+}
+
+func NewRateLimiter(store RateLimitStore, lockout Lockout) *RateLimiter {
+	return &RateLimiter{
+		store:   store,
+		lockout: lockout,
+		now:     time.Now,
+	}
+}
+
+// Allow takes a request of the key from the bucket of the limit. It
+// returns how long to wait before retrying when the bucket is empty, zero
+// when the request is allowed.
+func (l *RateLimiter) Allow(limit RateLimit, key string, ctx context.Context) (time.Duration, error) {
+	return l.store.Take(limit.Name+":"+key, limit, l.now(), ctx)
+}
+
+// Locked returns how long the key stays locked out, zero when it isn't.
+func (l *RateLimiter) Locked(key string, ctx context.Context) (time.Duration, error) {
+	until, err := l.store.LockedUntil("lockout:"+key, ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(until.Sub(l.now()), 0), nil
+}
+
+// Fail records a failed login of the key, locking it out once the failures
+// reach the threshold.
+func (l *RateLimiter) Fail(key string, ctx context.Context) error {
+	now := l.now()
+
+	failures, err := l.store.Fail("lockout:"+key, l.lockout.Window, now, ctx)
+	if err != nil {
+		return err
+	}
+	if failures < l.lockout.Threshold {
+		return nil
+	}
+
+	lockout := l.lockout.Base
+	for range failures - l.lockout.Threshold {
+		if lockout >= l.lockout.Max {
+			break
+		}
+		lockout *= 2
+	}
+
+	return l.store.Lock("lockout:"+key, now.Add(min(lockout, l.lockout.Max)), ctx)
+}
+
+// Succeed forgets the failures of the key after a successful login.
+func (l *RateLimiter) Succeed(key string, ctx context.Context) error {
+	return l.store.Reset("lockout:"+key, ctx)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(now *time.Time) *RateLimiter {
+	limiter := NewRateLimiter(NewMemoryRateLimitStore(), DefaultLockout)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	now := time.Now()
+	limiter := newTestRateLimiter(&now)
+	limit := RateLimit{Name: "login", Burst: 3, Every: 10 * time.Second}
+
+	for i := range 3 {
+		if wait, err := limiter.Allow(limit, "1.2.3.4", context.Background()); err != nil || wait != 0 {
+			t.Fatalf("request %d: expected it to be allowed, got %v, %v", i, wait, err)
+		}
+	}
+
+	wait, err := limiter.Allow(limit, "1.2.3.4", context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if wait != 10*time.Second {
+		t.Errorf("expected to wait 10s, got %v", wait)
+	}
+
+	if wait, _ := limiter.Allow(limit, "5.6.7.8", context.Background()); wait != 0 {
+		t.Errorf("expected another key to have its own bucket, got %v", wait)
+	}
+	if wait, _ := limiter.Allow(RateLimit{Name: "signup", Burst: 1, Every: time.Minute}, "1.2.3.4", context.Background()); wait != 0 {
+		t.Errorf("expected another limit to have its own bucket, got %v", wait)
+	}
+
+	now = now.Add(4 * time.Second)
+	if wait, _ := limiter.Allow(limit, "1.2.3.4", context.Background()); wait != 6*time.Second {
+		t.Errorf("expected to wait 6s, got %v", wait)
+	}
+
+	now = now.Add(6 * time.Second)
+	if wait, _ := limiter.Allow(limit, "1.2.3.4", context.Background()); wait != 0 {
+		t.Errorf("expected the refilled request to be allowed, got %v", wait)
+	}
+}
+
+func TestRateLimiter_Lockout(t *testing.T) {
+	now := time.Now()
+	limiter := newTestRateLimiter(&now)
+
+	for range DefaultLockout.Threshold - 1 {
+		if err := limiter.Fail("alice", context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if locked, _ := limiter.Locked("alice", context.Background()); locked != 0 {
+		t.Fatalf("expected no lockout below the threshold, got %v", locked)
+	}
+
+	// Every failure past the threshold doubles the lockout
+	for _, expected := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		if err := limiter.Fail("alice", context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if locked, _ := limiter.Locked("alice", context.Background()); locked != expected {
+			t.Errorf("expected a lockout of %v, got %v", expected, locked)
+		}
+	}
+
+	now = now.Add(5 * time.Minute)
+	if locked, _ := limiter.Locked("alice", context.Background()); locked != 0 {
+		t.Errorf("expected the lockout to be over, got %v", locked)
+	}
+
+	if err := limiter.Succeed("alice", context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := limiter.Fail("alice", context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if locked, _ := limiter.Locked("alice", context.Background()); locked != 0 {
+		t.Errorf("expected a success to forget the failures, got %v", locked)
+	}
+}
+
+func TestRateLimiter_LockoutMax(t *testing.T) {
+	now := time.Now()
+	limiter := newTestRateLimiter(&now)
+
+	for range DefaultLockout.Threshold + 20 {
+		if err := limiter.Fail("alice", context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if locked, _ := limiter.Locked("alice", context.Background()); locked != DefaultLockout.Max {
+		t.Errorf("expected a lockout of %v, got %v", DefaultLockout.Max, locked)
+	}
+}
+
+func TestRateLimiter_FailuresExpire(t *testing.T) {
+	now := time.Now()
+	limiter := newTestRateLimiter(&now)
+
+	for range DefaultLockout.Threshold - 1 {
+		limiter.Fail("alice", context.Background())
+	}
+
+	now = now.Add(DefaultLockout.Window)
+	limiter.Fail("alice", context.Background())
+	if locked, _ := limiter.Locked("alice", context.Background()); locked != 0 {
+		t.Errorf("expected the old failures to be forgotten, got %v", locked)
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// DefaultRevocationCacheTTL bounds how long a revocation made by another
+// server instance may go unnoticed.
+const DefaultRevocationCacheTTL = 30 * time.Second
+
+var ErrTokenRevoked = errors.New("token was revoked")
+
+// RevocationStore keeps the revoked access tokens and the token generations
+// of the players, in Postgres.
+type RevocationStore interface {
+	IsTokenRevoked(jti string, ctx context.Context) (bool, error)
+	RevokeToken(jti string, expiresAt time.Time, ctx context.Context) error
+	TokenGeneration(playerID int32, ctx context.Context) (int, error)
+}
+
+// RevocationService is the denylist of the access tokens. Every
+// authenticated request checks it, so the answers of the store are cached:
+// a revoked token stays revoked until it expires, the rest is asked again
+// after the TTL.
+type RevocationService struct {
+	store RevocationStore
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu          sync.Mutex
+	tokens      map[string]cachedRevocation
+	generations map[int32]cachedGeneration
+	nextPrune   time.Time
+}
+
+type cachedRevocation struct {
+	revoked bool
+	until   time.Time
+}
+
+type cachedGeneration struct {
+	generation int
+	until      time.Time
+}
+
+func NewRevocationService(store RevocationStore, ttl time.Duration) *RevocationService {
+	return &RevocationService{
+		store:       store,
+		ttl:         ttl,
+		now:         time.Now,
+		tokens:      make(map[string]cachedRevocation),
+		generations: make(map[int32]cachedGeneration),
+	}
+}
+
+// Check returns ErrTokenRevoked if the token was revoked, alone or by a
+// newer token generation of its player.
+func (s *RevocationService) Check(claims *TokenClaims, ctx context.Context) error {
+	revoked, err := s.isRevoked(claims, ctx)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+
+	generation, err := s.generation(claims.PlayerID, ctx)
+	if err != nil {
+		// The player is gone, so are its sessions
+		if errors.Is(err, domain.ErrPlayerNotFound) {
+			return ErrTokenRevoked
+		}
+		return err
+	}
+	if claims.Generation < generation {
+		return ErrTokenRevoked
+	}
+
+	return nil
+}
+
+// Revoke denies the token until it expires.
+func (s *RevocationService) Revoke(claims *TokenClaims, ctx context.Context) error {
+	if err := s.store.RevokeToken(claims.ID, claims.ExpiresAt, ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[claims.ID] = cachedRevocation{revoked: true, until: claims.ExpiresAt}
+	return nil
+}
+
+// SetGeneration caches the token generation of the player after it was
+// bumped, the tokens of the older generations are denied right away.
+func (s *RevocationService) SetGeneration(playerID int32, generation int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generations[playerID] = cachedGeneration{generation: generation, until: s.now().Add(s.ttl)}
+}
+
+func (s *RevocationService) isRevoked(claims *TokenClaims, ctx context.Context) (bool, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	s.prune(now)
+	cached, ok := s.tokens[claims.ID]
+	s.mu.Unlock()
+
+	if ok && now.Before(cached.until) {
+		return cached.revoked, nil
+	}
+
+	revoked, err := s.store.IsTokenRevoked(claims.ID, ctx)
+	if err != nil {
+		return false, err
+	}
+
+	until := now.Add(s.ttl)
+	if revoked {
+		until = claims.ExpiresAt
+	}
+
+	s.mu.Lock()
+	s.tokens[claims.ID] = cachedRevocation{revoked: revoked, until: until}
+	s.mu.Unlock()
+
+	return revoked, nil
+}
+
+func (s *RevocationService) generation(playerID int32, ctx context.Context) (int, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	cached, ok := s.generations[playerID]
+	s.mu.Unlock()
+
+	if ok && now.Before(cached.until) {
+		return cached.generation, nil
+	}
+
+	generation, err := s.store.TokenGeneration(playerID, ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	// A bump cached meanwhile wins over the older answer of the store
+	if current, ok := s.generations[playerID]; !ok || current.generation <= generation {
+		s.generations[playerID] = cachedGeneration{generation: generation, until: now.Add(s.ttl)}
+	} else {
+		generation = current.generation
+	}
+	s.mu.Unlock()
+
+	return generation, nil
+}
+
+// prune drops the stale entries once per TTL so the cache doesn't grow
+// with every token ever seen. The caller holds the lock.
+func (s *RevocationService) prune(now time.Time) {
+	if now.Before(s.nextPrune) {
+		return
+	}
+	s.nextPrune = now.Add(s.ttl)
+
+	for jti, cached := range s.tokens {
+		if !now.Before(cached.until) {
+			delete(s.tokens, jti)
+		}
+	}
+	for playerID, cached := range s.generations {
+		if !now.Before(cached.until) {
+			delete(s.generations, playerID)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+type memoryRevocationStore struct {
+	revoked     map[string]time.Time
+	generations map[int32]int
+	lookups     int
+}
+
+func newMemoryRevocationStore() *memoryRevocationStore {
+	return &memoryRevocationStore{
+		revoked:     make(map[string]time.Time),
+		generations: make(map[int32]int),
+	}
+}
+
+func (s *memoryRevocationStore) IsTokenRevoked(jti string, ctx context.Context) (bool, error) {
+	s.lookups++
+	_, ok := s.revoked[jti]
+	return ok, nil
+}
+
+func (s *memoryRevocationStore) RevokeToken(jti string, expiresAt time.Time, ctx context.Context) error {
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+func (s *memoryRevocationStore) TokenGeneration(playerID int32, ctx context.Context) (int, error) {
+	generation, ok := s.generations[playerID]
+	if !ok {
+		return 0, domain.ErrPlayerNotFound
+	}
+	return generation, nil
+}
+
+func TestRevocationService_Revoke(t *testing.T) {
+	store := newMemoryRevocationStore()
+	store.generations[1] = 0
+	service := NewRevocationService(store, time.Minute)
+
+	claims := &TokenClaims{PlayerID: 1, Name: "alice", ID: "a", ExpiresAt: time.Now().Add(time.Hour)}
+	other := &TokenClaims{PlayerID: 1, Name: "alice", ID: "b", ExpiresAt: time.Now().Add(time.Hour)}
+
+	if err := service.Check(claims, context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := service.Revoke(claims, context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := service.Check(claims, context.Background()); err != ErrTokenRevoked {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+	if err := service.Check(other, context.Background()); err != nil {
+		t.Errorf("expected the other token to stay valid, got %v", err)
+	}
+	if _, ok := store.revoked["a"]; !ok {
+		t.Error("expected the revocation to be stored")
+	}
+}
+
+func TestRevocationService_CachesStore(t *testing.T) {
+	store := newMemoryRevocationStore()
+	store.generations[1] = 0
+	service := NewRevocationService(store, time.Minute)
+
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	claims := &TokenClaims{PlayerID: 1, Name: "alice", ID: "a", ExpiresAt: now.Add(time.Hour)}
+	for range 3 {
+		if err := service.Check(claims, context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+	if store.lookups != 1 {
+		t.Errorf("expected 1 lookup, got %d", store.lookups)
+	}
+
+	// Revoked by another instance, noticed after the TTL
+	store.revoked["a"] = claims.ExpiresAt
+	if err := service.Check(claims, context.Background()); err != nil {
+		t.Errorf("expected the cached answer, got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := service.Check(claims, context.Background()); err != ErrTokenRevoked {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestRevocationService_Generation(t *testing.T) {
+	store := newMemoryRevocationStore()
+	store.generations[1] = 1
+	service := NewRevocationService(store, time.Minute)
+
+	old := &TokenClaims{PlayerID: 1, Name: "alice", ID: "a", Generation: 0, ExpiresAt: time.Now().Add(time.Hour)}
+	current := &TokenClaims{PlayerID: 1, Name: "alice", ID: "b", Generation: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
+	if err := service.Check(old, context.Background()); err != ErrTokenRevoked {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+	if err := service.Check(current, context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	service.SetGeneration(1, 2)
+	if err := service.Check(current, context.Background()); err != ErrTokenRevoked {
+		t.Errorf("expected ErrTokenRevoked after the bump, got %v", err)
+	}
+}
+
+func TestRevocationService_PlayerNotFound(t *testing.T) {
+	service := NewRevocationService(newMemoryRevocationStore(), time.Minute)
+
+	claims := &TokenClaims{PlayerID: 9, Name: "ghost", ID: "a", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := service.Check(claims, context.Background()); err != ErrTokenRevoked {
+		t.Errorf("expected ErrTokenRevoked, got %v", err)
+	}
+}
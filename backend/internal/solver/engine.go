@@ -0,0 +1,56 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/moLIart/gomoku-backend/internal/ai"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+// Engine plays forced wins found by the solver and asks the next engine
+// otherwise, so searches with a horizon don't miss them.
+type Engine struct {
+	next   ai.Engine
+	budget int
+}
+
+// NewEngine returns an engine solving every position within the node
+// budget before the next engine searches it.
+func NewEngine(next ai.Engine, budget int) *Engine {
+	return &Engine{next: next, budget: budget}
+}
+
+type solvedKey struct{}
+
+// solved is a result of the solver already known to the caller.
+type solved struct {
+	hash   uint64
+	side   domain.Stone
+	result Result
+}
+
+// WithResult returns a copy of the context holding the result of solving
+// the board for the side, the Engine uses it instead of solving again.
+func WithResult(ctx context.Context, board *domain.Board, side domain.Stone, result Result) context.Context {
+	return context.WithValue(ctx, solvedKey{}, &solved{hash: board.Hash(), side: side, result: result})
+}
+
+// BestMove returns the first move of a forced win of the side, the move
+// of the next engine when there is none.
+func (e *Engine) BestMove(ctx context.Context, board *domain.Board, side domain.Stone) (domain.Point, error) {
+	var result Result
+	if known, ok := ctx.Value(solvedKey{}).(*solved); ok && known.hash == board.Hash() && known.side == side {
+		result = known.result
+	} else {
+		result = SolveContext(ctx, board, side, e.budget)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return domain.Point{}, err
+	}
+	if result.Outcome == Win {
+		return result.Line[0], nil
+	}
+
+	return e.next.BestMove(ctx, board, side)
+}
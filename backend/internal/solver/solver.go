@@ -0,0 +1,412 @@
+// Package solver searches forced wins: VCF, victory by continuous fours,
+// where every attacking move threatens five, and VCT, victory by
+// continuous threats, where open threes may be played as well.
+//
+// The search is bounded by a node budget and a depth. A proof that no
+// forced win exists only holds within that depth.
+package solver
+
+import (
+	"context"
+	"math/bits"
+
+	"github.com/moLIart/gomoku-backend/internal/analysis"
+	"github.com/moLIart/gomoku-backend/internal/domain"
+)
+
+type Kind string
+
+const (
+	VCF Kind = "vcf"
+	VCT Kind = "vct"
+)
+
+type Outcome string
+
+const (
+	// Win means Line is a forced win
+	Win Outcome = "win"
+	// NoWin means there is no forced win within the depth
+	NoWin Outcome = "no_win"
+	// Unknown means the budget ran out before a proof
+	Unknown Outcome = "unknown"
+)
+
+const (
+	// DefaultBudget is the node budget of Solve for interactive use
+	DefaultBudget = 10000
+
+	// maxFourDepth and maxThreatDepth bound the number of attacking moves
+	// of a VCF and of a VCT
+	maxFourDepth   = 20
+	maxThreatDepth = 6
+
+	// lineRadius is the distance along a line where a stone takes part in
+	// the threats through a cell
+	lineRadius = domain.WinLength - 1
+)
+
+// Result is the answer of the solver. Line alternates moves of the
+// attacker and the defender, starting with the attacker and ending with
+// the move completing five. Against other defenses the attacker wins as
+// well, Line follows the defense delaying five the longest.
+type Result struct {
+	Outcome Outcome
+	Kind    Kind
+	Line    []domain.Point
+	Nodes   int
+}
+
+// Solve looks for a VCF of the side to move, then for a VCT, within the
+// node budget shared by both searches. The board is left unchanged.
+func Solve(board *domain.Board, side domain.Stone, budget int) Result {
+	return SolveContext(context.Background(), board, side, budget)
+}
+
+// SolveContext is Solve stopping when the context is done, the outcome is
+// unknown then.
+func SolveContext(ctx context.Context, board *domain.Board, side domain.Stone, budget int) Result {
+	vcf := newSearch(ctx, board, side, budget, false).run(VCF, maxFourDepth)
+	if vcf.Outcome == Win || vcf.Outcome == Unknown {
+		return vcf
+	}
+
+	vct := newSearch(ctx, board, side, budget-vcf.Nodes, true).run(VCT, maxThreatDepth)
+	vct.Nodes += vcf.Nodes
+	return vct
+}
+
+// SolveVCF looks for a victory by continuous fours of the side to move.
+func SolveVCF(board *domain.Board, side domain.Stone, budget int) Result {
+	return newSearch(context.Background(), board, side, budget, false).run(VCF, maxFourDepth)
+}
+
+// SolveVCT looks for a victory by continuous fours and open threes of the
+// side to move.
+func SolveVCT(board *domain.Board, side domain.Stone, budget int) Result {
+	return newSearch(context.Background(), board, side, budget, true).run(VCT, maxThreatDepth)
+}
+
+// SolveMove looks for a forced win of the side to move starting with the
+// move, a VCF then a VCT, within the node budget shared by both. A move
+// completing five wins right away. The outcome is unknown when the context
+// is done first.
+func SolveMove(ctx context.Context, board *domain.Board, side domain.Stone, move domain.Point, budget int) Result {
+	vcf := newSearch(ctx, board, side, budget, false).runFrom(move, VCF, maxFourDepth)
+	if vcf.Outcome == Win || vcf.Outcome == Unknown {
+		return vcf
+	}
+
+	vct := newSearch(ctx, board, side, budget-vcf.Nodes, true).runFrom(move, VCT, maxThreatDepth)
+	vct.Nodes += vcf.Nodes
+	return vct
+}
+
+// Defenses returns the replies the solver considers to the attacking move
+// of the side, already played on the board: the cells blocking the five it
+// threatens, otherwise the cells stopping its three and the fours of the
+// opponent.
+func Defenses(board *domain.Board, side domain.Stone, move domain.Point) []domain.Point {
+	s := newSearch(context.Background(), board, side, 0, true)
+	if fives := s.fivesThrough(move, side); len(fives) > 0 {
+		return fives
+	}
+	return s.defensesOfThree(move)
+}
+
+// ctxCheckInterval is the number of nodes between the checks of the
+// context, checking every node would slow the search down.
+const ctxCheckInterval = 256
+
+// search is one solver run on a copy of the board.
+type search struct {
+	ctx      context.Context
+	board    *domain.Board
+	attacker domain.Stone
+	defender domain.Stone
+	threes   bool
+	budget   int
+	nodes    int
+	// exhausted is set once the budget is spent, results are unknown then
+	exhausted bool
+	// failed holds the positions without a win, attacker to move, with
+	// the depth they were searched to
+	failed map[uint64]int
+}
+
+func newSearch(ctx context.Context, board *domain.Board, side domain.Stone, budget int, threes bool) *search {
+	return &search{
+		ctx:      ctx,
+		board:    board.Clone(),
+		attacker: side,
+		defender: side.Opponent(),
+		threes:   threes,
+		budget:   budget,
+		failed:   map[uint64]int{},
+	}
+}
+
+// run deepens the search one attacking move at a time, so the shortest
+// win is found first and shallow wins don't wait for deep refutations.
+func (s *search) run(kind Kind, maxDepth int) Result {
+	var line []domain.Point
+	for depth := 1; depth <= maxDepth && line == nil && !s.exhausted; depth++ {
+		line = s.attack(depth)
+	}
+
+	result := Result{Outcome: NoWin, Kind: kind, Nodes: s.nodes}
+	switch {
+	case line != nil:
+		result.Outcome, result.Line = Win, line
+	case s.exhausted:
+		result.Outcome = Unknown
+	}
+	return result
+}
+
+// runFrom deepens the search of a win starting with the move like run.
+func (s *search) runFrom(m domain.Point, kind Kind, maxDepth int) Result {
+	result := Result{Outcome: NoWin, Kind: kind}
+	if s.board.IsOutOfBounds(m.Row, m.Col) || s.board.IsOccupied(m.Row, m.Col) {
+		return result
+	}
+
+	if s.makesFive(m.Row, m.Col, s.attacker) {
+		result.Outcome, result.Line = Win, []domain.Point{m}
+		return result
+	}
+
+	// The attacker has to block a five of the defender and can't block two
+	theirs := s.threats(s.defender, false)
+	if len(theirs.fives) > 1 || len(theirs.fives) == 1 && theirs.fives[0] != m {
+		return result
+	}
+
+	var line []domain.Point
+	for depth := 1; depth <= maxDepth && line == nil && !s.exhausted; depth++ {
+		s.nodes++
+		line = s.tryMove(m, depth)
+	}
+
+	result.Nodes = s.nodes
+	switch {
+	case line != nil:
+		result.Outcome, result.Line = Win, line
+	case s.exhausted:
+		result.Outcome = Unknown
+	}
+	return result
+}
+
+// attack returns a winning line of the attacker to move within depth
+// attacking moves, nil if there is none or the budget is spent.
+func (s *search) attack(depth int) []domain.Point {
+	s.nodes++
+	if s.nodes > s.budget || (s.nodes%ctxCheckInterval == 0 && s.ctx.Err() != nil) {
+		s.exhausted = true
+		return nil
+	}
+
+	own := s.threats(s.attacker, s.threes)
+	if len(own.fives) > 0 {
+		return []domain.Point{own.fives[0]}
+	}
+
+	// The attacker has to block a five of the defender and can't block two
+	theirs := s.threats(s.defender, false)
+	if len(theirs.fives) > 1 || depth == 0 {
+		return nil
+	}
+
+	hash := s.board.Hash()
+	if searched, ok := s.failed[hash]; ok && searched >= depth {
+		return nil
+	}
+
+	moves := own.fours
+	if s.threes {
+		moves = append(moves, own.threes...)
+	}
+	if len(theirs.fives) == 1 {
+		moves = only(moves, theirs.fives[0])
+	}
+
+	for _, m := range moves {
+		if line := s.tryMove(m, depth); line != nil {
+			return line
+		}
+		if s.exhausted {
+			return nil
+		}
+	}
+
+	s.failed[hash] = depth
+	return nil
+}
+
+// tryMove plays the attacking move m and returns the winning line
+// starting with it, nil if some defense holds.
+func (s *search) tryMove(m domain.Point, depth int) []domain.Point {
+	s.board.Put(m.Row, m.Col, s.attacker)
+	defer s.board.Remove(m.Row, m.Col)
+
+	fives := s.fivesThrough(m, s.attacker)
+	if len(fives) >= 2 {
+		// An open four or a double four, one block is not enough
+		return []domain.Point{m, fives[0], fives[1]}
+	}
+
+	var defenses []domain.Point
+	if len(fives) == 1 {
+		defenses = fives
+	} else {
+		defenses = s.defensesOfThree(m)
+	}
+
+	var longest []domain.Point
+	for _, d := range defenses {
+		s.board.Put(d.Row, d.Col, s.defender)
+		line := s.attack(depth - 1)
+		s.board.Remove(d.Row, d.Col)
+
+		if line == nil {
+			return nil
+		}
+		if len(line)+1 > len(longest) {
+			longest = append([]domain.Point{d}, line...)
+		}
+	}
+
+	if longest == nil {
+		return nil
+	}
+	return append([]domain.Point{m}, longest...)
+}
+
+// defensesOfThree returns the replies to the open three made by the
+// stone at m: the cells stopping it and the fours of the defender, which
+// force the attacker to answer.
+func (s *search) defensesOfThree(m domain.Point) []domain.Point {
+	seen := map[domain.Point]bool{}
+	var defenses []domain.Point
+	add := func(p domain.Point) {
+		if !seen[p] {
+			seen[p] = true
+			defenses = append(defenses, p)
+		}
+	}
+
+	for _, p := range analysis.ThreeDefenses(s.board, m.Row, m.Col, s.attacker) {
+		add(p)
+	}
+	for _, p := range s.threats(s.defender, false).fours {
+		add(p)
+	}
+
+	return defenses
+}
+
+// fivesThrough returns the empty cells on the lines through the stone at
+// m which complete five for the side.
+func (s *search) fivesThrough(m domain.Point, stone domain.Stone) []domain.Point {
+	var fives []domain.Point
+	for _, dir := range domain.Directions {
+		for d := -lineRadius; d <= lineRadius; d++ {
+			row, col := m.Row+d*dir.DRow, m.Col+d*dir.DCol
+			if d == 0 || s.board.IsOutOfBounds(row, col) || s.board.IsOccupied(row, col) {
+				continue
+			}
+
+			if s.makesFive(row, col, stone) {
+				fives = append(fives, domain.Point{Row: row, Col: col})
+			}
+		}
+	}
+	return fives
+}
+
+// makesFive reports whether a stone of the side at (row, col) completes
+// five in a row.
+func (s *search) makesFive(row, col int, stone domain.Stone) bool {
+	const window = 1<<domain.WinLength - 1
+
+	for _, dir := range domain.Directions {
+		own, _ := s.board.LinePattern(row, col, dir, stone, lineRadius)
+		own |= 1 << lineRadius
+		for start := 0; start <= lineRadius; start++ {
+			if w := uint32(window) << start; own&w == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// threatCells are the empty cells where a stone of a side completes five,
+// makes a four or makes an open three.
+type threatCells struct {
+	fives  []domain.Point
+	fours  []domain.Point
+	threes []domain.Point
+}
+
+// threats finds the threat cells of the side, the threes only when asked
+// for.
+func (s *search) threats(stone domain.Stone, withThrees bool) threatCells {
+	var cells threatCells
+
+	// A three needs two stones in a window of five with the new one, a four
+	// needs three
+	atLeast := domain.WinLength - 2
+	if withThrees {
+		atLeast = domain.WinLength - 3
+	}
+
+	for row := 0; row < s.board.Size; row++ {
+		for col := 0; col < s.board.Size; col++ {
+			if s.board.IsOccupied(row, col) || !s.hasStonesAround(row, col, stone, atLeast) {
+				continue
+			}
+
+			p := domain.Point{Row: row, Col: col}
+			fours, threes, five := analysis.ThreatsThrough(s.board, row, col, stone)
+			switch {
+			case five:
+				cells.fives = append(cells.fives, p)
+			case fours > 0:
+				cells.fours = append(cells.fours, p)
+			case threes > 0 && withThrees:
+				cells.threes = append(cells.threes, p)
+			}
+		}
+	}
+
+	return cells
+}
+
+// hasStonesAround reports whether a window of five through the cell, free
+// of the opponent, holds at least n stones of the side.
+func (s *search) hasStonesAround(row, col int, stone domain.Stone, n int) bool {
+	const window = 1<<domain.WinLength - 1
+
+	for _, dir := range domain.Directions {
+		own, free := s.board.LinePattern(row, col, dir, stone, lineRadius)
+		open := own | free | 1<<lineRadius
+		for start := 0; start <= lineRadius; start++ {
+			if w := uint32(window) << start; open&w == w && bits.OnesCount32(own&w) >= n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// only keeps the cell if it is one of the moves.
+func only(moves []domain.Point, cell domain.Point) []domain.Point {
+	for _, m := range moves {
+		if m == cell {
+			return []domain.Point{cell}
+		}
+	}
+	return nil
+}
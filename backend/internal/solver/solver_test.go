@@ -0,0 +1,222 @@
+package solver_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moLIart/gomoku-backend/internal/domain"
+	"github.com/moLIart/gomoku-backend/internal/domain/domaintest"
+	"github.com/moLIart/gomoku-backend/internal/solver"
+)
+
+func points(coords ...int) []domain.Point {
+	var ps []domain.Point
+	for i := 0; i+1 < len(coords); i += 2 {
+		ps = append(ps, domain.Point{Row: coords[i], Col: coords[i+1]})
+	}
+	return ps
+}
+
+// requireWinningLine replays the line and checks that the attacker
+// completes five with its last move.
+func requireWinningLine(t *testing.T, board *domain.Board, side domain.Stone, line []domain.Point) {
+	t.Helper()
+	require.NotEmpty(t, line)
+	require.Equal(t, 1, len(line)%2, "the attacker plays the first and the last move")
+
+	board = board.Clone()
+	stone := side
+	for _, p := range line {
+		require.NoError(t, board.Put(p.Row, p.Col, stone))
+		stone = stone.Opponent()
+	}
+
+	last := line[len(line)-1]
+	assert.True(t, board.CheckWin(last.Row, last.Col, side, domain.WinLength))
+}
+
+func TestSolve_Five(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 3, 7, 4, 7, 5, 7, 6), points(8, 3, 8, 4, 8, 5, 0, 0))
+
+	result := solver.Solve(board, domain.Black, solver.DefaultBudget)
+	require.Equal(t, solver.Win, result.Outcome)
+	assert.Equal(t, solver.VCF, result.Kind)
+	assert.Len(t, result.Line, 1)
+	requireWinningLine(t, board, domain.Black, result.Line)
+}
+
+func TestSolve_OpenFour(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 7, 7), points(0, 0, 0, 14))
+
+	result := solver.Solve(board, domain.Black, solver.DefaultBudget)
+	require.Equal(t, solver.Win, result.Outcome)
+	assert.Equal(t, solver.VCF, result.Kind)
+	assert.Len(t, result.Line, 3)
+	requireWinningLine(t, board, domain.Black, result.Line)
+}
+
+func TestSolveVCF_FourFour(t *testing.T) {
+	// Two closed threes crossing at (7,7)
+	board := domaintest.NewBoard(t, 15, points(7, 4, 7, 5, 7, 6, 4, 7, 5, 7, 6, 7), points(7, 3, 3, 7))
+
+	result := solver.SolveVCF(board, domain.Black, solver.DefaultBudget)
+	require.Equal(t, solver.Win, result.Outcome)
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, result.Line[0])
+	requireWinningLine(t, board, domain.Black, result.Line)
+}
+
+func TestSolveVCF_Sequence(t *testing.T) {
+	// (7,7) makes a four on row 7 and a three on column 7, after the block
+	// at (7,8) the column becomes an open four with (8,7)
+	board := domaintest.NewBoard(t, 15,
+		points(7, 4, 7, 5, 7, 6, 5, 7, 6, 7),
+		points(7, 3, 2, 7, 12, 12))
+
+	result := solver.SolveVCF(board, domain.Black, solver.DefaultBudget)
+	require.Equal(t, solver.Win, result.Outcome)
+	assert.Greater(t, len(result.Line), 3)
+	requireWinningLine(t, board, domain.Black, result.Line)
+}
+
+func TestSolve_ThreeThree(t *testing.T) {
+	// (7,7) makes two open threes, there is no four to play
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 5, 7, 6, 7), points(0, 0, 0, 14, 14, 0, 14, 14))
+
+	vcf := solver.SolveVCF(board, domain.Black, solver.DefaultBudget)
+	assert.Equal(t, solver.NoWin, vcf.Outcome)
+
+	result := solver.Solve(board, domain.Black, solver.DefaultBudget)
+	require.Equal(t, solver.Win, result.Outcome)
+	assert.Equal(t, solver.VCT, result.Kind)
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, result.Line[0])
+	requireWinningLine(t, board, domain.Black, result.Line)
+	assert.Greater(t, result.Nodes, vcf.Nodes)
+}
+
+func TestSolve_MustBlockFour(t *testing.T) {
+	// Black would have a three-three but has to block the white four
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 5, 7, 6, 7), points(12, 2, 12, 3, 12, 4, 12, 5, 0, 0))
+
+	result := solver.Solve(board, domain.Black, solver.DefaultBudget)
+	assert.Equal(t, solver.NoWin, result.Outcome)
+}
+
+func TestSolve_NoWin(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 7, 8, 8), points(7, 8, 6, 6))
+
+	result := solver.Solve(board, domain.Black, solver.DefaultBudget)
+	assert.Equal(t, solver.NoWin, result.Outcome)
+	assert.Empty(t, result.Line)
+}
+
+func TestSolveMove(t *testing.T) {
+	// (7,7) makes two open threes, a quiet move or an occupied cell loses the
+	// initiative
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 5, 7, 6, 7), points(0, 0, 0, 14, 14, 0, 14, 14))
+
+	result := solver.SolveMove(context.Background(), board, domain.Black, domain.Point{Row: 7, Col: 7}, solver.DefaultBudget)
+	require.Equal(t, solver.Win, result.Outcome)
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, result.Line[0])
+	requireWinningLine(t, board, domain.Black, result.Line)
+
+	result = solver.SolveMove(context.Background(), board, domain.Black, domain.Point{Row: 11, Col: 11}, solver.DefaultBudget)
+	assert.Equal(t, solver.NoWin, result.Outcome)
+
+	result = solver.SolveMove(context.Background(), board, domain.Black, domain.Point{Row: 7, Col: 5}, solver.DefaultBudget)
+	assert.Equal(t, solver.NoWin, result.Outcome)
+}
+
+func TestSolveMove_MustBlockFour(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 7, 7), points(12, 2, 12, 3, 12, 4, 12, 5, 0, 0))
+
+	result := solver.SolveMove(context.Background(), board, domain.Black, domain.Point{Row: 7, Col: 8}, solver.DefaultBudget)
+	assert.Equal(t, solver.NoWin, result.Outcome)
+}
+
+func TestDefenses(t *testing.T) {
+	// The four of (7,7) has a single block
+	board := domaintest.NewBoard(t, 15, points(7, 4, 7, 5, 7, 6, 7, 7), points(7, 3, 0, 0, 0, 14))
+	assert.Equal(t, points(7, 8), solver.Defenses(board, domain.Black, domain.Point{Row: 7, Col: 7}))
+
+	// The open three of (7,7) is stopped at either end
+	board = domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 7, 7), points(0, 0, 0, 14))
+	defenses := solver.Defenses(board, domain.Black, domain.Point{Row: 7, Col: 7})
+	assert.Contains(t, defenses, domain.Point{Row: 7, Col: 4})
+	assert.Contains(t, defenses, domain.Point{Row: 7, Col: 8})
+	assert.NotContains(t, defenses, domain.Point{Row: 1, Col: 1})
+}
+
+func TestSolve_Budget(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 5, 7, 6, 7), points(0, 0, 0, 14, 14, 0, 14, 14))
+
+	result := solver.Solve(board, domain.Black, 3)
+	assert.Equal(t, solver.Unknown, result.Outcome)
+	assert.LessOrEqual(t, result.Nodes, 4)
+}
+
+func TestSolve_LeavesBoardUnchanged(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 5, 7, 6, 7), points(0, 0, 0, 14, 14, 0, 14, 14))
+	hash := board.Hash()
+
+	solver.Solve(board, domain.Black, solver.DefaultBudget)
+	assert.Equal(t, hash, board.Hash())
+}
+
+// fixedEngine always plays the same move.
+type fixedEngine struct {
+	move domain.Point
+}
+
+func (e fixedEngine) BestMove(context.Context, *domain.Board, domain.Stone) (domain.Point, error) {
+	return e.move, nil
+}
+
+func TestEngine_PlaysForcedWin(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 5, 7, 6, 7), points(0, 0, 0, 14, 14, 0, 14, 14))
+	engine := solver.NewEngine(fixedEngine{move: domain.Point{Row: 1, Col: 1}}, solver.DefaultBudget)
+
+	move, err := engine.BestMove(context.Background(), board, domain.Black)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 7, Col: 7}, move)
+}
+
+func TestEngine_AsksNextEngine(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 7, 8, 8), points(7, 8, 6, 6))
+	engine := solver.NewEngine(fixedEngine{move: domain.Point{Row: 1, Col: 1}}, solver.DefaultBudget)
+
+	move, err := engine.BestMove(context.Background(), board, domain.Black)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 1, Col: 1}, move)
+}
+
+func TestEngine_UsesKnownResult(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 7, 8, 8), points(7, 8, 6, 6))
+	engine := solver.NewEngine(fixedEngine{move: domain.Point{Row: 1, Col: 1}}, solver.DefaultBudget)
+
+	// Not a real win, the engine trusts the result of the caller
+	known := solver.Result{Outcome: solver.Win, Kind: solver.VCF, Line: points(2, 2)}
+	ctx := solver.WithResult(context.Background(), board, domain.Black, known)
+
+	move, err := engine.BestMove(ctx, board, domain.Black)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 2, Col: 2}, move)
+
+	// The result of another side is ignored
+	move, err = engine.BestMove(ctx, board, domain.White)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Point{Row: 1, Col: 1}, move)
+}
+
+func TestEngine_Cancelled(t *testing.T) {
+	board := domaintest.NewBoard(t, 15, points(7, 5, 7, 6, 5, 7, 6, 7), points(0, 0, 0, 14, 14, 0, 14, 14))
+	engine := solver.NewEngine(fixedEngine{move: domain.Point{Row: 1, Col: 1}}, solver.DefaultBudget)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := engine.BestMove(ctx, board, domain.Black)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,27 @@
+ALTER TABLE "games" ADD COLUMN "board_json" JSONB NULL;
+
+UPDATE "games" SET "board_json" = (
+  WITH "expanded" AS (
+    SELECT
+      split_part("games"."board", ':', 1)::int AS "size",
+      string_agg(repeat("runs"."m"[2], COALESCE(NULLIF("runs"."m"[1], ''), '1')::int), '' ORDER BY "runs"."ord") AS "cells"
+    FROM regexp_matches(split_part("games"."board", ':', 2), '(\d*)([.xo])', 'g') WITH ORDINALITY AS "runs"("m", "ord")
+  )
+  SELECT jsonb_build_object('size', "size", 'data', (
+    SELECT jsonb_agg((
+      SELECT jsonb_agg(
+        CASE substr("cells", "r" * "size" + "c" + 1, 1)
+          WHEN 'x' THEN "games"."first_player_id"
+          WHEN 'o' THEN "games"."second_player_id"
+          ELSE 0
+        END ORDER BY "c")
+      FROM generate_series(0, "size" - 1) AS "c"
+    ) ORDER BY "r")
+    FROM generate_series(0, "size" - 1) AS "r"
+  ))
+  FROM "expanded"
+);
+
+ALTER TABLE "games" DROP COLUMN "board";
+ALTER TABLE "games" RENAME COLUMN "board_json" TO "board";
+ALTER TABLE "games" ALTER COLUMN "board" SET NOT NULL;
@@ -0,0 +1,18 @@
+-- Boards are stored as "<size>:<cells>" (see domain.Board.MarshalText),
+-- an unencoded cell string is a valid run-length encoding as well.
+ALTER TABLE "games" ADD COLUMN "board_text" TEXT NULL;
+
+UPDATE "games" SET "board_text" = ("board"->>'size') || ':' || (
+  SELECT string_agg(
+    CASE "cells"."cell"::int
+      WHEN 0 THEN '.'
+      WHEN "games"."first_player_id" THEN 'x'
+      ELSE 'o'
+    END, '' ORDER BY "rows"."ord", "cells"."ord")
+  FROM jsonb_array_elements("board"->'data') WITH ORDINALITY AS "rows"("data", "ord"),
+    jsonb_array_elements_text("rows"."data") WITH ORDINALITY AS "cells"("cell", "ord")
+);
+
+ALTER TABLE "games" DROP COLUMN "board";
+ALTER TABLE "games" RENAME COLUMN "board_text" TO "board";
+ALTER TABLE "games" ALTER COLUMN "board" SET NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE "games" DROP COLUMN "undo_requested_by";
+ALTER TABLE "games" DROP COLUMN "moves";
+ALTER TABLE "games" DROP COLUMN "rated";
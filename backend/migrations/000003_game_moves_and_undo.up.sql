@@ -0,0 +1,3 @@
+ALTER TABLE "games" ADD COLUMN "rated" BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE "games" ADD COLUMN "moves" JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE "games" ADD COLUMN "undo_requested_by" INT NULL REFERENCES "players" ("player_id");
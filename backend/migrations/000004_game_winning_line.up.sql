@@ -0,0 +1 @@
+ALTER TABLE "games" ADD COLUMN "winning_line" JSONB NULL;
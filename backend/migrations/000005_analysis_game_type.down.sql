@@ -0,0 +1,6 @@
+DELETE FROM "games" WHERE "type" = 'analysis';
+
+ALTER TYPE "game_type" RENAME TO "game_type_old";
+CREATE TYPE "game_type" AS ENUM ('pvp', 'pva');
+ALTER TABLE "games" ALTER COLUMN "type" TYPE "game_type" USING "type"::text::"game_type";
+DROP TYPE "game_type_old";
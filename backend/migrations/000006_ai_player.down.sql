@@ -0,0 +1,5 @@
+DELETE FROM "games"
+WHERE "first_player_id" = (SELECT "player_id" FROM "players" WHERE "nickname" = 'gomoku-ai')
+   OR "second_player_id" = (SELECT "player_id" FROM "players" WHERE "nickname" = 'gomoku-ai');
+
+DELETE FROM "players" WHERE "nickname" = 'gomoku-ai';
@@ -0,0 +1,3 @@
+-- The password is random so nobody can log in as the AI
+INSERT INTO "players" ("nickname", "password", "score")
+VALUES ('gomoku-ai', substr(md5(random()::text), 1, 20), 0);
@@ -0,0 +1,4 @@
+DROP TABLE "puzzle_attempts";
+DROP TABLE "puzzle_ratings";
+DROP TABLE "daily_puzzles";
+DROP TABLE "puzzles";
@@ -0,0 +1,36 @@
+CREATE TABLE "puzzles" (
+  "puzzle_id" SERIAL PRIMARY KEY,
+  -- Same encoding as "games"."board" (see domain.Board.MarshalText)
+  "board" TEXT NOT NULL,
+  -- Stone to move, 1 for black and 2 for white
+  "side" SMALLINT NOT NULL,
+  -- Array of winning lines, each an array of [row, col] pairs
+  "solutions" JSONB NOT NULL,
+  "difficulty" INT NOT NULL,
+  "rating" INT NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT now()
+);
+
+-- The puzzle of every day is picked on its first request
+CREATE TABLE "daily_puzzles" (
+  "day" DATE PRIMARY KEY,
+  "puzzle_id" INT NOT NULL REFERENCES "puzzles" ("puzzle_id") ON DELETE CASCADE
+);
+
+CREATE TABLE "puzzle_ratings" (
+  "player_id" INT PRIMARY KEY REFERENCES "players" ("player_id") ON DELETE CASCADE,
+  "rating" INT NOT NULL,
+  "attempts" INT NOT NULL DEFAULT 0,
+  "solved" INT NOT NULL DEFAULT 0
+);
+
+-- Only the first attempt of a player at a puzzle is rated
+CREATE TABLE "puzzle_attempts" (
+  "player_id" INT NOT NULL REFERENCES "players" ("player_id") ON DELETE CASCADE,
+  "puzzle_id" INT NOT NULL REFERENCES "puzzles" ("puzzle_id") ON DELETE CASCADE,
+  "solved" BOOLEAN NOT NULL,
+  "attempted_at" timestamp NOT NULL DEFAULT now(),
+  PRIMARY KEY ("player_id", "puzzle_id")
+);
+
+CREATE INDEX "IDX_daily_puzzles_puzzle_id" ON "daily_puzzles" USING BTREE ("puzzle_id");
@@ -0,0 +1,15 @@
+CREATE TABLE "refresh_tokens" (
+  "token_id" SERIAL PRIMARY KEY,
+  "player_id" INT NOT NULL REFERENCES "players" ("player_id") ON DELETE CASCADE,
+  -- Tokens rotated from the same login, revoked together on reuse
+  "family" VARCHAR(32) NOT NULL,
+  -- SHA-256 of the token, the token itself is never stored
+  "token_hash" CHAR(64) NOT NULL UNIQUE,
+  "created_at" timestamp NOT NULL DEFAULT now(),
+  "expires_at" timestamp NOT NULL,
+  "used_at" timestamp NULL,
+  "revoked_at" timestamp NULL
+);
+
+CREATE INDEX "IDX_refresh_tokens_family" ON "refresh_tokens" USING BTREE ("family");
+CREATE INDEX "IDX_refresh_tokens_player_id" ON "refresh_tokens" USING BTREE ("player_id");
@@ -0,0 +1,2 @@
+DROP TABLE "revoked_tokens";
+ALTER TABLE "players" DROP COLUMN "token_generation";
@@ -0,0 +1,10 @@
+-- Bumped by logging out of all sessions, older access tokens are denied
+ALTER TABLE "players" ADD COLUMN "token_generation" INT NOT NULL DEFAULT 0;
+
+-- Denylist of the access tokens logged out before they expire
+CREATE TABLE "revoked_tokens" (
+  "jti" VARCHAR(32) PRIMARY KEY,
+  "expires_at" timestamp NOT NULL
+);
+
+CREATE INDEX "IDX_revoked_tokens_expires_at" ON "revoked_tokens" USING BTREE ("expires_at");
@@ -0,0 +1,2 @@
+-- Deleted players are anonymized, the games keep referring to them
+ALTER TABLE "players" ADD COLUMN "deleted_at" timestamp NULL;
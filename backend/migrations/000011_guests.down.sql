@@ -0,0 +1,3 @@
+DROP INDEX "IDX_players_guest_last_seen_at";
+ALTER TABLE "players" DROP COLUMN "last_seen_at";
+ALTER TABLE "players" DROP COLUMN "guest";
@@ -0,0 +1,5 @@
+ALTER TABLE "players" ADD COLUMN "guest" BOOLEAN NOT NULL DEFAULT false;
+-- Updated on login and token refresh, inactive guests are removed
+ALTER TABLE "players" ADD COLUMN "last_seen_at" timestamp NOT NULL DEFAULT now();
+
+CREATE INDEX "IDX_players_guest_last_seen_at" ON "players" USING BTREE ("last_seen_at") WHERE "guest";
@@ -0,0 +1,4 @@
+DROP TABLE "api_keys";
+DROP INDEX "IDX_players_owner_id";
+ALTER TABLE "players" DROP COLUMN "owner_id";
+ALTER TABLE "players" DROP COLUMN "bot";
@@ -0,0 +1,21 @@
+ALTER TABLE "players" ADD COLUMN "bot" BOOLEAN NOT NULL DEFAULT false;
+-- The player running the bot
+ALTER TABLE "players" ADD COLUMN "owner_id" INT NULL REFERENCES "players" ("player_id");
+
+CREATE INDEX "IDX_players_owner_id" ON "players" USING BTREE ("owner_id") WHERE "owner_id" IS NOT NULL;
+
+CREATE TABLE "api_keys" (
+  "api_key_id" SERIAL PRIMARY KEY,
+  "player_id" INT NOT NULL REFERENCES "players" ("player_id") ON DELETE CASCADE,
+  "name" VARCHAR(50) NOT NULL,
+  -- The start of the key, shown to tell the keys apart
+  "prefix" VARCHAR(16) NOT NULL,
+  -- SHA-256 of the key, the key itself is never stored
+  "key_hash" CHAR(64) NOT NULL UNIQUE,
+  "scopes" TEXT[] NOT NULL,
+  "created_at" timestamp NOT NULL DEFAULT now(),
+  "last_used_at" timestamp NULL,
+  "revoked_at" timestamp NULL
+);
+
+CREATE INDEX "IDX_api_keys_player_id" ON "api_keys" USING BTREE ("player_id");
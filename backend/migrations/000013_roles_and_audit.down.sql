@@ -0,0 +1,5 @@
+DROP TABLE "audit_log";
+ALTER TABLE "games" DROP COLUMN "annulled_at";
+ALTER TABLE "players" DROP COLUMN "ban_reason";
+ALTER TABLE "players" DROP COLUMN "banned_at";
+ALTER TABLE "players" DROP COLUMN "role";
@@ -0,0 +1,21 @@
+-- player, moderator or admin
+ALTER TABLE "players" ADD COLUMN "role" VARCHAR(16) NOT NULL DEFAULT 'player';
+ALTER TABLE "players" ADD COLUMN "banned_at" timestamp NULL;
+ALTER TABLE "players" ADD COLUMN "ban_reason" VARCHAR(200) NOT NULL DEFAULT '';
+
+-- An annulled game is over and its result doesn't count
+ALTER TABLE "games" ADD COLUMN "annulled_at" timestamp NULL;
+
+CREATE TABLE "audit_log" (
+  "audit_id" SERIAL PRIMARY KEY,
+  "actor_id" INT NOT NULL REFERENCES "players" ("player_id"),
+  "action" VARCHAR(32) NOT NULL,
+  -- player, game, book or puzzle
+  "target_type" VARCHAR(16) NOT NULL,
+  "target_id" INT NULL,
+  "details" JSONB NOT NULL DEFAULT '{}',
+  "created_at" timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX "IDX_audit_log_target" ON "audit_log" USING BTREE ("target_type", "target_id");
+CREATE INDEX "IDX_audit_log_actor_id" ON "audit_log" USING BTREE ("actor_id");
@@ -0,0 +1 @@
+-- The rated flags and the score of the AI are not restored
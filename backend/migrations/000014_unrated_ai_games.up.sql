@@ -0,0 +1,3 @@
+-- Games against the AI are never rated, the AI doesn't collect score
+UPDATE "games" SET "rated" = FALSE WHERE "type" = 'pva' AND "winner_player_id" IS NULL AND "annulled_at" IS NULL;
+UPDATE "players" SET "score" = 0 WHERE "nickname" = 'gomoku-ai';